@@ -0,0 +1,50 @@
+package app
+
+import (
+	"path/filepath"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+
+	"github.com/shalshcode08/Term-Note/internal/config"
+	"github.com/shalshcode08/Term-Note/internal/notes"
+)
+
+// newTestModel creates a model whose notes live in memory. Settings such
+// as history and locks go to a temp directory.
+func newTestModel(t *testing.T, files map[string]string) (Model, *notes.MemVault) {
+	t.Helper()
+	dir := t.TempDir()
+	t.Setenv("HOME", dir)
+
+	cfg, err := config.Default()
+	if err != nil {
+		t.Fatal(err)
+	}
+	cfg.Path = filepath.Join(dir, "config.toml")
+	cfg.VaultDir = filepath.Join(dir, "vault")
+	cfg.Git.AutoCommit = false
+
+	vault := notes.NewMemVault(files)
+	m, err := New(cfg, vault)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { m.watcher.Close() })
+	return m, vault
+}
+
+// press sends keys to the model one at a time
+func press(t *testing.T, m Model, keys ...tea.KeyMsg) Model {
+	t.Helper()
+	for _, k := range keys {
+		next, _ := m.Update(k)
+		m = next.(Model)
+	}
+	return m
+}
+
+// runes returns the key press for typing text
+func runes(text string) tea.KeyMsg {
+	return tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune(text)}
+}
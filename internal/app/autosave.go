@@ -0,0 +1,94 @@
+package app
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+
+	"github.com/shalshcode08/Term-Note/internal/notes"
+)
+
+// editorTickMsg drives autosave and keeps the "saved ago" indicator current
+type editorTickMsg time.Time
+
+// editorTick schedules the next editor tick
+func editorTick() tea.Cmd {
+	return tea.Tick(time.Second, func(t time.Time) tea.Msg {
+		return editorTickMsg(t)
+	})
+}
+
+// dirty reports whether the editor holds changes that are not on disk
+func (m Model) dirty() bool {
+	return m.currentFile != "" && m.textArea.Value() != m.savedContent
+}
+
+// autosaveEnabled reports whether the open note may be saved without asking.
+// Encrypted notes are only saved explicitly, so idle periods do not write
+// and commit a new encrypted file each time.
+func (m Model) autosaveEnabled() bool {
+	return m.cfg.Editor.Autosave > 0 && !m.readOnly && !notes.HasConflictMarkers(m.textArea.Value()) &&
+		m.noteKey == nil && !notes.IsEncrypted(m.currentFile)
+}
+
+// handleEditorTick notes when the text last changed and saves once it has
+// been idle for the configured autosave delay
+func (m Model) handleEditorTick(now time.Time) (tea.Model, tea.Cmd) {
+	m.expireUndo(now)
+	m.passphrases.expire(now)
+	if m.currentFile == "" {
+		return m, editorTick()
+	}
+
+	if value := m.textArea.Value(); value != m.lastSeen {
+		m.lastSeen = value
+		m.lastChange = now
+	}
+
+	m.checkLock()
+	m.watchDisk()
+
+	if m.dirty() && now.Sub(m.lastChange) >= m.cfg.Editor.Autosave {
+		m.autosave()
+	}
+	if !m.showRecover {
+		m.updateSwap()
+	}
+
+	return m, editorTick()
+}
+
+// autosave saves the open note if it has changes and autosave is allowed.
+// Failures are reported in the status bar like manual saves.
+func (m *Model) autosave() {
+	if !m.dirty() || !m.autosaveEnabled() {
+		return
+	}
+	err := m.writeNote(false)
+	if errors.Is(err, errChangedOnDisk) {
+		m.promptConflict(m.diskContent, false)
+		return
+	}
+	if errors.Is(err, errNoSnapshot) {
+		m.statusMessage = fmt.Sprintf("Autosaved %s, %v", m.currentFile, err)
+		m.statusType = "warning"
+		return
+	}
+	if err != nil {
+		m.statusMessage = fmt.Sprintf("Autosave failed: %v", err)
+		m.statusType = "error"
+	}
+}
+
+// savedAgo describes when the note was last saved, for the editor status bar
+func savedAgo(t time.Time) string {
+	if t.IsZero() {
+		return ""
+	}
+	if diff := time.Since(t); diff < time.Minute {
+		return fmt.Sprintf("saved %ds ago", int(diff.Seconds()))
+	}
+	return "saved " + notes.FormatRelativeTime(t)
+}
@@ -0,0 +1,132 @@
+package app
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/charmbracelet/bubbles/textarea"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/muesli/termenv"
+
+	"github.com/shalshcode08/Term-Note/internal/config"
+	"github.com/shalshcode08/Term-Note/internal/notes"
+	"github.com/shalshcode08/Term-Note/internal/ui/styles"
+)
+
+// CaptureModel is a one-screen model for jotting down a quick entry.
+// On submit the entry is appended with a timestamp to the inbox note.
+type CaptureModel struct {
+	cfg           *config.Config
+	vault         notes.Vault
+	inbox         string // Note name entries are appended to
+	keys          keyMap
+	textArea      textarea.Model
+	statusMessage string
+	savedTo       string // Filename the entry was saved to, empty if cancelled
+}
+
+// NewCapture creates a capture model that appends to the given inbox note
+// of the vault. It fails if the configured key bindings are invalid or the
+// theme cannot be loaded.
+func NewCapture(cfg *config.Config, vault notes.Vault, inbox string) (CaptureModel, error) {
+	keys, err := newKeyMap(cfg.Keys, cfg.Leader)
+	if err != nil {
+		return CaptureModel{}, fmt.Errorf("invalid key bindings: %w", err)
+	}
+
+	if err := LoadThemes(cfg); err != nil {
+		return CaptureModel{}, err
+	}
+
+	ta := newTextArea(cfg)
+	ta.Placeholder = "Capture a thought..."
+	ta.SetHeight(8)
+	if cfg.Plain {
+		lipgloss.SetColorProfile(termenv.Ascii)
+	}
+
+	return CaptureModel{
+		cfg:      cfg,
+		vault:    vault,
+		inbox:    inbox,
+		keys:     keys,
+		textArea: ta,
+	}, nil
+}
+
+// SavedTo returns the filename the entry was appended to, or "" if nothing was saved
+func (m CaptureModel) SavedTo() string {
+	return m.savedTo
+}
+
+// Init initializes the model (Bubble Tea interface)
+func (m CaptureModel) Init() tea.Cmd {
+	return textarea.Blink
+}
+
+// Update handles messages and updates the model (Bubble Tea interface)
+func (m CaptureModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case tea.WindowSizeMsg:
+		m.textArea.SetWidth(msg.Width)
+		m.textArea.SetHeight(max(msg.Height-4, 1)) // Leave space for header and status bar
+
+	case tea.KeyMsg:
+		act, _ := m.keys.lookup(contextCapture, msg)
+		switch act {
+		case actionQuit, actionCancel:
+			return m, tea.Quit
+
+		case actionSave:
+			text := strings.TrimSpace(m.textArea.Value())
+			if text == "" {
+				// Nothing to save, just leave
+				return m, tea.Quit
+			}
+
+			entry := notes.TimestampHeading(time.Now()) + "\n\n" + text
+			filename, err := notes.AddToNote(m.vault, m.inbox, m.cfg.DefaultExtension, entry, false)
+			if err != nil {
+				m.statusMessage = fmt.Sprintf("Failed to save: %v", err)
+				return m, nil
+			}
+
+			m.savedTo = filename
+			return m, tea.Quit
+		}
+	}
+
+	var cmd tea.Cmd
+	m.textArea, cmd = m.textArea.Update(msg)
+	return m, cmd
+}
+
+// View renders the capture screen (Bubble Tea interface)
+func (m CaptureModel) View() string {
+	if m.cfg.Plain {
+		return renderPlainCapture(notes.FileName(m.inbox, m.cfg.DefaultExtension), m.textArea, m.keys, m.statusMessage)
+	}
+
+	header := lipgloss.NewStyle().
+		Foreground(styles.ColorPrimary).
+		Bold(true).
+		Render("📥 Capture → " + notes.FileName(m.inbox, m.cfg.DefaultExtension))
+
+	statusBar := lipgloss.NewStyle().Foreground(styles.ColorText).Render(m.keys.label(contextCapture, actionSave)) +
+		lipgloss.NewStyle().Foreground(styles.ColorMuted).Render(" Save & close") +
+		lipgloss.NewStyle().Foreground(styles.ColorText).Render("  •  "+m.keys.label(contextCapture, actionCancel)) +
+		lipgloss.NewStyle().Foreground(styles.ColorMuted).Render(" Cancel")
+	if m.statusMessage != "" {
+		statusBar = styles.ErrorStyle.Render("❌ " + m.statusMessage)
+	}
+
+	return lipgloss.JoinVertical(
+		lipgloss.Left,
+		header,
+		"",
+		m.textArea.View(),
+		statusBar,
+	)
+}
@@ -0,0 +1,111 @@
+package app
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+
+	"github.com/shalshcode08/Term-Note/internal/config"
+	"github.com/shalshcode08/Term-Note/internal/notes"
+	"github.com/shalshcode08/Term-Note/internal/ui/styles"
+)
+
+func TestCaptureUsesKeymap(t *testing.T) {
+	cfg, err := config.Default()
+	if err != nil {
+		t.Fatal(err)
+	}
+	cfg.Keys = map[string][]string{"capture.save": {"ctrl+w"}}
+	vault := notes.NewMemVault(nil)
+
+	m, err := NewCapture(cfg, vault, "inbox")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if view := m.View(); !strings.Contains(view, "Ctrl+W") {
+		t.Errorf("capture view does not show the save key:\n%s", view)
+	}
+
+	for _, k := range []tea.KeyMsg{runes("buy milk"), {Type: tea.KeyCtrlS}} {
+		next, _ := m.Update(k)
+		m = next.(CaptureModel)
+	}
+	if m.SavedTo() != "" {
+		t.Fatalf("ctrl+s saved to %q after being rebound", m.SavedTo())
+	}
+
+	next, _ := m.Update(tea.KeyMsg{Type: tea.KeyCtrlW})
+	m = next.(CaptureModel)
+	if m.SavedTo() != "inbox.md" {
+		t.Fatalf("SavedTo = %q, want inbox.md", m.SavedTo())
+	}
+	content, err := vault.Read("inbox.md")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(content), "buy milk") {
+		t.Errorf("inbox does not contain the entry:\n%s", content)
+	}
+}
+
+func TestCapturePlainView(t *testing.T) {
+	cfg, err := config.Default()
+	if err != nil {
+		t.Fatal(err)
+	}
+	cfg.Plain = true
+
+	m, err := NewCapture(cfg, notes.NewMemVault(nil), "inbox")
+	if err != nil {
+		t.Fatal(err)
+	}
+	m.statusMessage = "disk full"
+
+	view := m.View()
+	for _, want := range []string{"Capture to inbox.md", "Ctrl+S: Save and close", "Error: disk full"} {
+		if !strings.Contains(view, want) {
+			t.Errorf("plain capture view does not show %q:\n%s", want, view)
+		}
+	}
+	for _, glyph := range []string{"📥", "→", "❌", "•"} {
+		if strings.Contains(view, glyph) {
+			t.Errorf("plain capture view contains %q:\n%s", glyph, view)
+		}
+	}
+}
+
+func TestLoadUserTheme(t *testing.T) {
+	t.Cleanup(func() { styles.Use("dark") })
+	m, _ := newTestModel(t, nil)
+	cfg := m.cfg
+
+	if err := os.MkdirAll(cfg.ThemesDir(), 0755); err != nil {
+		t.Fatal(err)
+	}
+	theme := "base = \"light\"\nprimary = \"#123456\"\n"
+	if err := os.WriteFile(filepath.Join(cfg.ThemesDir(), "ocean.toml"), []byte(theme), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	// The quick capture screen loads the configured theme like the app
+	cfg.Theme = "ocean"
+	if _, err := NewCapture(cfg, notes.NewMemVault(nil), "inbox"); err != nil {
+		t.Fatal(err)
+	}
+	current := styles.Current()
+	if current.Name != "ocean" || current.Primary != "#123456" || styles.ColorText != current.Text {
+		t.Errorf("current theme = %+v", current)
+	}
+	if !slices.Contains(styles.ThemeNames(), "ocean") {
+		t.Errorf("ThemeNames() = %v, want ocean listed", styles.ThemeNames())
+	}
+
+	cfg.Theme = "missing"
+	if _, err := NewCapture(cfg, notes.NewMemVault(nil), "inbox"); err == nil {
+		t.Error("NewCapture with an unknown theme succeeded")
+	}
+}
@@ -0,0 +1,93 @@
+package app
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+
+	"github.com/shalshcode08/Term-Note/internal/ui/styles"
+)
+
+// newCommandInput creates the input used by the : command prompt
+func newCommandInput() textinput.Model {
+	ti := textinput.New()
+	ti.Prompt = ":"
+	ti.Placeholder = "theme <name>"
+	ti.CharLimit = 200
+	ti.ShowSuggestions = true
+	styleTextInput(&ti)
+	return ti
+}
+
+// commandSuggestions returns the completions offered by the command prompt
+func commandSuggestions() []string {
+	var suggestions []string
+	for _, name := range styles.ThemeNames() {
+		suggestions = append(suggestions, "theme "+name)
+	}
+	return append(suggestions, "theme "+styles.ThemeAuto, "encrypt", "import ")
+}
+
+// openCommand shows the : command prompt
+func (m Model) openCommand() (tea.Model, tea.Cmd) {
+	m.showCommand = true
+	m.commandInput.SetValue("")
+	m.commandInput.SetSuggestions(commandSuggestions())
+	return m, m.commandInput.Focus()
+}
+
+// runCommand executes the command typed at the prompt
+func (m Model) runCommand() (tea.Model, tea.Cmd) {
+	m.showCommand = false
+	m.commandInput.Blur()
+
+	fields := strings.Fields(m.commandInput.Value())
+	if len(fields) == 0 {
+		return m, nil
+	}
+
+	switch fields[0] {
+	case "theme":
+		if len(fields) == 1 {
+			m.statusMessage = fmt.Sprintf("Theme: %s (available: %s)", styles.Current().Name, strings.Join(styles.ThemeNames(), ", "))
+			m.statusType = ""
+			return m, nil
+		}
+		if err := m.setTheme(fields[1]); err != nil {
+			m.statusMessage = err.Error()
+			m.statusType = "error"
+			return m, nil
+		}
+		m.statusMessage = "Theme set to " + styles.Current().Name
+		m.statusType = "success"
+
+	case "encrypt":
+		m.openEncrypt()
+		if m.showPassphrase {
+			return m, m.passphraseInput.Focus()
+		}
+
+	case "import":
+		// The path is the rest of the line, spaces included
+		m.openImport(strings.TrimPrefix(strings.TrimSpace(m.commandInput.Value()), "import"))
+
+	default:
+		m.statusMessage = fmt.Sprintf("Unknown command %q", fields[0])
+		m.statusType = "error"
+	}
+
+	return m, nil
+}
+
+// renderCommandLine renders the command prompt shown at the bottom of the screen
+func renderCommandLine(input textinput.Model, width int) string {
+	return lipgloss.NewStyle().
+		Width(width).
+		BorderTop(true).
+		BorderStyle(lipgloss.NormalBorder()).
+		BorderForeground(styles.ColorBorder).
+		Render(input.View())
+}
@@ -0,0 +1,280 @@
+package app
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/charmbracelet/lipgloss"
+
+	"github.com/shalshcode08/Term-Note/internal/notes"
+	"github.com/shalshcode08/Term-Note/internal/ui/styles"
+)
+
+// errChangedOnDisk is returned when saving would overwrite changes made to
+// the note by another program
+var errChangedOnDisk = errors.New("note changed on disk")
+
+// stampDisk records the on-disk version of the open note
+func (m *Model) stampDisk(content string) {
+	info, err := m.vault.Stat(m.currentFile)
+	if err != nil {
+		m.diskStamp = notes.Stamp{}
+		return
+	}
+	data := []byte(content)
+	if m.noteKey != nil {
+		// The file holds the content encrypted
+		if data, err = m.vault.Read(m.currentFile); err != nil {
+			m.diskStamp = notes.Stamp{}
+			return
+		}
+	}
+	m.diskStamp = notes.NewStamp(info, data)
+}
+
+// checkDisk reports whether the open note was changed on disk since it was
+// loaded or saved, returning the new content if so. A note that cannot be
+// read (e.g. because it was deleted) is not treated as changed. An encrypted
+// note that no longer opens with its key is changed, but its content is
+// returned still encrypted and diskSealed is set.
+func (m *Model) checkDisk() (bool, string) {
+	changed, content, err := m.diskStamp.Changed(m.vault, m.currentFile)
+	if err != nil || content == nil {
+		return false, ""
+	}
+	m.diskSealed = false
+	if m.noteKey != nil && changed {
+		plain, err := m.noteKey.Decrypt(content)
+		if err != nil {
+			// Encrypted again with another passphrase, or damaged
+			m.diskSealed = true
+			return true, string(content)
+		}
+		content = plain
+	}
+	if !changed {
+		// Touched but not edited; remember the new time so it is not read again
+		m.stampDisk(string(content))
+		return false, ""
+	}
+	return true, string(content)
+}
+
+// watchDisk checks the open note for outside changes. A note without local
+// edits is reloaded; otherwise the user is asked what to do.
+func (m *Model) watchDisk() {
+	if m.showConflict || m.showUnsaved {
+		return
+	}
+	changed, disk := m.checkDisk()
+	if !changed {
+		return
+	}
+
+	if !m.dirty() && !m.diskSealed {
+		m.reloadNote(disk)
+		m.statusMessage = "Reloaded " + m.currentFile + ", it changed on disk"
+		m.statusType = "warning"
+		return
+	}
+	m.promptConflict(disk, false)
+}
+
+// promptConflict shows the dialog for a note changed on disk. Unless asked
+// for explicitly, it stays closed for a version the user already dismissed.
+func (m *Model) promptConflict(disk string, explicit bool) {
+	if !explicit && disk == m.dismissedDisk {
+		return
+	}
+	m.diskContent = disk
+	m.diskDiff = nil
+	if !m.diskSealed {
+		m.diskDiff = notes.Diff(m.savedContent, disk)
+	}
+	m.showConflict = true
+}
+
+// reopenNote closes the open note and opens it again from disk, asking for
+// the passphrase of an encrypted note that no longer opens with its key
+func (m *Model) reopenNote() {
+	filename := m.currentFile
+	m.closeNote()
+	m.showingList = true
+	m.refreshList()
+	if err := m.OpenNote(filename); err != nil {
+		m.statusMessage = fmt.Sprintf("Cannot read the file: %v", err)
+		m.statusType = "error"
+	}
+}
+
+// reloadNote replaces the editor content with the version on disk
+func (m *Model) reloadNote(disk string) {
+	filename := m.currentFile
+	readOnly := m.readOnly
+	m.setNote(filename, disk)
+	m.readOnly = readOnly
+	m.stampDisk(disk)
+}
+
+// mergeNote merges the editor changes with the changes made on disk, using
+// the content last loaded or saved as the common base
+func (m *Model) mergeNote() {
+	if m.diskSealed {
+		m.statusMessage = m.currentFile + " was encrypted with another passphrase and cannot be merged"
+		m.statusType = "warning"
+		return
+	}
+	merged, conflicts := notes.Merge3(m.savedContent, m.textArea.Value(), m.diskContent)
+
+	m.textArea.SetValue(merged)
+	m.savedContent = m.diskContent
+	m.stampDisk(m.diskContent)
+
+	if conflicts == 0 {
+		m.statusMessage = "Merged the changes made on disk"
+		m.statusType = "success"
+		return
+	}
+	m.statusMessage = fmt.Sprintf("%d conflict(s): edit the %s ... %s blocks, then save", conflicts, notes.MarkerOurs, notes.MarkerTheirs)
+	m.statusType = "warning"
+}
+
+// sealedMessage explains a change on disk that the note's key cannot open
+const sealedMessage = "Another program saved this note encrypted with another\npassphrase, so it cannot be shown or merged here."
+
+// renderConflictDialog renders the choices for a note changed on disk,
+// with the lines that changed there
+func renderConflictDialog(filename string, diff []notes.DiffLine, sealed bool, keys keyMap) string {
+	dialogStyle := lipgloss.NewStyle().
+		Border(lipgloss.RoundedBorder()).
+		BorderForeground(styles.ColorWarning).
+		Padding(1, 4).
+		Width(72)
+
+	titleStyle := lipgloss.NewStyle().
+		Foreground(styles.ColorWarning).
+		Bold(true).
+		Align(lipgloss.Center).
+		Width(64)
+
+	filenameStyle := lipgloss.NewStyle().
+		Foreground(styles.ColorPrimary).
+		Bold(true).
+		Align(lipgloss.Center).
+		Width(64)
+
+	messageStyle := lipgloss.NewStyle().
+		Foreground(styles.ColorMuted).
+		Align(lipgloss.Center).
+		Width(64).
+		MarginTop(1)
+
+	buttonsStyle := lipgloss.NewStyle().
+		Align(lipgloss.Center).
+		Width(64).
+		MarginTop(1)
+
+	added, removed := notes.DiffStats(diff)
+
+	title := titleStyle.Render("⚠️  CHANGED ON DISK")
+	file := filenameStyle.Render(filename)
+	message := messageStyle.Render(fmt.Sprintf(
+		"Another program changed this note (+%d -%d lines)\nwhile it had unsaved edits here.", added, removed))
+	if sealed {
+		message = messageStyle.Render(sealedMessage)
+	}
+
+	preview := lipgloss.NewStyle().
+		MarginTop(1).
+		Width(64).
+		Render(renderDiffPreview(diff, 8))
+
+	button := func(label string, act action, color lipgloss.Color) string {
+		return lipgloss.NewStyle().
+			Foreground(styles.ColorBg).
+			Background(color).
+			Bold(true).
+			Render(fmt.Sprintf(" %s (%s) ", label, keys.label(contextConflict, act)))
+	}
+	choices := []string{
+		button("Reload", actionReload, styles.ColorSecondary),
+		button("Overwrite", actionOverwrite, styles.ColorError),
+		button("Merge", actionMerge, styles.ColorSuccess),
+		button("Cancel", actionCancel, styles.ColorMuted),
+	}
+	if sealed {
+		choices = append(choices[:2], choices[3])
+	}
+	buttons := buttonsStyle.Render(strings.Join(choices, " "))
+
+	content := lipgloss.JoinVertical(
+		lipgloss.Left,
+		title,
+		"",
+		file,
+		message,
+		preview,
+		buttons,
+	)
+
+	return dialogStyle.Render(content)
+}
+
+// renderDiffPreview renders up to limit changed lines of a diff
+func renderDiffPreview(diff []notes.DiffLine, limit int) string {
+	addStyle := lipgloss.NewStyle().Foreground(styles.ColorSuccess)
+	delStyle := lipgloss.NewStyle().Foreground(styles.ColorError)
+
+	var lines []string
+	more := 0
+	for _, line := range diff {
+		if line.Op == notes.DiffEqual {
+			continue
+		}
+		if len(lines) == limit {
+			more++
+			continue
+		}
+		if line.Op == notes.DiffInsert {
+			lines = append(lines, addStyle.Render("+ "+line.Text))
+		} else {
+			lines = append(lines, delStyle.Render("- "+line.Text))
+		}
+	}
+	if more > 0 {
+		lines = append(lines, lipgloss.NewStyle().Foreground(styles.ColorMuted).Render(fmt.Sprintf("... %d more", more)))
+	}
+	return strings.Join(lines, "\n")
+}
+
+// renderPlainConflictDialog renders the choices for a note changed on disk in plain mode
+func renderPlainConflictDialog(filename string, diff []notes.DiffLine, sealed bool, keys keyMap) string {
+	if sealed {
+		return strings.Join([]string{
+			"Changed on disk: " + filename,
+			"",
+			strings.ReplaceAll(sealedMessage, "\n", " "),
+			"",
+			fmt.Sprintf("Press %s to reload with its passphrase, %s to overwrite or %s to cancel.",
+				plainLabel(keys, contextConflict, actionReload),
+				plainLabel(keys, contextConflict, actionOverwrite),
+				plainLabel(keys, contextConflict, actionCancel)),
+		}, "\n")
+	}
+	added, removed := notes.DiffStats(diff)
+
+	return strings.Join([]string{
+		"Changed on disk: " + filename,
+		"",
+		fmt.Sprintf("Another program changed this note (%d lines added, %d removed) while it had unsaved edits here.", added, removed),
+		"",
+		renderDiffPreview(diff, 8),
+		"",
+		fmt.Sprintf("Press %s to reload, %s to overwrite, %s to merge or %s to cancel.",
+			plainLabel(keys, contextConflict, actionReload),
+			plainLabel(keys, contextConflict, actionOverwrite),
+			plainLabel(keys, contextConflict, actionMerge),
+			plainLabel(keys, contextConflict, actionCancel)),
+	}, "\n")
+}
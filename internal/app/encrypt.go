@@ -0,0 +1,335 @@
+package app
+
+import (
+	"errors"
+	"fmt"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/charmbracelet/bubbles/textinput"
+	"github.com/charmbracelet/lipgloss"
+
+	"github.com/shalshcode08/Term-Note/internal/notes"
+	"github.com/shalshcode08/Term-Note/internal/ui/styles"
+)
+
+// passphraseStep is what the passphrase dialog is asking for
+type passphraseStep int
+
+const (
+	passphraseUnlock passphraseStep = iota // Passphrase of the encrypted note being opened
+	passphraseNew                          // New passphrase for the note being encrypted
+	passphraseRepeat                       // The new passphrase again, to catch typos
+)
+
+// keyring remembers the passphrase typed last and the keys derived from
+// it, so that encrypted notes open without asking again until it expires
+type keyring struct {
+	passphrase string
+	keys       []*notes.Key
+	expires    time.Time
+}
+
+// remember keeps a passphrase and its key for ttl, replacing a different
+// passphrase. Nothing is kept when ttl is zero.
+func (k *keyring) remember(passphrase string, key *notes.Key, ttl time.Duration, now time.Time) {
+	if ttl <= 0 {
+		return
+	}
+	if passphrase != k.passphrase {
+		*k = keyring{passphrase: passphrase}
+	}
+	k.keys = append(k.keys, key)
+	k.expires = now.Add(ttl)
+}
+
+// find returns a key that opens an encrypted note, deriving it from the
+// remembered passphrase if needed, or nil if the passphrase must be asked.
+// Using the keyring keeps it for another ttl.
+func (k *keyring) find(data []byte, ttl time.Duration, now time.Time) *notes.Key {
+	k.expire(now)
+	if k.passphrase == "" {
+		return nil
+	}
+	for _, key := range k.keys {
+		if key.Matches(data) {
+			k.expires = now.Add(ttl)
+			return key
+		}
+	}
+	key, err := notes.DeriveKey(k.passphrase, data)
+	if err != nil {
+		return nil
+	}
+	k.keys = append(k.keys, key)
+	k.expires = now.Add(ttl)
+	return key
+}
+
+// expire forgets the passphrase once its time is up
+func (k *keyring) expire(now time.Time) {
+	if k.passphrase != "" && !now.Before(k.expires) {
+		*k = keyring{}
+	}
+}
+
+// newPassphraseInput creates the masked input of the passphrase dialog
+func newPassphraseInput() textinput.Model {
+	ti := textinput.New()
+	ti.Placeholder = "passphrase"
+	ti.CharLimit = 200
+	ti.Width = 56
+	ti.Prompt = ""
+	ti.EchoMode = textinput.EchoPassword
+	ti.EchoCharacter = '•'
+	styleTextInput(&ti)
+	return ti
+}
+
+// askPassphrase shows the passphrase dialog. filename is the encrypted note
+// to open when unlocking.
+func (m *Model) askPassphrase(step passphraseStep, filename string) {
+	m.passphraseStep = step
+	m.passphraseFile = filename
+	m.passphraseInput.SetValue("")
+	m.passphraseInput.Focus()
+	m.showPassphrase = true
+	m.statusMessage = ""
+	m.statusType = ""
+}
+
+// closePassphrase hides the passphrase dialog and forgets what was typed in it
+func (m *Model) closePassphrase() {
+	m.showPassphrase = false
+	m.passphraseFile = ""
+	m.newPassphrase = ""
+	m.passphraseInput.Blur()
+	m.passphraseInput.SetValue("")
+}
+
+// passphraseNote returns the note the passphrase dialog is about: the one
+// being unlocked, or else the open note being encrypted
+func (m Model) passphraseNote() string {
+	if m.passphraseFile != "" {
+		return m.passphraseFile
+	}
+	return m.currentFile
+}
+
+// submitPassphrase handles the passphrase typed in the dialog
+func (m *Model) submitPassphrase() {
+	passphrase := m.passphraseInput.Value()
+	if passphrase == "" {
+		m.statusMessage = "Please enter a passphrase"
+		m.statusType = "error"
+		return
+	}
+
+	switch m.passphraseStep {
+	case passphraseUnlock:
+		m.unlockEncrypted(passphrase)
+	case passphraseNew:
+		m.newPassphrase = passphrase
+		m.passphraseStep = passphraseRepeat
+		m.passphraseInput.SetValue("")
+		m.statusMessage = ""
+		m.statusType = ""
+	case passphraseRepeat:
+		if passphrase != m.newPassphrase {
+			m.newPassphrase = ""
+			m.passphraseStep = passphraseNew
+			m.passphraseInput.SetValue("")
+			m.statusMessage = "The passphrases do not match, enter it again"
+			m.statusType = "error"
+			return
+		}
+		m.closePassphrase()
+		m.encryptNote(passphrase)
+	}
+}
+
+// unlockEncrypted opens the encrypted note waiting in the passphrase dialog
+func (m *Model) unlockEncrypted(passphrase string) {
+	filename := m.passphraseFile
+	data, err := m.vault.Read(filename)
+	if err != nil {
+		m.closePassphrase()
+		m.statusMessage = fmt.Sprintf("Cannot read the file: %v", err)
+		m.statusType = "error"
+		return
+	}
+	key, err := notes.DeriveKey(passphrase, data)
+	if errors.Is(err, notes.ErrWrongPassphrase) {
+		m.passphraseInput.SetValue("")
+		m.statusMessage = "Wrong passphrase"
+		m.statusType = "error"
+		return
+	}
+
+	m.closePassphrase()
+	if err == nil {
+		m.passphrases.remember(passphrase, key, m.cfg.Encryption.CacheTimeout, time.Now())
+		err = m.openNote(filename, key)
+	}
+	if err != nil {
+		m.statusMessage = fmt.Sprintf("Cannot read the file: %v", err)
+		m.statusType = "error"
+	}
+}
+
+// openEncrypt starts encrypting the open note by asking for a new passphrase
+func (m *Model) openEncrypt() {
+	switch {
+	case m.currentFile == "":
+		m.statusMessage = "Open a note to encrypt it"
+		m.statusType = "warning"
+	case m.noteKey != nil:
+		m.statusMessage = m.currentFile + " is already encrypted"
+		m.statusType = "warning"
+	case m.readOnly:
+		m.statusMessage = "Cannot encrypt a read-only note"
+		m.statusType = "error"
+	default:
+		m.askPassphrase(passphraseNew, "")
+	}
+}
+
+// encryptNote replaces the open note with an encrypted copy of the editor
+// content. Its plain text history and swap file are deleted and the links
+// to it are updated for the new filename.
+func (m *Model) encryptNote(passphrase string) {
+	key, err := notes.NewKey(passphrase)
+	if err != nil {
+		m.statusMessage = fmt.Sprintf("Cannot encrypt note: %v", err)
+		m.statusType = "error"
+		return
+	}
+
+	from := m.currentFile
+	content := m.textArea.Value()
+	to, err := notes.EncryptNote(m.vault, from, []byte(content), key)
+	switch {
+	case errors.Is(err, notes.ErrExists):
+		m.statusMessage = "File already exists with this name: " + from + notes.EncryptedExt
+		m.statusType = "error"
+		return
+	case err != nil && to == "":
+		m.statusMessage = fmt.Sprintf("Cannot encrypt note: %v", err)
+		m.statusType = "error"
+		return
+	}
+
+	var problems []string
+	if err != nil {
+		problems = append(problems, err.Error())
+	}
+	m.dropSwap()
+	m.unlockNote()
+	if err := notes.DeleteSnapshots(m.cfg.HistoryDir(), from); err != nil {
+		problems = append(problems, "its plain text history is still there: "+err.Error())
+	}
+	changed, err := notes.RewriteLinks(m.vault, from, to)
+	if err != nil {
+		problems = append(problems, "some links were not updated: "+err.Error())
+	}
+
+	m.currentFile = to
+	m.noteKey = key
+	m.savedContent = content
+	m.lastSaved = time.Now()
+	m.stampDisk(content)
+	m.lockNote()
+	m.passphrases.remember(passphrase, key, m.cfg.Encryption.CacheTimeout, time.Now())
+
+	message := fmt.Sprintf("Encrypt %s", filepath.ToSlash(from))
+	m.commitNotes(message, append([]string{from, to}, changed...)...)
+	if m.gitRepo && m.cfg.Git.AutoCommit {
+		problems = append(problems, "earlier git commits may still hold its plain text")
+	}
+
+	m.statusMessage = fmt.Sprintf("Encrypted %s as %s", from, to)
+	m.statusType = "success"
+	switch len(changed) {
+	case 0:
+	case 1:
+		m.statusMessage += " · updated links in 1 note"
+	default:
+		m.statusMessage += fmt.Sprintf(" · updated links in %d notes", len(changed))
+	}
+	if len(problems) > 0 {
+		m.statusMessage += ", but " + strings.Join(problems, " and ")
+		m.statusType = "warning"
+	}
+}
+
+// renderPassphraseDialog renders the dialog asking for the passphrase of an
+// encrypted note, or a new one for the note being encrypted
+func renderPassphraseDialog(step passphraseStep, filename string, input textinput.Model, keys keyMap, statusMsg string, statusType string) string {
+	title, label, tip := passphraseText(step)
+	title = styles.DialogTitleStyle.Render("🔒 " + strings.ToUpper(title))
+	file := lipgloss.NewStyle().
+		Foreground(styles.ColorPrimary).
+		Bold(true).
+		Render(filepath.ToSlash(filename))
+
+	promptSymbol := lipgloss.NewStyle().
+		Foreground(styles.ColorPrimary).
+		Bold(true).
+		Render("› ")
+	inputBox := styles.InputBoxStyle.Render(lipgloss.JoinHorizontal(lipgloss.Left, promptSymbol, input.View()))
+
+	statusLine := styles.InputTipStyle.Render("💡 Tip: " + tip)
+	if statusMsg != "" {
+		statusLine = statusStyle(statusType).Render(statusMsg)
+	}
+
+	helpText := styles.InputHelpStyle.Render(fmt.Sprintf("⏎ %s to continue  •  %s to cancel",
+		keys.label(contextPassphrase, actionConfirm), keys.label(contextPassphrase, actionCancel)))
+
+	content := lipgloss.JoinVertical(
+		lipgloss.Left,
+		title,
+		"",
+		file,
+		"",
+		styles.InputLabelStyle.Render(label),
+		inputBox,
+		"",
+		statusLine,
+		"",
+		helpText,
+	)
+	return styles.DialogBoxStyle.Render(content)
+}
+
+// renderPlainPassphraseDialog renders the passphrase prompt in plain mode
+func renderPlainPassphraseDialog(step passphraseStep, filename string, input textinput.Model, keys keyMap, statusMsg string, statusType string) string {
+	title, label, tip := passphraseText(step)
+	lines := []string{
+		title + ": " + filepath.ToSlash(filename),
+		"",
+		label,
+		input.View(),
+		tip + ".",
+	}
+	if statusMsg != "" {
+		lines = append(lines, "", plainStatus(statusMsg, statusType))
+	}
+	lines = append(lines, "", fmt.Sprintf("Press %s to continue or %s to cancel.",
+		plainLabel(keys, contextPassphrase, actionConfirm), plainLabel(keys, contextPassphrase, actionCancel)))
+	return strings.Join(lines, "\n")
+}
+
+// passphraseText returns the title, input label and tip of the passphrase
+// dialog for a step
+func passphraseText(step passphraseStep) (title, label, tip string) {
+	switch step {
+	case passphraseNew:
+		return "Encrypt note", "New Passphrase:", "There is no way to open the note without it"
+	case passphraseRepeat:
+		return "Encrypt note", "Repeat Passphrase:", "There is no way to open the note without it"
+	default:
+		return "Encrypted note", "Passphrase:", "The passphrase is remembered for a while after it is used"
+	}
+}
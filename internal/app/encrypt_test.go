@@ -0,0 +1,93 @@
+package app
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/shalshcode08/Term-Note/internal/notes"
+	"github.com/shalshcode08/Term-Note/internal/ui/styles"
+)
+
+// encryptedNote returns a note encrypted with passphrase, and its key
+func encryptedNote(t *testing.T, passphrase, content string) ([]byte, *notes.Key) {
+	t.Helper()
+	key, err := notes.NewKey(passphrase)
+	if err != nil {
+		t.Fatal(err)
+	}
+	data, err := key.Encrypt([]byte(content))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return data, key
+}
+
+func TestEncryptedNotesAreNotAutosaved(t *testing.T) {
+	data, key := encryptedNote(t, "correct horse", "secret")
+	m, vault := newTestModel(t, map[string]string{"secret.md.enc": string(data)})
+	if err := m.openNote("secret.md.enc", key); err != nil {
+		t.Fatal(err)
+	}
+
+	m.textArea.SetValue("secret, edited")
+	m.autosave()
+	if !m.dirty() {
+		t.Fatal("autosave saved an encrypted note")
+	}
+	if onDisk, _ := vault.Read("secret.md.enc"); !bytes.Equal(onDisk, data) {
+		t.Fatal("autosave rewrote the encrypted file")
+	}
+
+	if err := m.saveNote(); err != nil {
+		t.Fatal(err)
+	}
+	onDisk, _ := vault.Read("secret.md.enc")
+	if plain, err := key.Decrypt(onDisk); err != nil || string(plain) != "secret, edited" {
+		t.Fatalf("after saving: %q, %v", plain, err)
+	}
+}
+
+func TestSealedDiskVersionRaisesConflict(t *testing.T) {
+	data, key := encryptedNote(t, "correct horse", "secret")
+	m, vault := newTestModel(t, map[string]string{"secret.md.enc": string(data)})
+	if err := m.openNote("secret.md.enc", key); err != nil {
+		t.Fatal(err)
+	}
+
+	// Another program encrypts the note with another passphrase
+	other, _ := encryptedNote(t, "battery staple", "their version")
+	if err := vault.Write("secret.md.enc", other); err != nil {
+		t.Fatal(err)
+	}
+
+	// Even without local edits, the unreadable version is not loaded
+	m.watchDisk()
+	if !m.showConflict || !m.diskSealed {
+		t.Fatalf("showConflict = %v, diskSealed = %v, want both", m.showConflict, m.diskSealed)
+	}
+	if m.textArea.Value() != "secret" {
+		t.Fatalf("editor holds %q", m.textArea.Value())
+	}
+	if view := m.View(); !strings.Contains(view, "another") || strings.Contains(view, "Merge (") {
+		t.Errorf("conflict dialog does not explain the sealed version:\n%s", view)
+	}
+
+	// Reloading asks for the passphrase that opens it
+	m = press(t, m, runes("r"))
+	if !m.showPassphrase || m.passphraseFile != "secret.md.enc" {
+		t.Fatalf("reload: showPassphrase = %v for %q", m.showPassphrase, m.passphraseFile)
+	}
+}
+
+func TestThemeRestylesPassphraseInput(t *testing.T) {
+	t.Cleanup(func() { styles.Use("dark") })
+	m, _ := newTestModel(t, nil)
+
+	if err := m.setTheme("light"); err != nil {
+		t.Fatal(err)
+	}
+	if got, want := m.passphraseInput.TextStyle.GetForeground(), styles.Current().Text; got != want {
+		t.Errorf("passphrase input text color = %v, want %v", got, want)
+	}
+}
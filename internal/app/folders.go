@@ -0,0 +1,216 @@
+package app
+
+import (
+	"errors"
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"github.com/charmbracelet/bubbles/textinput"
+	"github.com/charmbracelet/lipgloss"
+
+	"github.com/shalshcode08/Term-Note/internal/config"
+	"github.com/shalshcode08/Term-Note/internal/notes"
+	"github.com/shalshcode08/Term-Note/internal/ui/styles"
+)
+
+// listTitle returns the note list title, naming the vault when there is a
+// choice and the folders leading to the one being browsed
+func listTitle(cfg *config.Config, folder string) string {
+	title := "All Notes"
+	if len(cfg.Vaults) > 1 {
+		title += " · " + cfg.VaultName
+	}
+	if folder != "" {
+		title += " / " + strings.Join(strings.Split(filepath.ToSlash(folder), "/"), " / ")
+	}
+	return title
+}
+
+// newMoveInput creates the folder prompt of the move dialog
+func newMoveInput() textinput.Model {
+	ti := textinput.New()
+	ti.Placeholder = "vault root"
+	ti.CharLimit = 100
+	ti.Width = 56
+	ti.Prompt = ""
+	ti.ShowSuggestions = true
+	styleTextInput(&ti)
+	return ti
+}
+
+// refreshList reloads the note list for the folder being browsed, going
+// back to the vault root if the folder is gone
+func (m *Model) refreshList() {
+	items, err := notes.ListFiles(m.vault, m.folder, m.cfg.Plain)
+	if err != nil && m.folder != "" {
+		m.folder = ""
+		items, err = notes.ListFiles(m.vault, m.folder, m.cfg.Plain)
+	}
+	if err != nil {
+		m.statusMessage = err.Error()
+		m.statusType = "error"
+	}
+	m.fileList.Title = listTitle(m.cfg, m.folder)
+	m.updateListItems(items)
+}
+
+// openFolder shows the notes in a folder of the vault
+func (m *Model) openFolder(folder string) {
+	m.folder = folder
+	m.fileList.ResetFilter()
+	m.refreshList()
+	m.fileList.ResetSelected()
+	m.statusMessage = ""
+	m.statusType = ""
+}
+
+// parentFolder goes up one folder and selects the folder it came from
+func (m *Model) parentFolder() {
+	if m.folder == "" {
+		return
+	}
+	child := m.folder
+	parent := filepath.Dir(m.folder)
+	if parent == "." {
+		parent = ""
+	}
+	m.openFolder(parent)
+	for i, item := range m.fileList.Items() {
+		if note, ok := item.(notes.Item); ok && note.IsDir() && note.Filename() == child {
+			m.fileList.Select(i)
+			break
+		}
+	}
+}
+
+// openMove shows the dialog for moving the selected note to another folder
+func (m *Model) openMove() {
+	item, ok := m.fileList.SelectedItem().(notes.Item)
+	if !ok {
+		return
+	}
+	if item.IsDir() {
+		m.statusMessage = "Only notes can be moved, not folders"
+		m.statusType = "warning"
+		return
+	}
+
+	folders, err := notes.ListFolders(m.vault)
+	if err != nil {
+		m.statusMessage = err.Error()
+		m.statusType = "error"
+		return
+	}
+	suggestions := make([]string, 0, len(folders))
+	for _, folder := range folders {
+		suggestions = append(suggestions, filepath.ToSlash(folder))
+	}
+
+	m.fileToMove = item.Filename()
+	m.moveInput.SetSuggestions(suggestions)
+	m.moveInput.SetValue(filepath.ToSlash(m.folder))
+	m.moveInput.CursorEnd()
+	m.moveInput.Focus()
+	m.showMove = true
+	m.statusMessage = ""
+	m.statusType = ""
+}
+
+// closeMove hides the move dialog
+func (m *Model) closeMove() {
+	m.showMove = false
+	m.fileToMove = ""
+	m.moveInput.Blur()
+	m.moveInput.SetValue("")
+}
+
+// moveNote moves the note chosen in the move dialog to the folder typed in
+// it, creating the folder if needed
+func (m *Model) moveNote() {
+	folder := strings.Trim(strings.TrimSpace(m.moveInput.Value()), "/")
+	if folder != "" && notes.ValidateName(folder) != nil {
+		m.statusMessage = "Folder name contains invalid characters"
+		m.statusType = "error"
+		return
+	}
+
+	from := m.fileToMove
+	to := filepath.Join(filepath.FromSlash(folder), filepath.Base(from))
+	if to == from {
+		m.closeMove()
+		m.statusMessage = from + " is already in that folder"
+		m.statusType = "warning"
+		return
+	}
+
+	err := m.relocateNote("Moved", from, to)
+	var locked *notes.LockedError
+	switch {
+	case errors.Is(err, notes.ErrExists):
+		m.statusMessage = "File already exists with this name: " + to
+		m.statusType = "error"
+	case errors.As(err, &locked):
+		m.statusMessage = "Cannot move: " + err.Error()
+		m.statusType = "error"
+	case err != nil:
+		m.statusMessage = err.Error()
+		m.statusType = "error"
+	default:
+		m.closeMove()
+	}
+}
+
+// renderMoveDialog renders the dialog for moving a note to another folder
+func renderMoveDialog(filename string, input textinput.Model, keys keyMap, statusMsg string, statusType string) string {
+	title := styles.DialogTitleStyle.Render("📂  MOVE NOTE")
+	file := lipgloss.NewStyle().
+		Foreground(styles.ColorPrimary).
+		Bold(true).
+		Render(filepath.ToSlash(filename))
+
+	promptSymbol := lipgloss.NewStyle().
+		Foreground(styles.ColorPrimary).
+		Bold(true).
+		Render("› ")
+	inputBox := styles.InputBoxStyle.Render(lipgloss.JoinHorizontal(lipgloss.Left, promptSymbol, input.View()))
+
+	statusLine := styles.InputTipStyle.Render("💡 Tip: Leave empty to move the note to the vault root")
+	if statusMsg != "" {
+		statusLine = statusStyle(statusType).Render(statusMsg)
+	}
+
+	helpText := styles.InputHelpStyle.Render(fmt.Sprintf("⇥ %s to complete  •  ⏎ %s to move  •  %s to cancel",
+		keys.label(contextMove, actionNone), keys.label(contextMove, actionConfirm), keys.label(contextMove, actionCancel)))
+
+	content := lipgloss.JoinVertical(
+		lipgloss.Left,
+		title,
+		"",
+		file,
+		"",
+		styles.InputLabelStyle.Render("Move to folder:"),
+		inputBox,
+		"",
+		statusLine,
+		"",
+		helpText,
+	)
+	return styles.DialogBoxStyle.Render(content)
+}
+
+// renderPlainMoveDialog renders the move prompt in plain mode
+func renderPlainMoveDialog(filename string, input textinput.Model, keys keyMap, statusMsg string, statusType string) string {
+	lines := []string{
+		"Move note: " + filepath.ToSlash(filename),
+		"",
+		"Move to folder (empty for the vault root):",
+		input.View(),
+	}
+	if statusMsg != "" {
+		lines = append(lines, "", plainStatus(statusMsg, statusType))
+	}
+	lines = append(lines, "", fmt.Sprintf("Press %s to move or %s to cancel.",
+		plainLabel(keys, contextMove, actionConfirm), plainLabel(keys, contextMove, actionCancel)))
+	return strings.Join(lines, "\n")
+}
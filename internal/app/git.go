@@ -0,0 +1,371 @@
+package app
+
+import (
+	"errors"
+	"fmt"
+	"path/filepath"
+	"strings"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+
+	"github.com/shalshcode08/Term-Note/internal/notes"
+	"github.com/shalshcode08/Term-Note/internal/ui/styles"
+)
+
+// gitCommitMsg reports the end of the commits started by startGit
+type gitCommitMsg struct {
+	message string // Message of the first commit that failed
+	err     error
+}
+
+// gitSyncMsg reports the end of a pull and push started by startGit
+type gitSyncMsg struct {
+	err error
+}
+
+// gitQuitTimeout is how long a stop signal waits for the queued commits
+const gitQuitTimeout = 5 * time.Second
+
+// gitQuitTimeoutMsg ends the wait for commits started by a stop signal
+type gitQuitTimeoutMsg struct{}
+
+// pendingCommit is a commit waiting for its turn to run
+type pendingCommit struct {
+	message   string
+	filenames []string
+}
+
+// commitNotes queues a commit of notes in a git vault with auto-commit on.
+// Other vaults are left alone. The commit runs in the background once the
+// current message is handled; a failure is reported in the status bar.
+func (m *Model) commitNotes(message string, filenames ...string) {
+	if !m.gitRepo || !m.cfg.Git.AutoCommit {
+		return
+	}
+	m.commits = append(m.commits, pendingCommit{message, filenames})
+}
+
+// startGit returns the command running the next git work in the
+// background: the queued commits, or else the sync asked for. Only one runs
+// at a time, so commits never race with a pull or push.
+func (m *Model) startGit() tea.Cmd {
+	if m.gitRunning {
+		return nil
+	}
+	dir := m.cfg.VaultDir
+
+	if commits := m.commits; len(commits) > 0 {
+		m.commits = nil
+		m.gitRunning = true
+		return func() tea.Msg {
+			for _, c := range commits {
+				if err := notes.GitCommit(dir, c.message, c.filenames...); err != nil {
+					return gitCommitMsg{message: c.message, err: err}
+				}
+			}
+			return gitCommitMsg{}
+		}
+	}
+
+	if m.syncing && !m.quitting {
+		m.gitRunning = true
+		return func() tea.Msg {
+			return gitSyncMsg{err: notes.GitSync(dir)}
+		}
+	}
+	return nil
+}
+
+// finishCommits reports the commits that failed
+func (m *Model) finishCommits(msg gitCommitMsg) {
+	m.gitRunning = false
+	if msg.err != nil {
+		m.statusMessage = fmt.Sprintf("Git commit %q failed: %v", msg.message, msg.err)
+		m.statusType = "warning"
+	}
+}
+
+// syncVault asks for a pull and push of the vault, which runs in the
+// background after any queued commits
+func (m *Model) syncVault() {
+	if !m.gitRepo {
+		m.statusMessage = "The vault is not a git repository"
+		m.statusType = "warning"
+		return
+	}
+	if m.syncing {
+		return
+	}
+
+	m.syncing = true
+	m.statusMessage = "Syncing with the remote..."
+	m.statusType = ""
+}
+
+// finishSync reports the result of a sync and shows any merge conflicts
+func (m *Model) finishSync(err error) {
+	m.gitRunning = false
+	m.syncing = false
+	var conflicts *notes.ConflictError
+	switch {
+	case errors.As(err, &conflicts):
+		m.gitConflicts = conflicts.Files
+		m.gitConflictCursor = 0
+		m.showGitConflicts = true
+		m.statusMessage = "Pulling left merge conflicts"
+		m.statusType = "error"
+	case err != nil:
+		m.statusMessage = fmt.Sprintf("Sync failed: %v", err)
+		m.statusType = "error"
+	default:
+		m.statusMessage = "Synced with the remote"
+		m.statusType = "success"
+	}
+	if m.currentFile == "" {
+		m.refreshList()
+	}
+}
+
+// openConflict opens the highlighted conflicted note to resolve it
+func (m *Model) openConflict() {
+	if m.gitConflictCursor >= len(m.gitConflicts) {
+		return
+	}
+	m.autosave()
+	if m.currentFile != "" && m.dirty() {
+		m.statusMessage = "Save or close " + m.currentFile + " before resolving conflicts"
+		m.statusType = "warning"
+		return
+	}
+
+	filename := m.gitConflicts[m.gitConflictCursor]
+	m.showGitConflicts = false
+	if err := m.OpenNote(filename); err != nil {
+		m.statusMessage = fmt.Sprintf("Cannot read the file: %v", err)
+		m.statusType = "error"
+		return
+	}
+	m.statusMessage = "Resolve the conflict markers in " + filename + " and save to commit the merge"
+	m.statusType = "warning"
+}
+
+// abortMerge abandons the merge left by a pull with conflicts
+func (m *Model) abortMerge() {
+	m.showGitConflicts = false
+	if err := notes.GitAbortMerge(m.cfg.VaultDir); err != nil {
+		m.statusMessage = err.Error()
+		m.statusType = "error"
+		return
+	}
+	m.gitConflicts = nil
+	m.statusMessage = "Merge aborted, your notes are as they were before the pull"
+	m.statusType = "success"
+	m.refreshList()
+}
+
+// openGitLog shows the commits of the open note
+func (m *Model) openGitLog() {
+	if !m.gitRepo {
+		m.statusMessage = "The vault is not a git repository"
+		m.statusType = "warning"
+		return
+	}
+	commits, err := notes.GitLog(m.cfg.VaultDir, m.currentFile)
+	if err != nil {
+		m.statusMessage = err.Error()
+		m.statusType = "error"
+		return
+	}
+	if len(commits) == 0 {
+		m.statusMessage = "No commits of " + m.currentFile + " yet"
+		m.statusType = "warning"
+		return
+	}
+
+	m.gitLog = commits
+	m.showGitLog = true
+	m.statusMessage = ""
+	m.statusType = ""
+	m.selectCommit(0)
+}
+
+// selectCommit highlights a commit and shows the changes it made to the note
+func (m *Model) selectCommit(i int) {
+	i = max(0, min(i, len(m.gitLog)-1))
+	m.gitLogCursor = i
+	m.historyScroll = 0
+
+	patch, err := notes.GitPatch(m.cfg.VaultDir, m.gitLog[i])
+	if err != nil {
+		m.historyDiff = ""
+		m.statusMessage = err.Error()
+		m.statusType = "error"
+		return
+	}
+	m.historyDiff = patch
+}
+
+// renderGitLogView renders the commits of a note and the patch of the
+// highlighted one
+func renderGitLogView(filename string, commits []notes.Commit, cursor int, patch string, scroll, width, height int, keys keyMap, statusMessage, statusType string) string {
+	header := lipgloss.NewStyle().
+		Foreground(styles.ColorPrimary).
+		Bold(true).
+		Render(fmt.Sprintf("🌿 Git log · %s (%d commits)", filepath.ToSlash(filename), len(commits)))
+
+	first := max(0, min(cursor-historyRows/2, len(commits)-historyRows))
+	var rows []string
+	for i := first; i < len(commits) && i < first+historyRows; i++ {
+		label := commitLabel(commits[i])
+		if i == cursor {
+			rows = append(rows, lipgloss.NewStyle().Foreground(styles.ColorPrimary).Bold(true).Render("▸ "+label))
+		} else {
+			rows = append(rows, lipgloss.NewStyle().Foreground(styles.ColorMuted).Render("  "+label))
+		}
+	}
+
+	separator := lipgloss.NewStyle().Foreground(styles.ColorMuted).Render(strings.Repeat("─", max(1, width)))
+
+	key := func(act action, desc string) string {
+		return lipgloss.NewStyle().Foreground(styles.ColorText).Render(keys.label(contextGitLog, act)) +
+			lipgloss.NewStyle().Foreground(styles.ColorMuted).Render(" "+desc)
+	}
+	statusBar := strings.Join([]string{
+		key(actionUp, "Newer"),
+		key(actionDown, "Older"),
+		key(actionPageDown, "Scroll changes"),
+		key(actionCancel, "Close"),
+	}, lipgloss.NewStyle().Foreground(styles.ColorMuted).Render("  •  "))
+	if statusMessage != "" {
+		statusBar += "  •  " + statusStyle(statusType).Render(statusMessage)
+	}
+
+	return lipgloss.JoinVertical(
+		lipgloss.Left,
+		header,
+		"",
+		strings.Join(rows, "\n"),
+		separator,
+		strings.Join(renderDiffLines(patch, scroll, historyPage(height), "No changes to the note's content"), "\n"),
+		"",
+		statusBar,
+	)
+}
+
+// commitLabel describes a commit in one line of the git log
+func commitLabel(c notes.Commit) string {
+	hash := c.Hash
+	if len(hash) > 7 {
+		hash = hash[:7]
+	}
+	return fmt.Sprintf("%s  %-16s %-14s %s", hash, notes.FormatRelativeTime(c.Time), c.Author, c.Subject)
+}
+
+// renderGitConflicts renders the dialog listing the notes a pull left in conflict
+func renderGitConflicts(files []string, cursor int, keys keyMap) string {
+	dialogStyle := lipgloss.NewStyle().
+		Border(lipgloss.RoundedBorder()).
+		BorderForeground(styles.ColorError).
+		Padding(2, 4).
+		Width(60)
+
+	titleStyle := lipgloss.NewStyle().
+		Foreground(styles.ColorError).
+		Bold(true).
+		Align(lipgloss.Center).
+		Width(52)
+
+	messageStyle := lipgloss.NewStyle().
+		Foreground(styles.ColorMuted).
+		Align(lipgloss.Center).
+		Width(52).
+		MarginTop(1)
+
+	var rows []string
+	for i, file := range files {
+		if i == cursor {
+			rows = append(rows, lipgloss.NewStyle().Foreground(styles.ColorPrimary).Bold(true).Render("▸ "+filepath.ToSlash(file)))
+		} else {
+			rows = append(rows, lipgloss.NewStyle().Foreground(styles.ColorText).Render("  "+filepath.ToSlash(file)))
+		}
+	}
+
+	title := titleStyle.Render("⚠️  MERGE CONFLICTS")
+	message := messageStyle.Render("The pull changed these notes in ways that clash with\nyour commits. Open one to resolve its conflict markers.")
+
+	button := func(label string, act action, color lipgloss.Color) string {
+		return lipgloss.NewStyle().
+			Foreground(styles.ColorBg).
+			Background(color).
+			Bold(true).
+			Render(fmt.Sprintf(" %s (%s) ", label, keys.label(contextGitConflict, act)))
+	}
+	buttons := lipgloss.NewStyle().
+		Align(lipgloss.Center).
+		Width(52).
+		MarginTop(2).
+		Render(strings.Join([]string{
+			button("Open", actionOpen, styles.ColorPrimary),
+			button("Abort merge", actionAbortMerge, styles.ColorError),
+			button("Later", actionCancel, styles.ColorMuted),
+		}, " "))
+
+	content := lipgloss.JoinVertical(
+		lipgloss.Left,
+		title,
+		message,
+		"",
+		strings.Join(rows, "\n"),
+		buttons,
+	)
+	return dialogStyle.Render(content)
+}
+
+// renderPlainGitLogView renders the git log of a note in plain mode
+func renderPlainGitLogView(filename string, commits []notes.Commit, cursor int, patch string, scroll, height int, keys keyMap, statusMessage, statusType string) string {
+	out := []string{fmt.Sprintf("Git log of %s (%d commits)", filepath.ToSlash(filename), len(commits)), ""}
+
+	first := max(0, min(cursor-historyRows/2, len(commits)-historyRows))
+	for i := first; i < len(commits) && i < first+historyRows; i++ {
+		marker := "  "
+		if i == cursor {
+			marker = "> "
+		}
+		out = append(out, marker+commitLabel(commits[i]))
+	}
+	out = append(out, "")
+	out = append(out, plainDiffLines(patch, scroll, historyPage(height), "No changes to the note's content")...)
+
+	out = append(out, "", fmt.Sprintf("%s: Newer, %s: Older, %s: Scroll changes, %s: Close",
+		plainLabel(keys, contextGitLog, actionUp),
+		plainLabel(keys, contextGitLog, actionDown),
+		plainLabel(keys, contextGitLog, actionPageDown),
+		plainLabel(keys, contextGitLog, actionCancel)))
+	if statusMessage != "" {
+		out = append(out, plainStatus(statusMessage, statusType))
+	}
+	return strings.Join(out, "\n")
+}
+
+// renderPlainGitConflicts renders the merge conflicts dialog in plain mode
+func renderPlainGitConflicts(files []string, cursor int, keys keyMap) string {
+	out := []string{
+		"Merge conflicts",
+		"",
+		"The pull changed these notes in ways that clash with your commits:",
+	}
+	for i, file := range files {
+		marker := "  "
+		if i == cursor {
+			marker = "> "
+		}
+		out = append(out, marker+filepath.ToSlash(file))
+	}
+	out = append(out, "", fmt.Sprintf("Press %s to open the note and resolve its conflict markers, %s to abort the merge or %s to deal with it later.",
+		plainLabel(keys, contextGitConflict, actionOpen),
+		plainLabel(keys, contextGitConflict, actionAbortMerge),
+		plainLabel(keys, contextGitConflict, actionCancel)))
+	return strings.Join(out, "\n")
+}
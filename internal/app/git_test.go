@@ -0,0 +1,162 @@
+package app
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+
+	"github.com/shalshcode08/Term-Note/internal/notes"
+)
+
+// newGitModel creates a model for a vault in a new git repository holding
+// one committed note, plan.md
+func newGitModel(t *testing.T) Model {
+	t.Helper()
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git is not installed")
+	}
+	m, _ := newTestModel(t, nil)
+	for _, name := range []string{"GIT_AUTHOR_NAME", "GIT_COMMITTER_NAME"} {
+		t.Setenv(name, "Test")
+	}
+	for _, name := range []string{"GIT_AUTHOR_EMAIL", "GIT_COMMITTER_EMAIL"} {
+		t.Setenv(name, "test@example.com")
+	}
+
+	dir := m.cfg.VaultDir
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "plan.md"), []byte("v1"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	runGit(t, dir, "init", "-q")
+	runGit(t, dir, "add", "plan.md")
+	runGit(t, dir, "commit", "-q", "-m", "Add plan.md")
+
+	m.vault = notes.DirVault(dir)
+	m.gitRepo = true
+	m.cfg.Git.AutoCommit = true
+	return m
+}
+
+// runGit runs git in dir and returns its output
+func runGit(t *testing.T, dir string, args ...string) string {
+	t.Helper()
+	cmd := exec.Command("git", args...)
+	cmd.Dir = dir
+	out, err := cmd.CombinedOutput()
+	if err != nil {
+		t.Fatalf("git %s: %v\n%s", strings.Join(args, " "), err, out)
+	}
+	return string(out)
+}
+
+func TestCommitsRunInBackground(t *testing.T) {
+	m := newGitModel(t)
+	dir := m.cfg.VaultDir
+	commitCount := func() int {
+		return strings.Count(runGit(t, dir, "log", "--oneline"), "\n")
+	}
+
+	if err := m.OpenNote("plan.md"); err != nil {
+		t.Fatal(err)
+	}
+
+	// Autosaves are committed once the note is left
+	m.textArea.SetValue("v2")
+	m.autosave()
+	m.textArea.SetValue("v3")
+	m.autosave()
+	if len(m.commits) != 0 {
+		t.Fatalf("autosaves queued %d commits", len(m.commits))
+	}
+
+	// Saving queues a commit without running git
+	m.textArea.SetValue("v4")
+	if err := m.saveNote(); err != nil {
+		t.Fatal(err)
+	}
+	if len(m.commits) != 1 || commitCount() != 1 {
+		t.Fatalf("after saving: %d queued, %d made, want 1 queued and none made", len(m.commits), commitCount())
+	}
+
+	// A sync asked for meanwhile waits for the commits
+	m.syncVault()
+	cmd := m.startGit()
+	if cmd == nil || m.startGit() != nil {
+		t.Fatal("startGit did not start exactly one job")
+	}
+	msg, ok := cmd().(gitCommitMsg)
+	if !ok || msg.err != nil {
+		t.Fatalf("first job = %#v, want successful commits", msg)
+	}
+	if commitCount() != 2 {
+		t.Fatalf("%d commits made, want 2", commitCount())
+	}
+
+	next, _ := m.update(msg)
+	m = next.(Model)
+	cmd = m.startGit()
+	if cmd == nil {
+		t.Fatal("sync did not start after the commits")
+	}
+	if _, ok := cmd().(gitSyncMsg); !ok {
+		t.Fatal("second job is not the sync")
+	}
+}
+
+// isQuit reports whether cmd quits the program
+func isQuit(cmd tea.Cmd) bool {
+	if cmd == nil {
+		return false
+	}
+	_, ok := cmd().(tea.QuitMsg)
+	return ok
+}
+
+func TestSignalWaitsForCommits(t *testing.T) {
+	// Nothing to commit: stop right away
+	m, _ := newTestModel(t, nil)
+	if _, cmd := m.update(signalMsg{sig: os.Interrupt}); !isQuit(cmd) {
+		t.Fatal("signal without queued commits did not quit")
+	}
+
+	m = newGitModel(t)
+	if err := m.OpenNote("plan.md"); err != nil {
+		t.Fatal(err)
+	}
+	m.textArea.SetValue("v2")
+	if err := m.saveNote(); err != nil {
+		t.Fatal(err)
+	}
+
+	next, cmd := m.update(signalMsg{sig: os.Interrupt})
+	m = next.(Model)
+	if !m.quitting || cmd == nil {
+		t.Fatalf("signal with a queued commit: quitting = %v, cmd = %v", m.quitting, cmd)
+	}
+
+	// The commit is made, then the program stops
+	git := m.startGit()
+	if git == nil {
+		t.Fatal("queued commit was not started")
+	}
+	next, cmd = m.update(git())
+	m = next.(Model)
+	if !isQuit(cmd) {
+		t.Fatal("did not quit once the commit was made")
+	}
+	if log := runGit(t, m.cfg.VaultDir, "log", "--oneline"); strings.Count(log, "\n") != 2 {
+		t.Errorf("git log after the signal:\n%s", log)
+	}
+
+	// A commit that hangs does not keep the program alive
+	if _, cmd := m.update(gitQuitTimeoutMsg{}); !isQuit(cmd) {
+		t.Error("timeout did not quit")
+	}
+}
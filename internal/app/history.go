@@ -0,0 +1,274 @@
+package app
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/charmbracelet/lipgloss"
+
+	"github.com/shalshcode08/Term-Note/internal/notes"
+	"github.com/shalshcode08/Term-Note/internal/ui/styles"
+)
+
+// errNoSnapshot is returned when a note was saved but its history snapshot
+// could not be written
+var errNoSnapshot = errors.New("no history snapshot was written")
+
+// historyRows is the number of snapshots listed at once in the history panel
+const historyRows = 6
+
+// savedVersion is a version of the open note as it was on disk
+type savedVersion struct {
+	content string
+	modTime time.Time // Zero if the note was not on disk yet
+}
+
+// snapshot adds the saved content to the note's history. The version it
+// replaced is kept as well if the note has no history yet. Autosaves made
+// since the last snapshot count as one change from the version before them.
+func (m *Model) snapshot(previous savedVersion, content string) error {
+	if m.autosavedFrom != nil {
+		previous = *m.autosavedFrom
+		m.autosavedFrom = nil
+	}
+	if m.noteKey != nil {
+		// Snapshots are plain text, so encrypted notes have no history
+		return nil
+	}
+	dir := m.cfg.HistoryDir()
+	now := time.Now()
+
+	if previous.content != "" && !previous.modTime.IsZero() {
+		existing, err := notes.ListSnapshots(dir, m.currentFile)
+		if err != nil {
+			return err
+		}
+		if len(existing) == 0 {
+			if err := notes.SaveSnapshot(dir, m.currentFile, previous.content, previous.modTime); err != nil {
+				return err
+			}
+		}
+	}
+
+	if err := notes.SaveSnapshot(dir, m.currentFile, content, m.lastSaved); err != nil {
+		return err
+	}
+	return notes.PruneSnapshots(dir, m.currentFile, m.cfg.History.Keep, m.cfg.History.MaxAge, now)
+}
+
+// recordAutosaves adds the version left by autosaves to the history of the
+// open note and commits it. It runs when leaving the note, so the history
+// and git log get one version per visit rather than one per autosave.
+func (m *Model) recordAutosaves() {
+	if m.autosavedFrom == nil {
+		return
+	}
+	m.commitNotes("Update "+filepath.ToSlash(m.currentFile), m.currentFile)
+	if err := m.snapshot(*m.autosavedFrom, m.savedContent); err != nil {
+		m.statusMessage = fmt.Sprintf("%v for %s: %v", errNoSnapshot, m.currentFile, err)
+		m.statusType = "warning"
+	}
+}
+
+// openHistory shows the history panel for the open note
+func (m *Model) openHistory() {
+	if m.noteKey != nil {
+		m.statusMessage = "History is not kept for encrypted notes"
+		m.statusType = "warning"
+		return
+	}
+	snapshots, err := notes.ListSnapshots(m.cfg.HistoryDir(), m.currentFile)
+	if err != nil {
+		m.statusMessage = err.Error()
+		m.statusType = "error"
+		return
+	}
+	if len(snapshots) == 0 {
+		m.statusMessage = "No history for " + m.currentFile + " yet, it is kept from the first save"
+		m.statusType = "warning"
+		return
+	}
+
+	m.snapshots = snapshots
+	m.showHistory = true
+	m.statusMessage = ""
+	m.statusType = ""
+	m.selectSnapshot(0)
+}
+
+// selectSnapshot highlights a snapshot and diffs it against the editor content
+func (m *Model) selectSnapshot(i int) {
+	i = max(0, min(i, len(m.snapshots)-1))
+	m.historyCursor = i
+	m.historyScroll = 0
+
+	content, err := os.ReadFile(m.snapshots[i].Path)
+	if err != nil {
+		m.historyContent = ""
+		m.historyDiff = ""
+		m.statusMessage = fmt.Sprintf("Cannot read snapshot: %v", err)
+		m.statusType = "error"
+		return
+	}
+	m.historyContent = string(content)
+	m.historyDiff = notes.UnifiedDiff(
+		notes.Diff(m.historyContent, m.textArea.Value()),
+		m.currentFile+" ("+notes.FormatRelativeTime(m.snapshots[i].Time)+")",
+		m.currentFile+" (editor)",
+		3,
+	)
+}
+
+// scrollHistory moves the diff in the history panel by a number of lines
+func (m *Model) scrollHistory(lines int) {
+	total := strings.Count(m.historyDiff, "\n")
+	m.historyScroll = max(0, min(m.historyScroll+lines, total-1))
+}
+
+// restoreSnapshot loads the highlighted snapshot into the editor as unsaved edits
+func (m *Model) restoreSnapshot() {
+	if m.lockedOut() {
+		m.statusMessage = (&notes.LockedError{Holder: m.lockHolder}).Error() + ", cannot restore"
+		m.statusType = "error"
+		return
+	}
+
+	m.showHistory = false
+	m.textArea.SetValue(m.historyContent)
+	m.statusMessage = fmt.Sprintf("Restored the version from %s, save to keep it",
+		notes.FormatRelativeTime(m.snapshots[m.historyCursor].Time))
+	m.statusType = "success"
+}
+
+// historyPage returns the number of diff lines that fit in the history panel
+func historyPage(height int) int {
+	// Header, list, separator and status bar
+	return max(3, height-historyRows-6)
+}
+
+// renderHistoryView renders the snapshots of a note and the diff of the
+// highlighted one against the editor content
+func renderHistoryView(filename string, snapshots []notes.Snapshot, cursor int, diff string, scroll, width, height int, keys keyMap, statusMessage, statusType string) string {
+	header := lipgloss.NewStyle().
+		Foreground(styles.ColorPrimary).
+		Bold(true).
+		Render(fmt.Sprintf("🕘 History · %s (%d versions)", filename, len(snapshots)))
+
+	// Snapshot list, scrolled to keep the cursor visible
+	first := max(0, min(cursor-historyRows/2, len(snapshots)-historyRows))
+	var rows []string
+	for i := first; i < len(snapshots) && i < first+historyRows; i++ {
+		label := fmt.Sprintf("%-16s %s", notes.FormatRelativeTime(snapshots[i].Time),
+			snapshots[i].Time.Local().Format("2006-01-02 15:04:05"))
+		if i == cursor {
+			rows = append(rows, lipgloss.NewStyle().Foreground(styles.ColorPrimary).Bold(true).Render("▸ "+label))
+		} else {
+			rows = append(rows, lipgloss.NewStyle().Foreground(styles.ColorMuted).Render("  "+label))
+		}
+	}
+
+	separator := lipgloss.NewStyle().Foreground(styles.ColorMuted).Render(strings.Repeat("─", max(1, width)))
+
+	key := func(act action, desc string) string {
+		return lipgloss.NewStyle().Foreground(styles.ColorText).Render(keys.label(contextHistory, act)) +
+			lipgloss.NewStyle().Foreground(styles.ColorMuted).Render(" "+desc)
+	}
+	statusBar := strings.Join([]string{
+		key(actionUp, "Newer"),
+		key(actionDown, "Older"),
+		key(actionPageDown, "Scroll diff"),
+		key(actionRestore, "Restore"),
+		key(actionCancel, "Close"),
+	}, lipgloss.NewStyle().Foreground(styles.ColorMuted).Render("  •  "))
+	if statusMessage != "" {
+		statusBar += "  •  " + statusStyle(statusType).Render(statusMessage)
+	}
+
+	return lipgloss.JoinVertical(
+		lipgloss.Left,
+		header,
+		"",
+		strings.Join(rows, "\n"),
+		separator,
+		strings.Join(renderDiffLines(diff, scroll, historyPage(height), "Same as the editor content"), "\n"),
+		"",
+		statusBar,
+	)
+}
+
+// renderDiffLines renders a page of a unified diff starting at line scroll,
+// colored by line type, or empty when the diff has no changes
+func renderDiffLines(diff string, scroll, page int, empty string) []string {
+	lines := strings.Split(strings.TrimSuffix(diff, "\n"), "\n")
+	if diff == "" || len(lines) <= 2 {
+		return []string{lipgloss.NewStyle().Foreground(styles.ColorMuted).Render(empty)}
+	}
+
+	var out []string
+	end := min(scroll+page, len(lines))
+	for _, line := range lines[scroll:end] {
+		style := lipgloss.NewStyle().Foreground(styles.ColorText)
+		switch {
+		case strings.HasPrefix(line, "+++"), strings.HasPrefix(line, "---"):
+			style = style.Bold(true)
+		case strings.HasPrefix(line, "@@"):
+			style = lipgloss.NewStyle().Foreground(styles.ColorAccent)
+		case strings.HasPrefix(line, "+"):
+			style = lipgloss.NewStyle().Foreground(styles.ColorSuccess)
+		case strings.HasPrefix(line, "-"):
+			style = lipgloss.NewStyle().Foreground(styles.ColorError)
+		}
+		out = append(out, style.Render(line))
+	}
+	if end < len(lines) {
+		out = append(out, lipgloss.NewStyle().Foreground(styles.ColorMuted).Render(fmt.Sprintf("... %d more lines", len(lines)-end)))
+	}
+	return out
+}
+
+// plainDiffLines returns a page of a unified diff in plain mode
+func plainDiffLines(diff string, scroll, page int, empty string) []string {
+	lines := strings.Split(strings.TrimSuffix(diff, "\n"), "\n")
+	if diff == "" || len(lines) <= 2 {
+		return []string{empty}
+	}
+	end := min(scroll+page, len(lines))
+	out := append([]string(nil), lines[scroll:end]...)
+	if end < len(lines) {
+		out = append(out, fmt.Sprintf("... %d more lines", len(lines)-end))
+	}
+	return out
+}
+
+// renderPlainHistoryView renders the history panel in plain mode
+func renderPlainHistoryView(filename string, snapshots []notes.Snapshot, cursor int, diff string, scroll, height int, keys keyMap, statusMessage, statusType string) string {
+	out := []string{fmt.Sprintf("History of %s (%d versions)", filename, len(snapshots)), ""}
+
+	first := max(0, min(cursor-historyRows/2, len(snapshots)-historyRows))
+	for i := first; i < len(snapshots) && i < first+historyRows; i++ {
+		marker := "  "
+		if i == cursor {
+			marker = "> "
+		}
+		out = append(out, fmt.Sprintf("%s%-16s %s", marker, notes.FormatRelativeTime(snapshots[i].Time),
+			snapshots[i].Time.Local().Format("2006-01-02 15:04:05")))
+	}
+	out = append(out, "")
+
+	out = append(out, plainDiffLines(diff, scroll, historyPage(height), "Same as the editor content")...)
+
+	out = append(out, "", fmt.Sprintf("%s: Newer, %s: Older, %s: Scroll diff, %s: Restore, %s: Close",
+		plainLabel(keys, contextHistory, actionUp),
+		plainLabel(keys, contextHistory, actionDown),
+		plainLabel(keys, contextHistory, actionPageDown),
+		plainLabel(keys, contextHistory, actionRestore),
+		plainLabel(keys, contextHistory, actionCancel)))
+	if statusMessage != "" {
+		out = append(out, plainStatus(statusMessage, statusType))
+	}
+	return strings.Join(out, "\n")
+}
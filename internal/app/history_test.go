@@ -0,0 +1,51 @@
+package app
+
+import (
+	"testing"
+
+	"github.com/shalshcode08/Term-Note/internal/notes"
+)
+
+func TestAutosaveSnapshotsOnLeave(t *testing.T) {
+	m, _ := newTestModel(t, map[string]string{"plan.md": "one"})
+	if err := m.OpenNote("plan.md"); err != nil {
+		t.Fatal(err)
+	}
+
+	versions := func() int {
+		t.Helper()
+		snapshots, err := notes.ListSnapshots(m.cfg.HistoryDir(), "plan.md")
+		if err != nil {
+			t.Fatal(err)
+		}
+		return len(snapshots)
+	}
+
+	for _, text := range []string{"two", "three"} {
+		m.textArea.SetValue(text)
+		m.autosave()
+		if m.dirty() {
+			t.Fatalf("autosave left %q unsaved", text)
+		}
+	}
+	if n := versions(); n != 0 {
+		t.Fatalf("autosaves wrote %d snapshots, want none until the note is left", n)
+	}
+
+	m.closeNote()
+	if n := versions(); n != 2 {
+		t.Fatalf("after closing: %d snapshots, want the original and the autosaved version", n)
+	}
+
+	// An explicit save snapshots right away
+	if err := m.OpenNote("plan.md"); err != nil {
+		t.Fatal(err)
+	}
+	m.textArea.SetValue("four")
+	if err := m.saveNote(); err != nil {
+		t.Fatal(err)
+	}
+	if n := versions(); n != 3 {
+		t.Fatalf("after saving: %d snapshots, want 3", n)
+	}
+}
@@ -0,0 +1,185 @@
+package app
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"github.com/charmbracelet/lipgloss"
+
+	"github.com/shalshcode08/Term-Note/internal/config"
+	"github.com/shalshcode08/Term-Note/internal/notes"
+	"github.com/shalshcode08/Term-Note/internal/ui/styles"
+)
+
+// importListed is how many existing and skipped files the import dialog
+// names before summing up the rest
+const importListed = 5
+
+// openImport reads the notes of a source and shows what importing them into
+// the folder being browsed would do
+func (m *Model) openImport(source string) {
+	source = config.ExpandHome(strings.TrimSpace(source))
+	if source == "" {
+		m.statusMessage = "Usage: import <path>"
+		m.statusType = "error"
+		return
+	}
+
+	plan, err := notes.ReadImport(source, "", m.cfg.DefaultExtension)
+	if err != nil {
+		m.statusMessage = err.Error()
+		m.statusType = "error"
+		return
+	}
+	if len(plan.Files) == 0 {
+		m.statusMessage = "Nothing to import from " + source
+		m.statusType = "warning"
+		return
+	}
+	report, err := notes.Import(m.vault, plan, m.folder, true)
+	if err != nil {
+		m.statusMessage = err.Error()
+		m.statusType = "error"
+		return
+	}
+
+	m.importPlan = plan
+	m.importReport = report
+	m.showImport = true
+}
+
+// closeImport hides the import dialog
+func (m *Model) closeImport() {
+	m.showImport = false
+	m.importPlan = nil
+	m.importReport = notes.ImportReport{}
+}
+
+// importNotes imports the notes shown in the import dialog. Files that
+// already exist are left alone, as when creating a note.
+func (m *Model) importNotes() {
+	plan := m.importPlan
+	m.closeImport()
+
+	report, err := notes.Import(m.vault, plan, m.folder, false)
+	m.commitNotes("Import "+filepath.Base(plan.Source), report.Created...)
+	m.refreshList()
+	if err != nil {
+		m.statusMessage = err.Error()
+		m.statusType = "error"
+		return
+	}
+
+	m.statusMessage = report.Summary()
+	m.statusType = "success"
+	if len(report.Existing) > 0 {
+		m.statusMessage += " (File already exists with this name: " + filepath.ToSlash(report.Existing[0]) + ")"
+		m.statusType = "warning"
+	}
+}
+
+// importDetails returns the lines naming the files an import leaves out
+func importDetails(report notes.ImportReport) []string {
+	var lines []string
+	for i, name := range report.Existing {
+		if i == importListed {
+			lines = append(lines, fmt.Sprintf("…and %d more that already exist", len(report.Existing)-i))
+			break
+		}
+		lines = append(lines, "exists: "+filepath.ToSlash(name))
+	}
+	for i, source := range report.Skipped {
+		if i == importListed {
+			lines = append(lines, fmt.Sprintf("…and %d more skipped", len(report.Skipped)-i))
+			break
+		}
+		lines = append(lines, "skipped: "+source)
+	}
+	return lines
+}
+
+// importMessage sums up the dry run of an import into a folder
+func importMessage(report notes.ImportReport, folder string) string {
+	target := "the vault"
+	if folder != "" {
+		target = filepath.ToSlash(folder) + "/"
+	}
+	return fmt.Sprintf("%s.\nFiles go into %s; existing files are never overwritten.", report.Summary(), target)
+}
+
+// renderImportDialog renders the dry run report shown before importing
+func renderImportDialog(plan *notes.ImportPlan, report notes.ImportReport, folder string, keys keyMap) string {
+	dialogStyle := lipgloss.NewStyle().
+		Border(lipgloss.RoundedBorder()).
+		BorderForeground(styles.ColorPrimary).
+		Padding(1, 4).
+		Width(70)
+
+	titleStyle := lipgloss.NewStyle().
+		Foreground(styles.ColorPrimary).
+		Bold(true).
+		Align(lipgloss.Center).
+		Width(62)
+
+	sourceStyle := lipgloss.NewStyle().
+		Foreground(styles.ColorSecondary).
+		Bold(true).
+		Align(lipgloss.Center).
+		Width(62)
+
+	messageStyle := lipgloss.NewStyle().
+		Foreground(styles.ColorText).
+		Align(lipgloss.Center).
+		Width(62).
+		MarginTop(1)
+
+	detailStyle := lipgloss.NewStyle().
+		Foreground(styles.ColorMuted).
+		Width(62).
+		MarginTop(1)
+
+	buttonsStyle := lipgloss.NewStyle().
+		Align(lipgloss.Center).
+		Width(62).
+		MarginTop(1)
+
+	title := titleStyle.Render("📥 IMPORT NOTES")
+	source := sourceStyle.Render(fmt.Sprintf("%s (%s)", plan.Source, plan.Format))
+	message := messageStyle.Render(importMessage(report, folder))
+
+	parts := []string{title, "", source, message}
+	if details := importDetails(report); len(details) > 0 {
+		parts = append(parts, detailStyle.Render(strings.Join(details, "\n")))
+	}
+
+	button := func(label string, act action, color lipgloss.Color) string {
+		return lipgloss.NewStyle().
+			Foreground(styles.ColorBg).
+			Background(color).
+			Bold(true).
+			Render(fmt.Sprintf(" %s (%s) ", label, keys.label(contextImport, act)))
+	}
+	parts = append(parts, buttonsStyle.Render(strings.Join([]string{
+		button("Import", actionConfirm, styles.ColorSuccess),
+		button("Cancel", actionCancel, styles.ColorMuted),
+	}, " ")))
+
+	return dialogStyle.Render(lipgloss.JoinVertical(lipgloss.Left, parts...))
+}
+
+// renderPlainImportDialog renders the import dialog in plain mode
+func renderPlainImportDialog(plan *notes.ImportPlan, report notes.ImportReport, folder string, keys keyMap) string {
+	lines := []string{
+		fmt.Sprintf("Import notes: %s (%s)", plan.Source, plan.Format),
+		"",
+		importMessage(report, folder),
+	}
+	if details := importDetails(report); len(details) > 0 {
+		lines = append(lines, "")
+		lines = append(lines, details...)
+	}
+	lines = append(lines, "", fmt.Sprintf("Press %s to import or %s to cancel.",
+		plainLabel(keys, contextImport, actionConfirm), plainLabel(keys, contextImport, actionCancel)))
+	return strings.Join(lines, "\n")
+}
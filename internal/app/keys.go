@@ -0,0 +1,565 @@
+package app
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/charmbracelet/bubbles/key"
+	"github.com/charmbracelet/bubbles/list"
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// context identifies the part of the UI that currently receives key presses
+type context int
+
+const (
+	contextLanding     context = iota // Landing page
+	contextList                       // Note list
+	contextFilter                     // Note list while typing a filter
+	contextCreate                     // Create note dialog
+	contextDelete                     // Delete confirmation dialog
+	contextVault                      // Vault switcher dialog
+	contextEditor                     // Note editor
+	contextHelp                       // Help overlay on top of the editor
+	contextCommand                    // : command prompt
+	contextUnsaved                    // Save / Discard / Cancel dialog for unsaved changes
+	contextConflict                   // Dialog for a note changed on disk
+	contextRecover                    // Dialog for a swap file found when opening a note
+	contextLocked                     // Dialog for a note open in another instance
+	contextHistory                    // History panel of the open note
+	contextTrash                      // Trash view
+	contextPurge                      // Confirmation for deleting a note from the trash
+	contextMove                       // Dialog for moving a note to another folder
+	contextRename                     // Dialog for renaming a note
+	contextGitLog                     // Git log of the open note
+	contextGitConflict                // Dialog for merge conflicts left by a sync
+	contextPassphrase                 // Passphrase prompt for encrypted notes
+	contextImport                     // Dry run report shown before importing notes
+	contextCapture                    // Quick capture screen of "termnote capture"
+)
+
+// action names an operation a key can be bound to
+type action string
+
+const (
+	actionNone          action = "" // Documented only; the focused component handles the key
+	actionQuit          action = "quit"
+	actionNewNote       action = "new_note"
+	actionListNotes     action = "list_notes"
+	actionSwitchVault   action = "switch_vault"
+	actionBack          action = "back"
+	actionOpen          action = "open"
+	actionDelete        action = "delete"
+	actionConfirm       action = "confirm"
+	actionCancel        action = "cancel"
+	actionUp            action = "up"
+	actionDown          action = "down"
+	actionCreate        action = "create"
+	actionSave          action = "save"
+	actionClose         action = "close"
+	actionHelp          action = "help"
+	actionBullet        action = "bullet"
+	actionTodo          action = "todo"
+	actionToggleTodo    action = "toggle_todo"
+	actionHeading1      action = "heading1"
+	actionHeading2      action = "heading2"
+	actionHeading3      action = "heading3"
+	actionTable         action = "table"
+	actionCodeBlock     action = "code_block"
+	actionLink          action = "link"
+	actionImage         action = "image"
+	actionRule          action = "horizontal_rule"
+	actionContinueList  action = "continue_list"
+	actionSelectVault   action = "select_vault"
+	actionCloseSwitcher action = "close_switcher"
+	actionDailyNote     action = "daily_note"
+	actionLeader        action = "leader"
+	actionCommand       action = "command"
+	actionRunCommand    action = "run_command"
+	actionDiscard       action = "discard"
+	actionReload        action = "reload"
+	actionOverwrite     action = "overwrite"
+	actionMerge         action = "merge"
+	actionRecover       action = "recover"
+	actionShowDiff      action = "show_diff"
+	actionReadOnly      action = "read_only"
+	actionStealLock     action = "steal_lock"
+	actionHistory       action = "history"
+	actionRestore       action = "restore"
+	actionPageUp        action = "page_up"
+	actionPageDown      action = "page_down"
+	actionTrash         action = "trash"
+	actionUndo          action = "undo"
+	actionPurge         action = "purge"
+	actionParent        action = "parent"
+	actionMove          action = "move"
+	actionRename        action = "rename"
+	actionGitLog        action = "git_log"
+	actionSync          action = "sync"
+	actionAbortMerge    action = "abort_merge"
+)
+
+// contextNames are the names used for contexts in the [keys] config section
+var contextNames = map[context]string{
+	contextLanding:     "landing",
+	contextList:        "list",
+	contextFilter:      "filter",
+	contextCreate:      "create",
+	contextDelete:      "delete",
+	contextVault:       "vault",
+	contextEditor:      "editor",
+	contextHelp:        "help",
+	contextCommand:     "command",
+	contextUnsaved:     "unsaved",
+	contextConflict:    "conflict",
+	contextRecover:     "recover",
+	contextLocked:      "locked",
+	contextHistory:     "history",
+	contextTrash:       "trash",
+	contextPurge:       "purge",
+	contextMove:        "move",
+	contextRename:      "rename",
+	contextGitLog:      "git_log",
+	contextGitConflict: "git_conflict",
+	contextPassphrase:  "passphrase",
+	contextImport:      "import",
+	contextCapture:     "capture",
+}
+
+// binding ties one or more keys to an action within a context
+type binding struct {
+	key.Binding
+	action action
+	group  string // Section heading in the help overlay
+}
+
+// newBinding creates a binding whose help label is derived from its keys
+func newBinding(act action, desc, group string, keys ...string) binding {
+	keys = append([]string(nil), keys...)
+	for i, k := range keys {
+		if alias, ok := keyAliases[k]; ok {
+			keys[i] = alias
+		}
+	}
+
+	b := binding{
+		Binding: key.NewBinding(key.WithKeys(keys...), key.WithHelp(helpKey(act, keys), desc)),
+		action:  act,
+		group:   group,
+	}
+	if len(keys) == 0 {
+		b.SetEnabled(false)
+	}
+	return b
+}
+
+// keyMap holds the active bindings of every context, plus the leader key
+// and the chords that may follow it
+type keyMap struct {
+	contexts map[context][]binding
+	leader   key.Binding
+	chords   map[context][]chord
+}
+
+// defaultBindings is the registry of every binding, per context. A key only
+// has an effect in the contexts it is registered for; anything else goes to
+// the focused component (textarea, text input or list).
+var defaultBindings = map[context][]binding{
+	contextLanding: {
+		newBinding(actionNewNote, "Create a new note", "", "ctrl+n"),
+		newBinding(actionListNotes, "List all notes", "", "ctrl+l"),
+		newBinding(actionSwitchVault, "Switch vault", "", "ctrl+o"),
+		newBinding(actionCommand, "Command (e.g. theme light)", "", ":"),
+		newBinding(actionQuit, "Quit application", "", "q", "ctrl+c"),
+	},
+	contextList: {
+		newBinding(actionNone, "navigate", "", "up", "down"),
+		newBinding(actionNone, "filter", "", "/"),
+		newBinding(actionOpen, "open", "", "enter"),
+		newBinding(actionParent, "up a folder", "", "backspace"),
+		newBinding(actionRename, "rename", "", "r"),
+		newBinding(actionMove, "move", "", "m"),
+		newBinding(actionDelete, "delete", "", "d", "delete"),
+		newBinding(actionUndo, "undo delete", "", "u"),
+		newBinding(actionTrash, "trash", "", "t"),
+		newBinding(actionSync, "sync", "", "s"),
+		newBinding(actionNewNote, "new", "", "ctrl+n"),
+		newBinding(actionListNotes, "refresh", "", "ctrl+l"),
+		newBinding(actionSwitchVault, "vault", "", "ctrl+o"),
+		newBinding(actionCommand, "command", "", ":"),
+		newBinding(actionBack, "back", "", "esc"),
+		newBinding(actionQuit, "quit", "", "q", "ctrl+c"),
+	},
+	contextFilter: {
+		newBinding(actionNone, "apply filter", "", "enter"),
+		newBinding(actionNone, "cancel filter", "", "esc"),
+		newBinding(actionQuit, "quit", "", "ctrl+c"),
+	},
+	contextCreate: {
+		newBinding(actionCreate, "create", "", "enter"),
+		newBinding(actionCancel, "cancel", "", "esc"),
+		newBinding(actionQuit, "quit", "", "ctrl+c"),
+	},
+	contextDelete: {
+		newBinding(actionConfirm, "delete", "", "y"),
+		newBinding(actionCancel, "keep", "", "n", "esc"),
+		newBinding(actionQuit, "quit", "", "ctrl+c"),
+	},
+	contextVault: {
+		newBinding(actionUp, "previous", "", "up", "k"),
+		newBinding(actionDown, "next", "", "down", "j"),
+		newBinding(actionSelectVault, "open", "", "enter"),
+		newBinding(actionCloseSwitcher, "cancel", "", "esc", "ctrl+o"),
+		newBinding(actionQuit, "quit", "", "ctrl+c"),
+	},
+	contextEditor: {
+		newBinding(actionSave, "Save note", "Basic Commands:", "ctrl+s"),
+		newBinding(actionHelp, "Toggle this help", "Basic Commands:", "ctrl+h"),
+		newBinding(actionClose, "Close note", "Basic Commands:", "esc"),
+		newBinding(actionQuit, "Quit application", "Basic Commands:", "ctrl+c"),
+		newBinding(actionBullet, "Insert bullet point (- )", "Markdown Formatting:", "ctrl+b"),
+		newBinding(actionTodo, "Insert todo checkbox (- [ ] )", "Markdown Formatting:", "ctrl+t"),
+		newBinding(actionToggleTodo, "Toggle todo (check/uncheck)", "Markdown Formatting:", "ctrl+d"),
+		newBinding(actionHeading1, "Insert H1 header (# )", "Markdown Formatting:", "alt+1", "ctrl+1"),
+		newBinding(actionHeading2, "Insert H2 header (## )", "Markdown Formatting:", "alt+2", "ctrl+2"),
+		newBinding(actionHeading3, "Insert H3 header (### )", "Markdown Formatting:", "alt+3", "ctrl+3"),
+		newBinding(actionTable, "Insert table", "Advanced Features:", "alt+t"),
+		newBinding(actionCodeBlock, "Insert code block", "Advanced Features:", "alt+c"),
+		newBinding(actionLink, "Insert link template", "Advanced Features:", "alt+l"),
+		newBinding(actionImage, "Insert image template", "Advanced Features:", "alt+i"),
+		newBinding(actionRule, "Insert horizontal rule", "Advanced Features:", "alt+r"),
+		newBinding(actionHistory, "Browse note history", "Advanced Features:", "alt+v"),
+		newBinding(actionGitLog, "Git log of this note", "Advanced Features:", "alt+g"),
+		newBinding(actionContinueList, "New line, continuing lists", "Advanced Features:", "enter"),
+	},
+	contextCommand: {
+		newBinding(actionNone, "complete", "", "tab"),
+		newBinding(actionRunCommand, "run", "", "enter"),
+		newBinding(actionCancel, "cancel", "", "esc"),
+		newBinding(actionQuit, "quit", "", "ctrl+c"),
+	},
+	contextUnsaved: {
+		newBinding(actionSave, "save", "", "s", "y"),
+		newBinding(actionDiscard, "discard", "", "d", "n"),
+		newBinding(actionCancel, "cancel", "", "esc", "c"),
+	},
+	contextConflict: {
+		newBinding(actionReload, "reload", "", "r"),
+		newBinding(actionOverwrite, "overwrite", "", "o"),
+		newBinding(actionMerge, "merge", "", "m"),
+		newBinding(actionCancel, "cancel", "", "esc", "c"),
+	},
+	contextRecover: {
+		newBinding(actionRecover, "recover", "", "r"),
+		newBinding(actionShowDiff, "diff", "", "v"),
+		newBinding(actionDiscard, "delete swap", "", "d"),
+		newBinding(actionCancel, "ignore", "", "esc"),
+	},
+	contextLocked: {
+		newBinding(actionReadOnly, "read-only", "", "r"),
+		newBinding(actionStealLock, "steal lock", "", "s"),
+		newBinding(actionCancel, "cancel", "", "esc", "c"),
+	},
+	contextHistory: {
+		newBinding(actionUp, "newer", "", "up", "k"),
+		newBinding(actionDown, "older", "", "down", "j"),
+		newBinding(actionPageUp, "scroll up", "", "pgup", "ctrl+u"),
+		newBinding(actionPageDown, "scroll down", "", "pgdown", "ctrl+d"),
+		newBinding(actionRestore, "restore", "", "enter", "r"),
+		newBinding(actionCancel, "close", "", "esc", "q"),
+	},
+	contextTrash: {
+		newBinding(actionNone, "navigate", "", "up", "down"),
+		newBinding(actionRestore, "restore", "", "enter", "r"),
+		newBinding(actionPurge, "purge", "", "d", "delete"),
+		newBinding(actionBack, "back", "", "esc"),
+		newBinding(actionQuit, "quit", "", "q", "ctrl+c"),
+	},
+	contextPurge: {
+		newBinding(actionConfirm, "purge", "", "y"),
+		newBinding(actionCancel, "keep", "", "n", "esc"),
+		newBinding(actionQuit, "quit", "", "ctrl+c"),
+	},
+	contextMove: {
+		newBinding(actionNone, "complete", "", "tab"),
+		newBinding(actionConfirm, "move", "", "enter"),
+		newBinding(actionCancel, "cancel", "", "esc"),
+		newBinding(actionQuit, "quit", "", "ctrl+c"),
+	},
+	contextRename: {
+		newBinding(actionConfirm, "rename", "", "enter"),
+		newBinding(actionCancel, "cancel", "", "esc"),
+		newBinding(actionQuit, "quit", "", "ctrl+c"),
+	},
+	contextGitLog: {
+		newBinding(actionUp, "newer", "", "up", "k"),
+		newBinding(actionDown, "older", "", "down", "j"),
+		newBinding(actionPageUp, "scroll up", "", "pgup", "ctrl+u"),
+		newBinding(actionPageDown, "scroll down", "", "pgdown", "ctrl+d"),
+		newBinding(actionCancel, "close", "", "esc", "q"),
+	},
+	contextGitConflict: {
+		newBinding(actionUp, "previous", "", "up", "k"),
+		newBinding(actionDown, "next", "", "down", "j"),
+		newBinding(actionOpen, "open", "", "enter"),
+		newBinding(actionAbortMerge, "abort merge", "", "a"),
+		newBinding(actionCancel, "later", "", "esc"),
+		newBinding(actionQuit, "quit", "", "ctrl+c"),
+	},
+	contextPassphrase: {
+		newBinding(actionConfirm, "continue", "", "enter"),
+		newBinding(actionCancel, "cancel", "", "esc"),
+		newBinding(actionQuit, "quit", "", "ctrl+c"),
+	},
+	contextImport: {
+		newBinding(actionConfirm, "import", "", "enter", "y"),
+		newBinding(actionCancel, "cancel", "", "esc", "n"),
+		newBinding(actionQuit, "quit", "", "ctrl+c"),
+	},
+	contextCapture: {
+		newBinding(actionSave, "Save & close", "", "ctrl+s"),
+		newBinding(actionCancel, "Cancel", "", "esc"),
+		newBinding(actionQuit, "quit", "", "ctrl+c"),
+	},
+	contextHelp: {
+		newBinding(actionHelp, "close", "", "ctrl+h", "esc"),
+		newBinding(actionQuit, "quit", "", "ctrl+c"),
+	},
+}
+
+// screens lists the screens of the app, with the context receiving their
+// keys, from the one shown first when several are open. The view and key
+// handling both go by this order, so keys always reach the screen shown.
+var screens = []struct {
+	ctx  context
+	open func(m Model) bool
+}{
+	{contextLocked, func(m Model) bool { return m.showLocked }},
+	{contextRecover, func(m Model) bool { return m.showRecover }},
+	{contextConflict, func(m Model) bool { return m.showConflict }},
+	{contextUnsaved, func(m Model) bool { return m.showUnsaved }},
+	{contextVault, func(m Model) bool { return m.showVaultSwitcher }},
+	{contextPassphrase, func(m Model) bool { return m.showPassphrase }},
+	{contextImport, func(m Model) bool { return m.showImport }},
+	{contextCreate, func(m Model) bool { return m.createFileInputVisible }},
+	{contextGitConflict, func(m Model) bool { return m.showGitConflicts }},
+	{contextHistory, func(m Model) bool { return m.currentFile != "" && m.showHistory }},
+	{contextGitLog, func(m Model) bool { return m.currentFile != "" && m.showGitLog }},
+	{contextHelp, func(m Model) bool { return m.currentFile != "" && m.showHelp }},
+	{contextEditor, func(m Model) bool { return m.currentFile != "" }},
+	{contextPurge, func(m Model) bool { return m.showTrash && m.showPurgeConfirm }},
+	{contextTrash, func(m Model) bool { return m.showTrash }},
+	{contextRename, func(m Model) bool { return m.showingList && m.showRename }},
+	{contextMove, func(m Model) bool { return m.showingList && m.showMove }},
+	{contextDelete, func(m Model) bool { return m.showingList && m.showDeleteConfirm }},
+	{contextFilter, func(m Model) bool { return m.showingList && m.fileList.FilterState() == list.Filtering }},
+	{contextList, func(m Model) bool { return m.showingList }},
+}
+
+// screenContext returns the context of the screen shown, leaving out the
+// command prompt drawn over it
+func (m Model) screenContext() context {
+	for _, s := range screens {
+		if s.open(m) {
+			return s.ctx
+		}
+	}
+	return contextLanding
+}
+
+// activeContext returns the context that should receive key presses
+func (m Model) activeContext() context {
+	if m.showCommand {
+		return contextCommand
+	}
+	return m.screenContext()
+}
+
+// newKeyMap builds the active key map from the defaults, the user's
+// overrides and the leader key. Overrides are keyed by action ("save") to
+// rebind it in every context, or by context and action ("editor.save").
+// An empty key list unbinds the action.
+func newKeyMap(overrides map[string][]string, leader string) (keyMap, error) {
+	km := keyMap{
+		contexts: make(map[context][]binding, len(defaultBindings)),
+		chords:   make(map[context][]chord, len(defaultChords)),
+	}
+	for ctx, bindings := range defaultBindings {
+		km.contexts[ctx] = append([]binding(nil), bindings...)
+	}
+	for ctx, chords := range defaultChords {
+		km.chords[ctx] = append([]chord(nil), chords...)
+	}
+	if leader != "" {
+		km.leader = newBinding(actionLeader, "Leader", "", leader).Binding
+	} else {
+		km.leader = newBinding(actionLeader, "Leader", "").Binding
+	}
+
+	// Apply plain action names first so context-specific ones win
+	names := make([]string, 0, len(overrides))
+	for name := range overrides {
+		names = append(names, name)
+	}
+	sort.Slice(names, func(i, j int) bool {
+		di, dj := strings.Contains(names[i], "."), strings.Contains(names[j], ".")
+		if di != dj {
+			return !di
+		}
+		return names[i] < names[j]
+	})
+
+	for _, name := range names {
+		keys := overrides[name]
+		ctxName, actName, scoped := strings.Cut(name, ".")
+		if !scoped {
+			ctxName, actName = "", name
+		}
+
+		found := false
+		for ctx, bindings := range km.contexts {
+			if scoped && contextNames[ctx] != ctxName {
+				continue
+			}
+			for i, b := range bindings {
+				if b.action == actionNone || string(b.action) != actName {
+					continue
+				}
+				bindings[i] = newBinding(b.action, b.Help().Desc, b.group, keys...)
+				found = true
+			}
+		}
+		if !found {
+			return keyMap{}, fmt.Errorf("unknown key binding %q", name)
+		}
+	}
+
+	if err := km.checkConflicts(); err != nil {
+		return keyMap{}, err
+	}
+	return km, nil
+}
+
+// checkConflicts reports keys bound to more than one action in the same
+// context, including the leader key in contexts that accept chords
+func (km keyMap) checkConflicts() error {
+	for ctx := contextLanding; ctx <= contextCapture; ctx++ {
+		owner := make(map[string]binding)
+		bindings := km.contexts[ctx]
+		if len(km.chords[ctx]) > 0 && km.leader.Enabled() {
+			bindings = append([]binding{{Binding: km.leader, action: actionLeader}}, bindings...)
+		}
+		for _, b := range bindings {
+			for _, k := range b.Keys() {
+				if other, ok := owner[k]; ok && other.action != b.action {
+					return fmt.Errorf("key %q is bound to both %q and %q in the %s view",
+						k, other.Help().Desc, b.Help().Desc, contextNames[ctx])
+				}
+				owner[k] = b
+			}
+		}
+	}
+	return nil
+}
+
+// lookup returns the action bound to a key press in a context
+func (km keyMap) lookup(ctx context, msg tea.KeyMsg) (action, bool) {
+	for _, b := range km.contexts[ctx] {
+		if b.action != actionNone && key.Matches(msg, b.Binding) {
+			return b.action, true
+		}
+	}
+	return actionNone, false
+}
+
+// bindings returns the enabled bindings of a context, in registry order
+func (km keyMap) bindings(ctx context) []binding {
+	enabled := make([]binding, 0, len(km.contexts[ctx]))
+	for _, b := range km.contexts[ctx] {
+		if b.Enabled() {
+			enabled = append(enabled, b)
+		}
+	}
+	return enabled
+}
+
+// keyLabels maps key names to how they are shown in help text
+var keyLabels = map[string]string{
+	"up":     "↑",
+	"down":   "↓",
+	"left":   "←",
+	"right":  "→",
+	"enter":  "Enter",
+	"esc":    "Esc",
+	"delete": "Del",
+	"tab":    "Tab",
+	"space":  "Space",
+	" ":      "Space",
+	"ctrl+@": "Ctrl+Space",
+}
+
+// keyAliases maps friendlier key names to the names Bubble Tea reports
+var keyAliases = map[string]string{
+	"ctrl+space": "ctrl+@",
+}
+
+// keyLabel formats a key name for display, e.g. "ctrl+s" -> "Ctrl+S"
+func keyLabel(key string) string {
+	if label, ok := keyLabels[key]; ok {
+		return label
+	}
+
+	parts := strings.Split(key, "+")
+	for i, part := range parts {
+		if label, ok := keyLabels[part]; ok {
+			parts[i] = label
+		} else if i < len(parts)-1 || len(part) > 1 {
+			// Modifiers and named keys are capitalised
+			parts[i] = strings.ToUpper(part[:1]) + part[1:]
+		} else if i > 0 {
+			parts[i] = strings.ToUpper(part)
+		}
+	}
+	return strings.Join(parts, "+")
+}
+
+// labels returns the display labels of every key bound to an action in a context
+func (km keyMap) labels(ctx context, act action) []string {
+	var labels []string
+	for _, b := range km.contexts[ctx] {
+		if b.action != act {
+			continue
+		}
+		for _, k := range b.Keys() {
+			labels = append(labels, keyLabel(k))
+		}
+	}
+	return labels
+}
+
+// label returns the display label of the primary key bound to an action
+// in a context, or "unbound" if there is none
+func (km keyMap) label(ctx context, act action) string {
+	if labels := km.labels(ctx, act); len(labels) > 0 {
+		return labels[0]
+	}
+	return "unbound"
+}
+
+// helpKey returns the display label for a binding's keys, e.g. "↑/↓" or "Ctrl+S"
+func helpKey(act action, keys []string) string {
+	if len(keys) == 0 {
+		return ""
+	}
+	// Keys handled by a component are usually a pair (↑/↓), so show them all;
+	// otherwise the first key is the primary one
+	if act != actionNone {
+		return keyLabel(keys[0])
+	}
+	labels := make([]string, 0, len(keys))
+	for _, k := range keys {
+		labels = append(labels, keyLabel(k))
+	}
+	return strings.Join(labels, "/")
+}
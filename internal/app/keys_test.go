@@ -0,0 +1,136 @@
+package app
+
+import (
+	"strings"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+func TestKeysGoToTheScreenShown(t *testing.T) {
+	m, _ := newTestModel(t, map[string]string{"secret.md.enc": "x"})
+	m.currentFile = "secret.md.enc"
+
+	// A change on disk noticed while the passphrase dialog is open
+	m.showPassphrase = true
+	m.showConflict = true
+	if ctx := m.activeContext(); ctx != contextConflict {
+		t.Fatalf("activeContext = %s, want conflict", contextNames[ctx])
+	}
+	if view := m.View(); !strings.Contains(view, "CHANGED ON DISK") {
+		t.Fatalf("view does not show the conflict dialog:\n%s", view)
+	}
+
+	// Dismissing it goes back to the passphrase dialog
+	m = press(t, m, tea.KeyMsg{Type: tea.KeyEsc})
+	if m.showConflict || !m.showPassphrase {
+		t.Fatalf("after esc: showConflict = %v, showPassphrase = %v", m.showConflict, m.showPassphrase)
+	}
+	if ctx := m.activeContext(); ctx != contextPassphrase {
+		t.Fatalf("activeContext = %s, want passphrase", contextNames[ctx])
+	}
+}
+
+func TestActiveContext(t *testing.T) {
+	m, _ := newTestModel(t, nil)
+	for _, s := range screens {
+		if contextNames[s.ctx] == "" {
+			t.Errorf("screen context %d has no name", s.ctx)
+		}
+	}
+	if ctx := m.activeContext(); ctx != contextLanding {
+		t.Errorf("activeContext = %s, want landing", contextNames[ctx])
+	}
+	m.showCommand = true
+	if ctx := m.activeContext(); ctx != contextCommand {
+		t.Errorf("activeContext = %s, want command", contextNames[ctx])
+	}
+	if ctx := m.screenContext(); ctx != contextLanding {
+		t.Errorf("screenContext = %s, want landing under the command prompt", contextNames[ctx])
+	}
+}
+
+func TestNewKeyMapCopiesDefaults(t *testing.T) {
+	km, err := newKeyMap(map[string][]string{"editor.save": {"alt+s"}}, "ctrl+space")
+	if err != nil {
+		t.Fatal(err)
+	}
+	km.chords[contextEditor][0].action = actionNone
+	km.chords[contextList] = nil
+
+	if defaultChords[contextEditor][0].action != actionSave || len(defaultChords[contextList]) == 0 {
+		t.Error("changing a key map changed the default chords")
+	}
+	if keys := defaultBindings[contextEditor][0].Keys(); len(keys) != 1 || keys[0] != "ctrl+s" {
+		t.Errorf("default editor save keys = %v after an override", keys)
+	}
+}
+
+func TestLeaderChords(t *testing.T) {
+	leader := tea.KeyMsg{Type: tea.KeyCtrlAt}
+	m, _ := newTestModel(t, map[string]string{"plan.md": "x"})
+	m.cfg.WhichKeyDelay = 0
+
+	// Leader n l lists the notes
+	m = press(t, m, leader, runes("n"))
+	if !m.chordPending || !m.showWhichKey {
+		t.Fatalf("after leader n: chordPending = %v, showWhichKey = %v", m.chordPending, m.showWhichKey)
+	}
+	m = press(t, m, runes("l"))
+	if m.chordPending || !m.showingList {
+		t.Fatalf("after leader n l: chordPending = %v, showingList = %v", m.chordPending, m.showingList)
+	}
+
+	// An unknown sequence is dropped with a warning
+	m = press(t, m, leader, runes("x"))
+	if m.chordPending || m.statusMessage != "No leader binding for x" {
+		t.Errorf("after leader x: chordPending = %v, status = %q", m.chordPending, m.statusMessage)
+	}
+
+	// Esc abandons a sequence
+	m = press(t, m, leader, runes("n"), tea.KeyMsg{Type: tea.KeyEsc})
+	if m.chordPending || m.showWhichKey || !m.showingList {
+		t.Errorf("after leader n esc: chordPending = %v, showWhichKey = %v", m.chordPending, m.showWhichKey)
+	}
+}
+
+func TestWhichKeyPopup(t *testing.T) {
+	m, _ := newTestModel(t, nil)
+	next, _ := m.Update(tea.WindowSizeMsg{Width: 80, Height: 24})
+	m = next.(Model)
+
+	// The popup waits for the delay and ignores timers of older prefixes
+	m = press(t, m, tea.KeyMsg{Type: tea.KeyCtrlAt})
+	next, _ = m.Update(whichKeyMsg{seq: m.chordSeq - 1})
+	m = next.(Model)
+	if m.showWhichKey {
+		t.Fatal("a stale which-key timer showed the popup")
+	}
+	next, _ = m.Update(whichKeyMsg{seq: m.chordSeq})
+	m = next.(Model)
+	if !m.showWhichKey {
+		t.Fatal("which-key popup not shown after the delay")
+	}
+
+	view := m.View()
+	for _, want := range []string{"Ctrl+Space", "+note", "+vault"} {
+		if !strings.Contains(view, want) {
+			t.Errorf("which-key popup does not show %q:\n%s", want, view)
+		}
+	}
+
+	// After a prefix only its continuations are listed
+	keys, descs := chordContinuations(defaultChords[contextEditor], []string{"h"})
+	if strings.Join(keys, " ") != "1 2 3" || descs["2"] != "Heading 2" {
+		t.Errorf("continuations of h = %v %v", keys, descs)
+	}
+	popup := renderPlainWhichKey(defaultChords[contextEditor], []string{"f"}, "Ctrl+Space")
+	for _, want := range []string{"Save note", "Git log"} {
+		if !strings.Contains(popup, want) {
+			t.Errorf("plain popup for f does not show %q:\n%s", want, popup)
+		}
+	}
+	if strings.Contains(popup, "Heading") {
+		t.Errorf("plain popup for f shows other prefixes:\n%s", popup)
+	}
+}
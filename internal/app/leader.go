@@ -0,0 +1,247 @@
+package app
+
+import (
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/charmbracelet/bubbles/key"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+
+	"github.com/shalshcode08/Term-Note/internal/notes"
+	"github.com/shalshcode08/Term-Note/internal/ui/styles"
+)
+
+// chord is a key sequence typed after the leader key
+type chord struct {
+	keys   string // Space-separated keys, e.g. "l t"
+	action action
+	desc   string
+}
+
+// chordGroups names the prefixes shown in the which-key popup
+var chordGroups = map[string]string{
+	"f": "file",
+	"h": "heading",
+	"l": "insert",
+	"n": "note",
+	"t": "todo",
+	"v": "vault",
+}
+
+// defaultChords are the leader sequences available in each context
+var defaultChords = map[context][]chord{
+	contextLanding: {
+		{"n n", actionNewNote, "New note"},
+		{"n d", actionDailyNote, "Daily note"},
+		{"n l", actionListNotes, "List notes"},
+		{"v v", actionSwitchVault, "Switch vault"},
+	},
+	contextList: {
+		{"n n", actionNewNote, "New note"},
+		{"n d", actionDailyNote, "Daily note"},
+		{"v v", actionSwitchVault, "Switch vault"},
+	},
+	contextEditor: {
+		{"f s", actionSave, "Save note"},
+		{"f c", actionClose, "Close note"},
+		{"f h", actionHistory, "Note history"},
+		{"f g", actionGitLog, "Git log"},
+		{"l b", actionBullet, "Bullet point"},
+		{"l c", actionCodeBlock, "Code block"},
+		{"l i", actionImage, "Image"},
+		{"l l", actionLink, "Link"},
+		{"l r", actionRule, "Horizontal rule"},
+		{"l t", actionTable, "Table"},
+		{"h 1", actionHeading1, "Heading 1"},
+		{"h 2", actionHeading2, "Heading 2"},
+		{"h 3", actionHeading3, "Heading 3"},
+		{"t n", actionTodo, "New todo"},
+		{"t t", actionToggleTodo, "Toggle todo"},
+		{"n d", actionDailyNote, "Daily note"},
+		{"?", actionHelp, "Help"},
+		{":", actionCommand, "Command"},
+	},
+}
+
+// whichKeyMsg is sent after the which-key delay. It is ignored if another
+// key was pressed in the meantime.
+type whichKeyMsg struct {
+	seq int
+}
+
+// startChord begins a leader sequence
+func (m Model) startChord() (tea.Model, tea.Cmd) {
+	m.chordPending = true
+	m.chordKeys = nil
+	m.showWhichKey = false
+	return m, m.whichKeyTimer()
+}
+
+// whichKeyTimer schedules the which-key popup for the current prefix
+func (m *Model) whichKeyTimer() tea.Cmd {
+	m.chordSeq++
+	seq := m.chordSeq
+	if m.cfg.WhichKeyDelay <= 0 {
+		m.showWhichKey = true
+		return nil
+	}
+	return tea.Tick(m.cfg.WhichKeyDelay, func(time.Time) tea.Msg {
+		return whichKeyMsg{seq: seq}
+	})
+}
+
+// continueChord handles a key press while a leader sequence is pending
+func (m Model) continueChord(ctx context, msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	if msg.String() == "esc" || key.Matches(msg, m.keys.leader) {
+		m.cancelChord()
+		return m, nil
+	}
+
+	m.chordKeys = append(m.chordKeys, msg.String())
+	typed := strings.Join(m.chordKeys, " ")
+
+	prefix := false
+	for _, c := range m.keys.chords[ctx] {
+		if c.keys == typed {
+			m.cancelChord()
+			return m.perform(ctx, c.action, msg)
+		}
+		if strings.HasPrefix(c.keys, typed+" ") {
+			prefix = true
+		}
+	}
+
+	if !prefix {
+		m.cancelChord()
+		m.statusMessage = "No leader binding for " + typed
+		m.statusType = "warning"
+		return m, nil
+	}
+
+	// Wait for the next key; keep the popup up if it is already showing
+	if m.showWhichKey {
+		return m, nil
+	}
+	return m, m.whichKeyTimer()
+}
+
+// cancelChord abandons the pending leader sequence
+func (m *Model) cancelChord() {
+	m.chordPending = false
+	m.chordKeys = nil
+	m.showWhichKey = false
+	m.chordSeq++
+}
+
+// openDailyNote opens today's daily note in the editor, creating it if needed
+func (m *Model) openDailyNote() {
+	name := notes.DailyNoteName(time.Now())
+
+	filename, err := notes.Resolve(m.vault, name, m.cfg.DefaultExtension)
+	if err != nil {
+		var createErr error
+		if filename, createErr = notes.Create(m.vault, name, m.cfg.DefaultExtension); createErr != nil {
+			m.statusMessage = "Cannot create daily note: " + createErr.Error()
+			m.statusType = "error"
+			return
+		}
+		m.commitNotes("Add "+filename, filename)
+	}
+
+	m.createFileInputVisible = false
+	if err := m.OpenNote(filename); err != nil {
+		m.statusMessage = "Cannot open daily note: " + err.Error()
+		m.statusType = "error"
+	}
+}
+
+// chordContinuations returns the keys that can follow the typed prefix, in
+// sorted order, with the description or "+group" name of each
+func chordContinuations(chords []chord, typed []string) ([]string, map[string]string) {
+	prefix := strings.Join(typed, " ")
+	if prefix != "" {
+		prefix += " "
+	}
+
+	// Collect the next key of every chord that continues the prefix
+	next := make(map[string]string)
+	for _, c := range chords {
+		if !strings.HasPrefix(c.keys, prefix) {
+			continue
+		}
+		rest := strings.Split(strings.TrimPrefix(c.keys, prefix), " ")
+		if len(rest) == 1 {
+			next[rest[0]] = c.desc
+		} else if _, ok := next[rest[0]]; !ok {
+			group := chordGroups[strings.TrimSpace(prefix+rest[0])]
+			if group == "" {
+				group = "more"
+			}
+			next[rest[0]] = "+" + group
+		}
+	}
+
+	keys := make([]string, 0, len(next))
+	for k := range next {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	return keys, next
+}
+
+// renderWhichKey renders the popup listing the keys that can follow the typed prefix
+func renderWhichKey(chords []chord, typed []string, leaderLabel string) string {
+	keys, next := chordContinuations(chords, typed)
+
+	keyStyle := lipgloss.NewStyle().Foreground(styles.ColorPrimary).Bold(true)
+	descStyle := lipgloss.NewStyle().Foreground(styles.ColorText)
+	groupStyle := lipgloss.NewStyle().Foreground(styles.ColorAccent)
+
+	var items []string
+	for _, k := range keys {
+		desc := next[k]
+		style := descStyle
+		if strings.HasPrefix(desc, "+") {
+			style = groupStyle
+		}
+		items = append(items, keyStyle.Render(k)+"  "+style.Render(desc))
+	}
+
+	// Lay the items out in columns of up to five rows
+	const rows = 5
+	var columns []string
+	for i := 0; i < len(items); i += rows {
+		end := min(i+rows, len(items))
+		column := lipgloss.NewStyle().Width(22).Render(strings.Join(items[i:end], "\n"))
+		columns = append(columns, column)
+	}
+
+	title := lipgloss.NewStyle().
+		Foreground(styles.ColorMuted).
+		Render(strings.TrimSpace(leaderLabel + " " + strings.Join(typed, " ")))
+
+	content := lipgloss.JoinVertical(
+		lipgloss.Left,
+		title,
+		lipgloss.JoinHorizontal(lipgloss.Top, columns...),
+	)
+
+	return lipgloss.NewStyle().
+		Border(lipgloss.RoundedBorder()).
+		BorderForeground(styles.ColorBorder).
+		Padding(0, 1).
+		Render(content)
+}
+
+// overlayBottom draws popup over the last lines of base, keeping the
+// result within height lines
+func overlayBottom(base, popup string, height int) string {
+	baseLines := strings.Split(base, "\n")
+	popupLines := strings.Split(popup, "\n")
+
+	keep := max(min(len(baseLines), height)-len(popupLines), 0)
+	return strings.Join(append(baseLines[:keep], popupLines...), "\n")
+}
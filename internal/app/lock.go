@@ -0,0 +1,206 @@
+package app
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"strings"
+
+	"github.com/charmbracelet/bubbles/key"
+	"github.com/charmbracelet/bubbles/textarea"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+
+	"github.com/shalshcode08/Term-Note/internal/notes"
+	"github.com/shalshcode08/Term-Note/internal/ui/styles"
+)
+
+// lockNote locks the open note for this instance. If another running
+// instance holds the lock, the user is asked whether to open the note
+// read-only or take the lock over.
+func (m *Model) lockNote() {
+	err := notes.AcquireLock(m.cfg.LockDir(), m.currentFile)
+	var locked *notes.LockedError
+	switch {
+	case errors.As(err, &locked):
+		m.lockHolder = locked.Holder
+		m.readOnly = true
+		m.showLocked = true
+	case err != nil:
+		// Locking is advisory; keep editing without it
+		m.statusMessage = fmt.Sprintf("Cannot lock note: %v", err)
+		m.statusType = "warning"
+	default:
+		m.lockHeld = true
+	}
+}
+
+// unlockNote releases the lock on the open note, if this instance holds it
+func (m *Model) unlockNote() {
+	if m.lockHeld {
+		if err := notes.ReleaseLock(m.cfg.LockDir(), m.currentFile); err != nil {
+			m.statusMessage = err.Error()
+			m.statusType = "error"
+		}
+	}
+	m.lockHeld = false
+	m.lockHolder = notes.Lock{}
+	m.showLocked = false
+}
+
+// lockedOut reports whether the open note is locked by another instance
+func (m Model) lockedOut() bool {
+	return m.lockHolder.PID != 0
+}
+
+// stealLock takes the lock on the open note over from the other instance
+func (m *Model) stealLock() {
+	m.showLocked = false
+	if err := notes.StealLock(m.cfg.LockDir(), m.currentFile); err != nil {
+		m.statusMessage = fmt.Sprintf("Cannot take over the lock: %v", err)
+		m.statusType = "error"
+		return
+	}
+
+	m.lockHeld = true
+	m.lockHolder = notes.Lock{}
+	m.readOnly = false
+	if info, err := m.vault.Stat(m.currentFile); err == nil {
+		m.readOnly = info.Mode().Perm()&0200 == 0
+	}
+	m.statusMessage = "Took over the lock on " + m.currentFile
+	m.statusType = "success"
+	m.checkSwap()
+}
+
+// checkLock notices when another instance took the lock on the open note
+// over, and makes the note read-only here
+func (m *Model) checkLock() {
+	if !m.lockHeld {
+		return
+	}
+	holder, err := notes.ReadLock(m.cfg.LockDir(), m.currentFile)
+	if errors.Is(err, fs.ErrNotExist) {
+		// Removed by hand; take it again
+		m.lockHeld = false
+		m.lockNote()
+		return
+	}
+	if err != nil || holder.Mine() {
+		return
+	}
+
+	m.lockHeld = false
+	m.lockHolder = holder
+	m.readOnly = true
+	m.statusMessage = fmt.Sprintf("PID %d on host %s took over this note, it is now read-only", holder.PID, holder.Host)
+	m.statusType = "warning"
+}
+
+// readOnlyKey handles a key in the editor of a note locked by another
+// instance. Only keys that move the cursor reach the textarea, and bound
+// actions that would change the text are undone.
+func (m Model) readOnlyKey(ctx context, msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	_, bound := m.keys.lookup(ctx, msg)
+	if !bound && !m.chordPending && !key.Matches(msg, m.keys.leader) && !movesCursor(m.textArea.KeyMap, msg) {
+		m.statusMessage = (&notes.LockedError{Holder: m.lockHolder}).Error() + ", changes are not allowed"
+		m.statusType = "warning"
+		return m, nil
+	}
+
+	value := m.textArea.Value()
+	updated, cmd := m.handleKey(ctx, msg)
+	next := updated.(Model)
+	if next.currentFile == m.currentFile && next.textArea.Value() != value {
+		next.textArea.SetValue(value)
+		next.statusMessage = (&notes.LockedError{Holder: m.lockHolder}).Error() + ", changes are not allowed"
+		next.statusType = "warning"
+	}
+	return next, cmd
+}
+
+// movesCursor reports whether a key only moves the textarea cursor
+func movesCursor(km textarea.KeyMap, msg tea.KeyMsg) bool {
+	return key.Matches(msg,
+		km.CharacterForward, km.CharacterBackward,
+		km.WordForward, km.WordBackward,
+		km.LineNext, km.LinePrevious,
+		km.LineStart, km.LineEnd,
+		km.InputBegin, km.InputEnd)
+}
+
+// renderLockedDialog renders the choices for a note open in another instance
+func renderLockedDialog(filename string, holder notes.Lock, keys keyMap) string {
+	dialogStyle := lipgloss.NewStyle().
+		Border(lipgloss.RoundedBorder()).
+		BorderForeground(styles.ColorWarning).
+		Padding(1, 4).
+		Width(60)
+
+	titleStyle := lipgloss.NewStyle().
+		Foreground(styles.ColorWarning).
+		Bold(true).
+		Align(lipgloss.Center).
+		Width(52)
+
+	filenameStyle := lipgloss.NewStyle().
+		Foreground(styles.ColorPrimary).
+		Bold(true).
+		Align(lipgloss.Center).
+		Width(52)
+
+	messageStyle := lipgloss.NewStyle().
+		Foreground(styles.ColorMuted).
+		Align(lipgloss.Center).
+		Width(52).
+		MarginTop(1)
+
+	buttonsStyle := lipgloss.NewStyle().
+		Align(lipgloss.Center).
+		Width(52).
+		MarginTop(1)
+
+	title := titleStyle.Render("🔒 NOTE IS LOCKED")
+	file := filenameStyle.Render(filename)
+	message := messageStyle.Render(fmt.Sprintf("This note is open in PID %d on host %s\n(opened %s).",
+		holder.PID, holder.Host, notes.FormatRelativeTime(holder.Since)))
+
+	button := func(label string, act action, color lipgloss.Color) string {
+		return lipgloss.NewStyle().
+			Foreground(styles.ColorBg).
+			Background(color).
+			Bold(true).
+			Render(fmt.Sprintf(" %s (%s) ", label, keys.label(contextLocked, act)))
+	}
+	buttons := buttonsStyle.Render(strings.Join([]string{
+		button("Read-only", actionReadOnly, styles.ColorSecondary),
+		button("Steal lock", actionStealLock, styles.ColorError),
+		button("Cancel", actionCancel, styles.ColorMuted),
+	}, " "))
+
+	content := lipgloss.JoinVertical(
+		lipgloss.Left,
+		title,
+		"",
+		file,
+		message,
+		buttons,
+	)
+
+	return dialogStyle.Render(content)
+}
+
+// renderPlainLockedDialog renders the choices for a locked note in plain mode
+func renderPlainLockedDialog(filename string, holder notes.Lock, keys keyMap) string {
+	return strings.Join([]string{
+		"Note is locked: " + filename,
+		"",
+		fmt.Sprintf("This note is open in PID %d on host %s (opened %s).",
+			holder.PID, holder.Host, notes.FormatRelativeTime(holder.Since)),
+		"",
+		fmt.Sprintf("Press %s to open it read-only, %s to steal the lock or %s to cancel.",
+			plainLabel(keys, contextLocked, actionReadOnly),
+			plainLabel(keys, contextLocked, actionStealLock),
+			plainLabel(keys, contextLocked, actionCancel)),
+	}, "\n")
+}
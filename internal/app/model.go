@@ -1,7 +1,8 @@
 package app
 
 import (
-	"os"
+	"fmt"
+	"time"
 
 	"github.com/charmbracelet/bubbles/list"
 	"github.com/charmbracelet/bubbles/textarea"
@@ -22,86 +23,284 @@ var (
 type Model struct {
 	newFileInput           textinput.Model
 	createFileInputVisible bool
-	currentFile            *os.File
+	cfg                    *config.Config
+	vault                  notes.Vault    // Where the notes of the active vault are stored
+	watcher                *notes.Watcher // Reports changes to the vault made by anyone
+	keys                   keyMap
+	currentFile            string           // Vault-relative filename of the open note, "" when none
+	savedContent           string           // Note content as last read from or written to disk
+	lastSaved              time.Time        // When the open note was last saved, zero if not yet
+	lastSeen               string           // Content at the previous editor tick
+	lastChange             time.Time        // When the content was last seen to change
+	readOnly               bool             // The open note must not be written without asking
+	showUnsaved            bool             // Show the unsaved changes dialog
+	unsavedNext            action           // Action to run once the unsaved changes are dealt with
+	diskStamp              notes.Stamp      // Version of the open note on disk when loaded or saved
+	showConflict           bool             // Show the dialog for a note changed on disk
+	diskContent            string           // Content found on disk when the change was detected
+	diskDiff               []notes.DiffLine // Changes made on disk, computed when the dialog opens
+	diskSealed             bool             // The version on disk does not open with the note's key
+	dismissedDisk          string           // Disk content the user chose to keep editing over
+	swapWritten            bool             // A swap file holds the current unsaved edits
+	lastSwapped            string           // Content last written to the swap file
+	showRecover            bool             // Show the dialog for a swap file found on open
+	showSwapDiff           bool             // Show the differences in the recover dialog
+	swapContent            string           // Content of the swap file found on open
+	swapDiff               []notes.DiffLine // Changes held by the swap file, computed when the dialog opens
+	swapTime               time.Time        // When that swap file was written
+	lockHeld               bool             // This instance holds the lock on the open note
+	lockHolder             notes.Lock       // Instance holding the lock when the note is open read-only
+	showLocked             bool             // Show the dialog for a note open in another instance
+	showHistory            bool             // Show the history panel of the open note
+	snapshots              []notes.Snapshot
+	historyCursor          int             // Highlighted snapshot
+	historyContent         string          // Content of the highlighted snapshot
+	historyDiff            string          // Unified diff of that snapshot against the editor
+	historyScroll          int             // First diff line shown
+	autosavedFrom          *savedVersion   // Version the autosaves not yet in history replaced, nil if none
+	gitRepo                bool            // The vault is inside a git work tree
+	syncing                bool            // A pull and push was asked for and has not ended
+	gitRunning             bool            // A commit or sync runs in the background
+	commits                []pendingCommit // Commits waiting to run, in order
+	quitting               bool            // Quit once the git work running has ended
+	showGitLog             bool            // Show the git log of the open note
+	gitLog                 []notes.Commit
+	gitLogCursor           int                // Highlighted commit
+	showGitConflicts       bool               // Show the dialog for merge conflicts left by a sync
+	gitConflicts           []string           // Vault-relative paths of the conflicted notes
+	gitConflictCursor      int                // Highlighted conflicted note
+	noteKey                *notes.Key         // Key of the open note if it is encrypted
+	passphrases            keyring            // Passphrase remembered for opening encrypted notes
+	showPassphrase         bool               // Show the passphrase dialog
+	passphraseStep         passphraseStep     // What the passphrase dialog asks for
+	passphraseFile         string             // Encrypted note to open once unlocked
+	newPassphrase          string             // First entry of a new passphrase
+	showImport             bool               // Show the dry run report of an import
+	importPlan             *notes.ImportPlan  // Notes read from the source being imported
+	importReport           notes.ImportReport // What importing them would do
+	trashList              list.Model
+	showTrash              bool            // Show the trash view
+	showPurgeConfirm       bool            // Show the purge confirmation dialog
+	lastTrashed            notes.TrashItem // Note that the undo toast can restore
+	undoUntil              time.Time       // When the undo toast goes away
 	textArea               textarea.Model
 	fileList               list.Model
 	showingList            bool
 	statusMessage          string
-	statusType             string // "success", "error", "warning", ""
-	showHelp               bool   // Toggle help overlay
-	showDeleteConfirm      bool   // Show delete confirmation dialog
-	fileToDelete           string // Filename to delete
-	windowWidth            int    // Terminal window width
-	windowHeight           int    // Terminal window height
+	statusType             string   // "success", "error", "warning", ""
+	showHelp               bool     // Toggle help overlay
+	showDeleteConfirm      bool     // Show delete confirmation dialog
+	fileToDelete           string   // Filename to delete
+	folder                 string   // Vault-relative folder shown in the list, "" for the root
+	showMove               bool     // Show the dialog for moving a note to another folder
+	fileToMove             string   // Filename to move
+	showRename             bool     // Show the dialog for renaming a note
+	fileToRename           string   // Filename to rename
+	showVaultSwitcher      bool     // Show vault switcher dialog
+	vaultCursor            int      // Highlighted vault in the switcher
+	chordPending           bool     // Leader pressed, waiting for the rest of a chord
+	chordKeys              []string // Keys typed since the leader
+	chordSeq               int      // Invalidates stale which-key timers
+	showWhichKey           bool     // Show the chord continuation popup
+	showCommand            bool     // Show the : command prompt
+	commandInput           textinput.Model
+	moveInput              textinput.Model
+	renameInput            textinput.Model
+	passphraseInput        textinput.Model
+	windowWidth            int // Terminal window width
+	windowHeight           int // Terminal window height
 }
 
-// New creates and initializes a new application model
-func New() Model {
+// New creates and initializes a new application model. It fails if the
+// configured key bindings are invalid or conflict with each other, or the
+// theme cannot be loaded. The notes are read from and written to vault.
+func New(cfg *config.Config, vault notes.Vault) (Model, error) {
+	keys, err := newKeyMap(cfg.Keys, cfg.Leader)
+	if err != nil {
+		return Model{}, fmt.Errorf("invalid key bindings: %w", err)
+	}
+
+	if err := LoadThemes(cfg); err != nil {
+		return Model{}, err
+	}
+
 	ti := textinput.New()
 	ti.Placeholder = "my-awesome-note"
 	ti.Focus()
 	ti.CharLimit = 100
 	ti.Width = 56
 	ti.Prompt = "" // Hide default prompt, we'll add custom one in view
-	ti.Cursor.Style = lipgloss.NewStyle().Foreground(styles.ColorPrimary)
-	ti.PromptStyle = lipgloss.NewStyle().Foreground(styles.ColorPrimary).Bold(true)
-	ti.TextStyle = lipgloss.NewStyle().Foreground(styles.ColorText)
-	ti.PlaceholderStyle = lipgloss.NewStyle().Foreground(styles.ColorMuted).Italic(true)
+	styleTextInput(&ti)
+
+	ta := newTextArea(cfg)
+
+	notesList, err := notes.ListFiles(vault, "", cfg.Plain)
+	if err != nil {
+		return Model{}, err
+	}
+	finalList := list.New(notesList, list.NewDefaultDelegate(), 0, 0)
+	finalList.Title = listTitle(cfg, "")
+	styleList(&finalList)
+	finalList.SetShowStatusBar(true)
+	finalList.SetFilteringEnabled(true)
+	finalList.SetStatusBarItemName("note", "notes")
+	finalList.SetShowHelp(false) // Disable default help, we have custom help text
+
+	trashList := newTrashList()
+	mi := newMoveInput()
+	ri := newRenameInput()
+	pi := newPassphraseInput()
+
+	if cfg.Plain {
+		usePlainMode(&ti, &ta, &finalList)
+		plainList(&trashList)
+		mi.Prompt = "> "
+		ri.Prompt = "> "
+		pi.Prompt = "> "
+		pi.EchoCharacter = '*'
+	}
 
+	// Locks left by instances that did not exit cleanly
+	notes.CleanLocks(cfg.LockDir())
+	// Notes kept in the trash longer than configured
+	notes.PurgeOldTrash(vault, cfg.Trash.PurgeAfter, time.Now())
+
+	return Model{
+		cfg:                    cfg,
+		vault:                  vault,
+		keys:                   keys,
+		newFileInput:           ti,
+		createFileInputVisible: false,
+		textArea:               ta,
+		commandInput:           newCommandInput(),
+		moveInput:              mi,
+		renameInput:            ri,
+		passphraseInput:        pi,
+		fileList:               finalList,
+		trashList:              trashList,
+		watcher:                notes.Watch(vault, watchInterval),
+		gitRepo:                notes.IsGitRepo(cfg.VaultDir),
+		showingList:            false,
+		statusMessage:          "",
+		statusType:             "",
+		showHelp:               false,
+		showDeleteConfirm:      false,
+		fileToDelete:           "",
+		windowWidth:            80,
+		windowHeight:           24,
+	}, nil
+}
+
+// newTextArea creates the note editor textarea
+func newTextArea(cfg *config.Config) textarea.Model {
 	ta := textarea.New()
 	ta.Placeholder = "Start writing your note..."
 	ta.Focus()
-	ta.ShowLineNumbers = false
-	ta.CharLimit = 0 // No limit
 	ta.SetWidth(80)
 	ta.SetHeight(20)
 	ta.Prompt = "" // Remove prompt to eliminate left line
+	styleTextArea(&ta)
+	applyEditorConfig(&ta, cfg)
+
+	return ta
+}
+
+// styleTextInput applies the current theme to a text input
+func styleTextInput(ti *textinput.Model) {
+	ti.Cursor.Style = lipgloss.NewStyle().Foreground(styles.ColorPrimary)
+	ti.PromptStyle = lipgloss.NewStyle().Foreground(styles.ColorPrimary).Bold(true)
+	ti.TextStyle = lipgloss.NewStyle().Foreground(styles.ColorText)
+	ti.PlaceholderStyle = lipgloss.NewStyle().Foreground(styles.ColorMuted).Italic(true)
+	ti.CompletionStyle = lipgloss.NewStyle().Foreground(styles.ColorMuted)
+}
+
+// styleTextArea applies the current theme to a textarea
+func styleTextArea(ta *textarea.Model) {
 	ta.FocusedStyle.CursorLine = lipgloss.NewStyle()
 	ta.FocusedStyle.Placeholder = lipgloss.NewStyle().Foreground(styles.ColorMuted)
 	ta.FocusedStyle.Text = lipgloss.NewStyle().Foreground(styles.ColorText)
 	ta.FocusedStyle.Prompt = lipgloss.NewStyle()
 	ta.FocusedStyle.EndOfBuffer = lipgloss.NewStyle()
-	ta.FocusedStyle.LineNumber = lipgloss.NewStyle()
+	ta.FocusedStyle.LineNumber = lipgloss.NewStyle().Foreground(styles.ColorMuted)
+	ta.FocusedStyle.CursorLineNumber = lipgloss.NewStyle().Foreground(styles.ColorAccent)
 	ta.BlurredStyle.Placeholder = lipgloss.NewStyle().Foreground(styles.ColorMuted)
 	ta.BlurredStyle.Text = lipgloss.NewStyle().Foreground(styles.ColorText)
 	ta.BlurredStyle.Prompt = lipgloss.NewStyle()
 	ta.BlurredStyle.EndOfBuffer = lipgloss.NewStyle()
-	ta.BlurredStyle.LineNumber = lipgloss.NewStyle()
+	ta.BlurredStyle.LineNumber = lipgloss.NewStyle().Foreground(styles.ColorMuted)
+	ta.Cursor.Style = lipgloss.NewStyle().Foreground(styles.ColorPrimary)
+}
 
-	notesList := notes.ListFiles(config.VaultDir)
-	finalList := list.New(notesList, list.NewDefaultDelegate(), 0, 0)
-	finalList.Title = "All Notes"
-	finalList.Styles.Title = lipgloss.NewStyle().
+// styleList applies the current theme to the note list
+func styleList(l *list.Model) {
+	l.Styles.Title = lipgloss.NewStyle().
 		Foreground(styles.ColorPrimary).
 		Bold(true)
-	finalList.Styles.FilterPrompt = lipgloss.NewStyle().Foreground(styles.ColorPrimary).Bold(true)
-	finalList.Styles.FilterCursor = lipgloss.NewStyle().Foreground(styles.ColorPrimary)
-	finalList.SetShowStatusBar(true)
-	finalList.SetFilteringEnabled(true)
-	finalList.Styles.StatusBar = lipgloss.NewStyle().
+	l.Styles.FilterPrompt = lipgloss.NewStyle().Foreground(styles.ColorPrimary).Bold(true)
+	l.Styles.FilterCursor = lipgloss.NewStyle().Foreground(styles.ColorPrimary)
+	l.Styles.StatusBar = lipgloss.NewStyle().
 		Foreground(styles.ColorMuted).
 		Padding(0, 2)
-	finalList.Styles.HelpStyle = styles.ListHelpStyle
-	finalList.SetStatusBarItemName("note", "notes")
-	finalList.SetShowHelp(false) // Disable default help, we have custom help text
+	l.Styles.HelpStyle = styles.ListHelpStyle
 
-	return Model{
-		newFileInput:           ti,
-		createFileInputVisible: false,
-		textArea:               ta,
-		fileList:               finalList,
-		showingList:            false,
-		statusMessage:          "",
-		statusType:             "",
-		showHelp:               false,
-		showDeleteConfirm:      false,
-		fileToDelete:           "",
-		windowWidth:            80,
-		windowHeight:           24,
+	delegate := list.NewDefaultDelegate()
+	delegate.Styles.NormalTitle = delegate.Styles.NormalTitle.Foreground(styles.ColorText)
+	delegate.Styles.NormalDesc = delegate.Styles.NormalDesc.Foreground(styles.ColorMuted)
+	delegate.Styles.SelectedTitle = delegate.Styles.SelectedTitle.
+		Foreground(styles.ColorPrimary).
+		BorderForeground(styles.ColorPrimary)
+	delegate.Styles.SelectedDesc = delegate.Styles.SelectedDesc.
+		Foreground(styles.ColorAccent).
+		BorderForeground(styles.ColorPrimary)
+	delegate.Styles.DimmedTitle = delegate.Styles.DimmedTitle.Foreground(styles.ColorMuted)
+	delegate.Styles.DimmedDesc = delegate.Styles.DimmedDesc.Foreground(styles.ColorMuted)
+	delegate.Styles.FilterMatch = delegate.Styles.FilterMatch.Foreground(styles.ColorAccent)
+	l.SetDelegate(delegate)
+}
+
+// LoadThemes registers the user's theme files and applies the configured theme
+func LoadThemes(cfg *config.Config) error {
+	userThemes, err := cfg.LoadThemes()
+	if err != nil {
+		return err
 	}
+	for name, colors := range userThemes {
+		theme, err := styles.NewTheme(name, colors)
+		if err != nil {
+			return err
+		}
+		styles.Register(theme)
+	}
+	return styles.Use(cfg.Theme)
+}
+
+// setTheme switches the theme and restyles every component so the next
+// render uses the new colors
+func (m *Model) setTheme(name string) error {
+	if m.cfg.Plain {
+		return fmt.Errorf("themes are not available in plain mode")
+	}
+	if err := styles.Use(name); err != nil {
+		return err
+	}
+	styleTextInput(&m.newFileInput)
+	styleTextInput(&m.commandInput)
+	styleTextInput(&m.moveInput)
+	styleTextInput(&m.renameInput)
+	styleTextInput(&m.passphraseInput)
+	styleTextArea(&m.textArea)
+	styleList(&m.fileList)
+	styleList(&m.trashList)
+	return nil
+}
+
+// applyEditorConfig applies the editor settings of the active vault to the textarea
+func applyEditorConfig(ta *textarea.Model, cfg *config.Config) {
+	ta.ShowLineNumbers = cfg.Editor.ShowLineNumbers
+	ta.CharLimit = cfg.Editor.CharLimit // 0 means no limit
 }
 
 // Init initializes the model (Bubble Tea interface)
 func (m Model) Init() tea.Cmd {
-	return nil
+	return tea.Batch(editorTick(), waitForChanges(m.watcher))
 }
@@ -0,0 +1,93 @@
+package app
+
+import (
+	"errors"
+	"io/fs"
+	"strings"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/shalshcode08/Term-Note/internal/notes"
+)
+
+func TestCreateSaveOpenDelete(t *testing.T) {
+	m, vault := newTestModel(t, map[string]string{"old.md": "an older note"})
+	next, _ := m.Update(tea.WindowSizeMsg{Width: 100, Height: 30})
+	m = next.(Model)
+
+	// Create
+	m = press(t, m, tea.KeyMsg{Type: tea.KeyCtrlN}, runes("plan"), tea.KeyMsg{Type: tea.KeyEnter})
+	if m.currentFile != "plan.md" {
+		t.Fatalf("after creating: currentFile = %q, status %q", m.currentFile, m.statusMessage)
+	}
+	if content, err := vault.Read("plan.md"); err != nil || len(content) != 0 {
+		t.Fatalf("created note = %q, %v", content, err)
+	}
+
+	// Creating it again is refused
+	m = press(t, m, tea.KeyMsg{Type: tea.KeyEsc}, tea.KeyMsg{Type: tea.KeyCtrlN}, runes("plan"), tea.KeyMsg{Type: tea.KeyEnter})
+	if m.statusMessage != "File already exists with this name" || !m.createFileInputVisible {
+		t.Fatalf("creating a duplicate: %q", m.statusMessage)
+	}
+	m = press(t, m, tea.KeyMsg{Type: tea.KeyEsc})
+
+	// Open and save
+	m = press(t, m, tea.KeyMsg{Type: tea.KeyCtrlL})
+	if !m.showingList {
+		t.Fatal("note list is not shown")
+	}
+	m.fileList.Select(indexOf(t, m, "plan.md"))
+	m = press(t, m, tea.KeyMsg{Type: tea.KeyEnter}, runes("ship it"), tea.KeyMsg{Type: tea.KeyCtrlS})
+	if content, _ := vault.Read("plan.md"); string(content) != "ship it" {
+		t.Fatalf("saved note = %q, status %q", content, m.statusMessage)
+	}
+	if m.statusMessage != "Saved plan.md" || m.dirty() {
+		t.Fatalf("after saving: status %q, dirty %v", m.statusMessage, m.dirty())
+	}
+
+	// Reopen from the list
+	m = press(t, m, tea.KeyMsg{Type: tea.KeyEsc}, tea.KeyMsg{Type: tea.KeyCtrlL})
+	m.fileList.Select(indexOf(t, m, "old.md"))
+	m = press(t, m, tea.KeyMsg{Type: tea.KeyEnter})
+	if m.currentFile != "old.md" || m.textArea.Value() != "an older note" {
+		t.Fatalf("opened %q with %q", m.currentFile, m.textArea.Value())
+	}
+
+	// Delete
+	m = press(t, m, tea.KeyMsg{Type: tea.KeyEsc}, tea.KeyMsg{Type: tea.KeyCtrlL})
+	m.fileList.Select(indexOf(t, m, "plan.md"))
+	m = press(t, m, runes("d"))
+	if !m.showDeleteConfirm || m.fileToDelete != "plan.md" {
+		t.Fatalf("delete dialog: shown %v for %q", m.showDeleteConfirm, m.fileToDelete)
+	}
+	m = press(t, m, runes("y"))
+	if _, err := vault.Read("plan.md"); !errors.Is(err, fs.ErrNotExist) {
+		t.Fatalf("deleted note still readable: %v", err)
+	}
+	if !strings.HasPrefix(m.statusMessage, "Moved plan.md to the trash") {
+		t.Fatalf("after deleting: status %q", m.statusMessage)
+	}
+	if i := findItem(m, "plan.md"); i >= 0 {
+		t.Fatal("deleted note is still listed")
+	}
+}
+
+// findItem returns the index of a note in the note list, or -1
+func findItem(m Model, filename string) int {
+	for i, item := range m.fileList.Items() {
+		if note, ok := item.(notes.Item); ok && note.Filename() == filename {
+			return i
+		}
+	}
+	return -1
+}
+
+// indexOf returns the index of a note in the note list
+func indexOf(t *testing.T, m Model, filename string) int {
+	t.Helper()
+	i := findItem(m, filename)
+	if i < 0 {
+		t.Fatalf("%s is not in the note list", filename)
+	}
+	return i
+}
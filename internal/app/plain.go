@@ -0,0 +1,300 @@
+package app
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"github.com/charmbracelet/bubbles/list"
+	"github.com/charmbracelet/bubbles/paginator"
+	"github.com/charmbracelet/bubbles/textarea"
+	"github.com/charmbracelet/bubbles/textinput"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/muesli/termenv"
+)
+
+// Plain mode renders every view as left-aligned ASCII text without color,
+// emoji or box drawing, so screen readers can follow it line by line.
+
+// asciiKeys replaces the arrow symbols used in key labels with words
+var asciiKeys = strings.NewReplacer("↑", "Up", "↓", "Down", "←", "Left", "→", "Right")
+
+// usePlainMode strips color and styling from everything lipgloss renders
+func usePlainMode(ti *textinput.Model, ta *textarea.Model, l *list.Model) {
+	lipgloss.SetColorProfile(termenv.Ascii)
+
+	ti.Prompt = "> "
+	ta.Prompt = ""
+	plainList(l)
+}
+
+// plainList replaces the list decorations with ASCII equivalents
+func plainList(l *list.Model) {
+	l.Paginator.Type = paginator.Arabic
+	l.Styles.DividerDot = lipgloss.NewStyle().SetString(" - ")
+
+	// Mark the selected note with ">" instead of a coloured bar
+	marker := lipgloss.NewStyle().
+		Border(lipgloss.Border{Left: ">"}, false, false, false, true).
+		PaddingLeft(1)
+	delegate := list.NewDefaultDelegate()
+	delegate.Styles.SelectedTitle = marker
+	delegate.Styles.SelectedDesc = delegate.Styles.NormalDesc
+	l.SetDelegate(delegate)
+}
+
+// plainStatus prefixes a status message with its type in words
+func plainStatus(msg, statusType string) string {
+	switch statusType {
+	case "error":
+		return "Error: " + msg
+	case "warning":
+		return "Warning: " + msg
+	default:
+		return msg
+	}
+}
+
+// plainHelp lists the bindings of a context one per line
+func plainHelp(keys keyMap, ctx context) []string {
+	var lines []string
+	for _, b := range keys.bindings(ctx) {
+		lines = append(lines, fmt.Sprintf("  %-14s %s", asciiKeys.Replace(b.Help().Key), b.Help().Desc))
+	}
+	return lines
+}
+
+// plainLabel returns the ASCII label of the first key bound to an action
+func plainLabel(keys keyMap, ctx context, act action) string {
+	return asciiKeys.Replace(keys.label(ctx, act))
+}
+
+// renderPlainCapture renders the quick capture screen in plain mode
+func renderPlainCapture(filename string, textArea textarea.Model, keys keyMap, statusMessage string) string {
+	lines := []string{
+		"Capture to " + filepath.ToSlash(filename),
+		"",
+		textArea.View(),
+		fmt.Sprintf("%s: Save and close, %s: Cancel",
+			plainLabel(keys, contextCapture, actionSave),
+			plainLabel(keys, contextCapture, actionCancel)),
+	}
+	if statusMessage != "" {
+		lines = append(lines, plainStatus(statusMessage, "error"))
+	}
+	return strings.Join(lines, "\n")
+}
+
+// plainScreen renders the view for the current mode in plain mode
+func (m Model) plainScreen() string {
+	switch m.screenContext() {
+	case contextLocked:
+		return renderPlainLockedDialog(m.currentFile, m.lockHolder, m.keys)
+	case contextRecover:
+		return renderPlainRecoverDialog(m.currentFile, m.swapDiff, m.swapTime, m.showSwapDiff, m.keys)
+	case contextConflict:
+		return renderPlainConflictDialog(m.currentFile, m.diskDiff, m.diskSealed, m.keys)
+	case contextUnsaved:
+		return renderPlainUnsavedConfirm(m.currentFile, m.keys)
+	case contextVault:
+		return renderPlainVaultSwitcher(m.keys, m.cfg.VaultNames(), m.cfg.Vaults, m.cfg.VaultName, m.vaultCursor)
+	case contextPassphrase:
+		return renderPlainPassphraseDialog(m.passphraseStep, m.passphraseNote(), m.passphraseInput, m.keys, m.statusMessage, m.statusType)
+	case contextImport:
+		return renderPlainImportDialog(m.importPlan, m.importReport, m.folder, m.keys)
+	case contextCreate:
+		return renderPlainCreateNoteDialog(m.newFileInput, m.keys, m.cfg.DefaultExtension, m.folder, m.statusMessage, m.statusType)
+	case contextGitConflict:
+		return renderPlainGitConflicts(m.gitConflicts, m.gitConflictCursor, m.keys)
+	case contextHistory:
+		return renderPlainHistoryView(m.currentFile, m.snapshots, m.historyCursor, m.historyDiff, m.historyScroll, m.windowHeight, m.keys, m.statusMessage, m.statusType)
+	case contextGitLog:
+		return renderPlainGitLogView(m.currentFile, m.gitLog, m.gitLogCursor, m.historyDiff, m.historyScroll, m.windowHeight, m.keys, m.statusMessage, m.statusType)
+	case contextHelp, contextEditor:
+		return renderPlainEditorView(m.currentFile, m.textArea, m.showHelp, m.keys, m.dirty(), m.readOnly, savedAgo(m.lastSaved), m.statusMessage, m.statusType)
+	case contextPurge, contextTrash:
+		return renderPlainTrashView(m.trashList, m.keys, m.showPurgeConfirm, m.statusMessage, m.statusType)
+	case contextRename:
+		return renderPlainRenameDialog(m.fileToRename, m.renameInput, m.keys, m.statusMessage, m.statusType)
+	case contextMove:
+		return renderPlainMoveDialog(m.fileToMove, m.moveInput, m.keys, m.statusMessage, m.statusType)
+	case contextDelete, contextFilter, contextList:
+		return renderPlainFileListView(m.fileList, m.keys, m.showDeleteConfirm, m.fileToDelete, m.statusMessage, m.statusType)
+	}
+	return renderPlainLanding(m.keys)
+}
+
+// renderPlainLanding renders the landing page as a title and a list of shortcuts
+func renderPlainLanding(keys keyMap) string {
+	lines := []string{
+		"TermNote - Your Terminal Note-Taking Companion",
+		"",
+		"Keyboard shortcuts:",
+	}
+	lines = append(lines, plainHelp(keys, contextLanding)...)
+	if keys.leader.Enabled() {
+		lines = append(lines, fmt.Sprintf("  %-14s %s", keys.leader.Help().Key, "Leader chords"))
+	}
+	return strings.Join(lines, "\n")
+}
+
+// renderPlainCreateNoteDialog renders the create note prompt
+func renderPlainCreateNoteDialog(input textinput.Model, keys keyMap, extension, folder string, statusMsg string, statusType string) string {
+	hint := extension + " extension will be added automatically"
+	if extension == "" {
+		hint = "No extension will be added"
+	}
+
+	label := "Note name"
+	if folder != "" {
+		label += " in " + filepath.ToSlash(folder) + "/"
+	}
+
+	lines := []string{
+		"Create new note",
+		"",
+		fmt.Sprintf("%s (%d/%d characters):", label, len(input.Value()), input.CharLimit),
+		input.View(),
+		hint,
+	}
+	if statusMsg != "" {
+		lines = append(lines, "", plainStatus(statusMsg, statusType))
+	}
+	lines = append(lines, "", fmt.Sprintf("Press %s to create or %s to cancel.",
+		plainLabel(keys, contextCreate, actionCreate), plainLabel(keys, contextCreate, actionCancel)))
+
+	return strings.Join(lines, "\n")
+}
+
+// renderPlainDeleteConfirm renders the delete confirmation question
+func renderPlainDeleteConfirm(filename string, keys keyMap) string {
+	return strings.Join([]string{
+		"Delete note: " + filename,
+		"",
+		"Move this note to the trash? It can be restored from the trash view.",
+		fmt.Sprintf("Press %s to delete or %s to keep it.",
+			plainLabel(keys, contextDelete, actionConfirm), plainLabel(keys, contextDelete, actionCancel)),
+	}, "\n")
+}
+
+// renderPlainUnsavedConfirm renders the question about unsaved changes
+func renderPlainUnsavedConfirm(filename string, keys keyMap) string {
+	return strings.Join([]string{
+		"Unsaved changes: " + filename,
+		"",
+		"This note has changes that are not saved. Save them before continuing?",
+		fmt.Sprintf("Press %s to save, %s to discard or %s to cancel.",
+			plainLabel(keys, contextUnsaved, actionSave),
+			plainLabel(keys, contextUnsaved, actionDiscard),
+			plainLabel(keys, contextUnsaved, actionCancel)),
+	}, "\n")
+}
+
+// renderPlainVaultSwitcher renders the vault choices, marking the cursor with ">"
+func renderPlainVaultSwitcher(keys keyMap, names []string, vaults map[string]string, active string, cursor int) string {
+	lines := []string{"Switch vault", ""}
+	for i, name := range names {
+		marker := "  "
+		if i == cursor {
+			marker = "> "
+		}
+		label := name
+		if name == active {
+			label += " (current)"
+		}
+		lines = append(lines, fmt.Sprintf("%s%-24s %s", marker, label, vaults[name]))
+	}
+	lines = append(lines, "")
+	lines = append(lines, plainHelp(keys, contextVault)...)
+	return strings.Join(lines, "\n")
+}
+
+// renderPlainFileListView renders the note list with its status and help lines
+func renderPlainFileListView(fileList list.Model, keys keyMap, showDeleteConfirm bool, fileToDelete string, statusMessage string, statusType string) string {
+	if showDeleteConfirm {
+		return renderPlainDeleteConfirm(fileToDelete, keys)
+	}
+
+	var lines []string
+	if statusMessage != "" {
+		lines = append(lines, plainStatus(statusMessage, statusType), "")
+	}
+
+	if len(fileList.Items()) == 0 {
+		lines = append(lines,
+			"No notes yet.",
+			fmt.Sprintf("Press %s to create your first note.", plainLabel(keys, contextList, actionNewNote)))
+		return strings.Join(lines, "\n")
+	}
+
+	lines = append(lines, fileList.View())
+	if fileList.FilterState() != list.Filtering {
+		lines = append(lines, "", "Keys:")
+		lines = append(lines, plainHelp(keys, contextList)...)
+	}
+	return strings.Join(lines, "\n")
+}
+
+// renderPlainHelp renders the editor shortcuts grouped by section
+func renderPlainHelp(keys keyMap) string {
+	lines := []string{"Keyboard shortcuts"}
+
+	group := ""
+	for _, b := range keys.bindings(contextEditor) {
+		if b.group != group {
+			group = b.group
+			lines = append(lines, "", group)
+		}
+		lines = append(lines, fmt.Sprintf("  %-14s %s", asciiKeys.Replace(b.Help().Key), b.Help().Desc))
+	}
+	if keys.leader.Enabled() {
+		lines = append(lines, "", fmt.Sprintf("  %-14s %s", keys.leader.Help().Key, "Leader chords (pause to list)"))
+	}
+
+	lines = append(lines, "", fmt.Sprintf("Press %s to close.", strings.Join(keys.labels(contextHelp, actionHelp), " or ")))
+	return strings.Join(lines, "\n")
+}
+
+// renderPlainEditorView renders the editor with a plain header and key line
+func renderPlainEditorView(currentFile string, textArea textarea.Model, showHelp bool, keys keyMap, modified, readOnly bool, saved string, statusMessage string, statusType string) string {
+	if showHelp {
+		return renderPlainHelp(keys)
+	}
+
+	header := "Editing: " + filepath.ToSlash(currentFile)
+	if modified {
+		header += " (modified)"
+	}
+	if readOnly {
+		header += " (read-only)"
+	}
+
+	lines := []string{
+		header,
+		"",
+		textArea.View(),
+		"",
+		fmt.Sprintf("%s: Save, %s: Close, %s: Help",
+			plainLabel(keys, contextEditor, actionSave),
+			plainLabel(keys, contextEditor, actionClose),
+			plainLabel(keys, contextEditor, actionHelp)),
+	}
+	if saved != "" {
+		lines = append(lines, strings.ToUpper(saved[:1])+saved[1:])
+	}
+	if statusMessage != "" {
+		lines = append(lines, plainStatus(statusMessage, statusType))
+	}
+	return strings.Join(lines, "\n")
+}
+
+// renderPlainWhichKey lists the keys that can follow the typed leader prefix
+func renderPlainWhichKey(chords []chord, typed []string, leaderLabel string) string {
+	keys, next := chordContinuations(chords, typed)
+
+	lines := []string{strings.TrimSpace(leaderLabel+" "+strings.Join(typed, " ")) + ", then:"}
+	for _, k := range keys {
+		lines = append(lines, fmt.Sprintf("  %-4s %s", k, next[k]))
+	}
+	return strings.Join(lines, "\n")
+}
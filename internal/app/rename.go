@@ -0,0 +1,208 @@
+package app
+
+import (
+	"errors"
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"github.com/charmbracelet/bubbles/textinput"
+	"github.com/charmbracelet/lipgloss"
+
+	"github.com/shalshcode08/Term-Note/internal/notes"
+	"github.com/shalshcode08/Term-Note/internal/ui/styles"
+)
+
+// newRenameInput creates the name prompt of the rename dialog
+func newRenameInput() textinput.Model {
+	ti := textinput.New()
+	ti.Placeholder = "my-renamed-note"
+	ti.CharLimit = 100
+	ti.Width = 56
+	ti.Prompt = ""
+	styleTextInput(&ti)
+	return ti
+}
+
+// openRename shows the dialog for renaming the selected note
+func (m *Model) openRename() {
+	item, ok := m.fileList.SelectedItem().(notes.Item)
+	if !ok {
+		return
+	}
+	if item.IsDir() {
+		m.statusMessage = "Only notes can be renamed, not folders"
+		m.statusType = "warning"
+		return
+	}
+
+	name := filepath.ToSlash(item.Filename())
+	m.fileToRename = item.Filename()
+	m.renameInput.SetValue(strings.TrimSuffix(name, filepath.Ext(name)))
+	m.renameInput.CursorEnd()
+	m.renameInput.Focus()
+	m.showRename = true
+	m.statusMessage = ""
+	m.statusType = ""
+}
+
+// closeRename hides the rename dialog
+func (m *Model) closeRename() {
+	m.showRename = false
+	m.fileToRename = ""
+	m.renameInput.Blur()
+	m.renameInput.SetValue("")
+}
+
+// renameNote renames the note chosen in the rename dialog to the name typed
+// in it, keeping its extension. Folders in the name move the note.
+func (m *Model) renameNote() {
+	from := m.fileToRename
+	name := strings.TrimSpace(m.renameInput.Value())
+	to := filepath.FromSlash(notes.FileName(name, filepath.Ext(from)))
+
+	err := notes.ValidateName(name)
+	if err == nil && to == from {
+		m.closeRename()
+		return
+	}
+	if err == nil {
+		err = m.relocateNote("Renamed", from, to)
+	}
+	var locked *notes.LockedError
+	switch {
+	case errors.Is(err, notes.ErrEmptyName):
+		m.statusMessage = "Please enter a note name"
+		m.statusType = "error"
+	case errors.Is(err, notes.ErrInvalidName):
+		m.statusMessage = "Filename contains invalid characters"
+		m.statusType = "error"
+	case errors.Is(err, notes.ErrExists):
+		m.statusMessage = "File already exists with this name"
+		m.statusType = "error"
+	case errors.As(err, &locked):
+		m.statusMessage = "Cannot rename: " + err.Error()
+		m.statusType = "error"
+	case err != nil:
+		m.statusMessage = fmt.Sprintf("Failed to rename file: %v", err)
+		m.statusType = "error"
+	default:
+		m.closeRename()
+	}
+}
+
+// relocateNote renames or moves a note, taking its history and swap file
+// along and rewriting the links in the vault that point to it. Notes open in
+// another instance are refused with a *notes.LockedError.
+func (m *Model) relocateNote(verb, from, to string) error {
+	if err := notes.CheckLock(m.cfg.LockDir(), from); err != nil {
+		return err
+	}
+	if err := notes.MoveNote(m.vault, from, to); err != nil {
+		return err
+	}
+
+	var problems []string
+	if err := notes.MoveSnapshots(m.cfg.HistoryDir(), from, to); err != nil {
+		problems = append(problems, "its history stayed behind: "+err.Error())
+	}
+	if err := notes.MoveSwap(m.cfg.SwapDir(), from, to); err != nil {
+		problems = append(problems, "its unsaved edits stayed behind: "+err.Error())
+	}
+	changed, err := notes.RewriteLinks(m.vault, from, to)
+	if err != nil {
+		problems = append(problems, "some links were not updated: "+err.Error())
+	}
+	message := fmt.Sprintf("%s %s to %s", verb, filepath.ToSlash(from), filepath.ToSlash(to))
+	m.commitNotes(message, append([]string{from, to}, changed...)...)
+
+	m.statusMessage = fmt.Sprintf("%s %s to %s", verb, from, to)
+	m.statusType = "success"
+	switch len(changed) {
+	case 0:
+	case 1:
+		m.statusMessage += " · updated links in 1 note"
+	default:
+		m.statusMessage += fmt.Sprintf(" · updated links in %d notes", len(changed))
+	}
+	if len(problems) > 0 {
+		m.statusMessage += ", but " + strings.Join(problems, " and ")
+		m.statusType = "warning"
+	}
+	m.refreshList()
+	return nil
+}
+
+// renderRenameDialog renders the dialog for renaming a note
+func renderRenameDialog(filename string, input textinput.Model, keys keyMap, statusMsg string, statusType string) string {
+	title := styles.DialogTitleStyle.Render("✏️  RENAME NOTE")
+	file := lipgloss.NewStyle().
+		Foreground(styles.ColorPrimary).
+		Bold(true).
+		Render(filepath.ToSlash(filename))
+
+	charCount := len(input.Value())
+	maxChars := input.CharLimit
+	counterStyle := lipgloss.NewStyle().Foreground(styles.ColorMuted)
+	if charCount > maxChars-10 {
+		counterStyle = counterStyle.Foreground(styles.ColorWarning)
+	}
+	labelWithCounter := lipgloss.JoinHorizontal(
+		lipgloss.Left,
+		styles.InputLabelStyle.Render("New Name:"),
+		counterStyle.Render(fmt.Sprintf("  %d/%d", charCount, maxChars)),
+	)
+
+	promptSymbol := lipgloss.NewStyle().
+		Foreground(styles.ColorPrimary).
+		Bold(true).
+		Render("› ")
+	inputBox := styles.InputBoxStyle.Render(lipgloss.JoinHorizontal(lipgloss.Left, promptSymbol, input.View()))
+
+	hint := "The extension is kept; links to this note are updated"
+	if ext := filepath.Ext(filename); ext != "" {
+		hint = ext + " extension is kept; links to this note are updated"
+	}
+	extensionHint := styles.FileExtensionStyle.Render(hint)
+
+	statusLine := styles.InputTipStyle.Render("💡 Tip: Add folders to move it too, like 'archive/old-plan'")
+	if statusMsg != "" {
+		statusLine = statusStyle(statusType).Render(statusMsg)
+	}
+
+	helpText := styles.InputHelpStyle.Render(fmt.Sprintf("⏎ %s to rename  •  %s to cancel",
+		keys.label(contextRename, actionConfirm), keys.label(contextRename, actionCancel)))
+
+	content := lipgloss.JoinVertical(
+		lipgloss.Left,
+		title,
+		"",
+		file,
+		"",
+		labelWithCounter,
+		inputBox,
+		extensionHint,
+		"",
+		statusLine,
+		"",
+		helpText,
+	)
+	return styles.DialogBoxStyle.Render(content)
+}
+
+// renderPlainRenameDialog renders the rename prompt in plain mode
+func renderPlainRenameDialog(filename string, input textinput.Model, keys keyMap, statusMsg string, statusType string) string {
+	lines := []string{
+		"Rename note: " + filepath.ToSlash(filename),
+		"",
+		fmt.Sprintf("New name (%d/%d characters):", len(input.Value()), input.CharLimit),
+		input.View(),
+		"The extension is kept and links to this note are updated.",
+	}
+	if statusMsg != "" {
+		lines = append(lines, "", plainStatus(statusMsg, statusType))
+	}
+	lines = append(lines, "", fmt.Sprintf("Press %s to rename or %s to cancel.",
+		plainLabel(keys, contextRename, actionConfirm), plainLabel(keys, contextRename, actionCancel)))
+	return strings.Join(lines, "\n")
+}
@@ -0,0 +1,215 @@
+package app
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"os/signal"
+	"strings"
+	"syscall"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+
+	"github.com/shalshcode08/Term-Note/internal/notes"
+	"github.com/shalshcode08/Term-Note/internal/ui/styles"
+)
+
+// signalMsg reports that the process was asked to stop
+type signalMsg struct {
+	sig os.Signal
+}
+
+// WatchSignals forwards SIGINT, SIGTERM and SIGHUP to the program so unsaved
+// edits reach the swap file before it exits. The program must be created
+// with tea.WithoutSignalHandler.
+func WatchSignals(p *tea.Program) {
+	sigs := make(chan os.Signal, 1)
+	signal.Notify(sigs, syscall.SIGINT, syscall.SIGTERM, syscall.SIGHUP)
+	go func() {
+		sig := <-sigs
+		signal.Stop(sigs)
+		p.Send(signalMsg{sig: sig})
+	}()
+}
+
+// updateSwap keeps the swap file in step with the editor: it holds the
+// content while there are unsaved edits and is removed once they are saved
+func (m *Model) updateSwap() {
+	if m.lockedOut() {
+		// The swap file belongs to the instance holding the lock
+		return
+	}
+	if m.noteKey != nil {
+		// Edits to encrypted notes never reach the disk in plain text
+		return
+	}
+	if !m.dirty() {
+		m.dropSwap()
+		return
+	}
+
+	value := m.textArea.Value()
+	if m.swapWritten && value == m.lastSwapped {
+		return
+	}
+	if err := notes.WriteSwap(m.cfg.SwapDir(), m.currentFile, value); err != nil {
+		m.statusMessage = fmt.Sprintf("Cannot write swap file: %v", err)
+		m.statusType = "error"
+		return
+	}
+	m.swapWritten = true
+	m.lastSwapped = value
+}
+
+// dropSwap removes the swap file written for the open note
+func (m *Model) dropSwap() {
+	if !m.swapWritten {
+		return
+	}
+	if err := notes.RemoveSwap(m.cfg.SwapDir(), m.currentFile); err != nil {
+		m.statusMessage = err.Error()
+		m.statusType = "error"
+		return
+	}
+	m.swapWritten = false
+	m.lastSwapped = ""
+}
+
+// checkSwap looks for edits to the note that were never saved, e.g. because
+// the terminal was closed, and offers to recover them
+func (m *Model) checkSwap() {
+	content, modified, err := notes.ReadSwap(m.cfg.SwapDir(), m.currentFile)
+	if errors.Is(err, os.ErrNotExist) {
+		return
+	}
+	if err != nil {
+		m.statusMessage = fmt.Sprintf("Cannot read swap file: %v", err)
+		m.statusType = "error"
+		return
+	}
+
+	// Nothing to recover if the note already has the same content
+	if content == m.savedContent {
+		notes.RemoveSwap(m.cfg.SwapDir(), m.currentFile)
+		return
+	}
+
+	m.showRecover = true
+	m.showSwapDiff = false
+	m.swapContent = content
+	m.swapDiff = notes.Diff(m.savedContent, content)
+	m.swapTime = modified
+}
+
+// recoverSwap loads the swap file content into the editor as unsaved edits
+func (m *Model) recoverSwap() {
+	m.showRecover = false
+	m.textArea.SetValue(m.swapContent)
+	m.swapWritten = true
+	m.lastSwapped = m.swapContent
+	m.statusMessage = "Recovered unsaved changes, save to keep them"
+	m.statusType = "success"
+}
+
+// deleteSwap throws away the swap file found when the note was opened
+func (m *Model) deleteSwap() {
+	m.showRecover = false
+	if err := notes.RemoveSwap(m.cfg.SwapDir(), m.currentFile); err != nil {
+		m.statusMessage = err.Error()
+		m.statusType = "error"
+	}
+}
+
+// renderRecoverDialog renders the choices for a swap file found when opening a note
+func renderRecoverDialog(filename string, diff []notes.DiffLine, swapTime time.Time, showDiff bool, keys keyMap) string {
+	dialogStyle := lipgloss.NewStyle().
+		Border(lipgloss.RoundedBorder()).
+		BorderForeground(styles.ColorWarning).
+		Padding(1, 4).
+		Width(72)
+
+	titleStyle := lipgloss.NewStyle().
+		Foreground(styles.ColorWarning).
+		Bold(true).
+		Align(lipgloss.Center).
+		Width(64)
+
+	filenameStyle := lipgloss.NewStyle().
+		Foreground(styles.ColorPrimary).
+		Bold(true).
+		Align(lipgloss.Center).
+		Width(64)
+
+	messageStyle := lipgloss.NewStyle().
+		Foreground(styles.ColorMuted).
+		Align(lipgloss.Center).
+		Width(64).
+		MarginTop(1)
+
+	buttonsStyle := lipgloss.NewStyle().
+		Align(lipgloss.Center).
+		Width(64).
+		MarginTop(1)
+
+	added, removed := notes.DiffStats(diff)
+
+	title := titleStyle.Render("⚠️  UNSAVED CHANGES FOUND")
+	file := filenameStyle.Render(filename)
+	message := messageStyle.Render(fmt.Sprintf(
+		"A swap file from %s holds edits that were never saved\n(+%d -%d lines compared to the note).",
+		notes.FormatRelativeTime(swapTime), added, removed))
+
+	var preview string
+	if showDiff {
+		preview = lipgloss.NewStyle().
+			MarginTop(1).
+			Width(64).
+			Render(renderDiffPreview(diff, 12))
+	}
+
+	button := func(label string, act action, color lipgloss.Color) string {
+		return lipgloss.NewStyle().
+			Foreground(styles.ColorBg).
+			Background(color).
+			Bold(true).
+			Render(fmt.Sprintf(" %s (%s) ", label, keys.label(contextRecover, act)))
+	}
+	buttons := buttonsStyle.Render(strings.Join([]string{
+		button("Recover", actionRecover, styles.ColorSuccess),
+		button("Diff", actionShowDiff, styles.ColorSecondary),
+		button("Delete swap", actionDiscard, styles.ColorError),
+		button("Ignore", actionCancel, styles.ColorMuted),
+	}, " "))
+
+	parts := []string{title, "", file, message}
+	if preview != "" {
+		parts = append(parts, preview)
+	}
+	content := lipgloss.JoinVertical(lipgloss.Left, append(parts, buttons)...)
+
+	return dialogStyle.Render(content)
+}
+
+// renderPlainRecoverDialog renders the choices for a swap file in plain mode
+func renderPlainRecoverDialog(filename string, diff []notes.DiffLine, swapTime time.Time, showDiff bool, keys keyMap) string {
+	added, removed := notes.DiffStats(diff)
+
+	lines := []string{
+		"Unsaved changes found: " + filename,
+		"",
+		fmt.Sprintf("A swap file from %s holds edits that were never saved (%d lines added, %d removed compared to the note).",
+			notes.FormatRelativeTime(swapTime), added, removed),
+	}
+	if showDiff {
+		lines = append(lines, "", renderDiffPreview(diff, 12))
+	}
+	lines = append(lines, "", fmt.Sprintf("Press %s to recover, %s to show the differences, %s to delete the swap file or %s to ignore it.",
+		plainLabel(keys, contextRecover, actionRecover),
+		plainLabel(keys, contextRecover, actionShowDiff),
+		plainLabel(keys, contextRecover, actionDiscard),
+		plainLabel(keys, contextRecover, actionCancel)))
+
+	return strings.Join(lines, "\n")
+}
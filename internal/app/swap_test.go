@@ -0,0 +1,127 @@
+package app
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+
+	"github.com/shalshcode08/Term-Note/internal/notes"
+)
+
+// swapContent returns the swap file of a note, or "" and false if there is none
+func swapContent(t *testing.T, m Model, filename string) (string, bool) {
+	t.Helper()
+	content, _, err := notes.ReadSwap(m.cfg.SwapDir(), filename)
+	if errors.Is(err, os.ErrNotExist) {
+		return "", false
+	}
+	if err != nil {
+		t.Fatal(err)
+	}
+	return content, true
+}
+
+func TestSwapFollowsEdits(t *testing.T) {
+	m, _ := newTestModel(t, map[string]string{"plan.md": "saved"})
+	if err := m.OpenNote("plan.md"); err != nil {
+		t.Fatal(err)
+	}
+
+	m.updateSwap()
+	if _, ok := swapContent(t, m, "plan.md"); ok {
+		t.Fatal("swap file written without unsaved edits")
+	}
+
+	m.textArea.SetValue("edited")
+	m.updateSwap()
+	if content, ok := swapContent(t, m, "plan.md"); !ok || content != "edited" {
+		t.Fatalf("swap file = %q, %v, want the unsaved edits", content, ok)
+	}
+
+	if err := m.saveNote(); err != nil {
+		t.Fatal(err)
+	}
+	m.updateSwap()
+	if _, ok := swapContent(t, m, "plan.md"); ok {
+		t.Error("swap file left behind after saving")
+	}
+}
+
+func TestSwapRecovery(t *testing.T) {
+	tests := []struct {
+		name     string
+		swap     string
+		key      string
+		dialog   bool
+		value    string
+		keepSwap bool
+	}{
+		{"recover", "lost edits", "r", true, "lost edits", true},
+		{"delete swap", "lost edits", "d", true, "saved", false},
+		{"ignore", "lost edits", "esc", true, "saved", true},
+		{"same as the note", "saved", "", false, "saved", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m, _ := newTestModel(t, map[string]string{"plan.md": "saved"})
+			// Left behind by an instance that was killed
+			if err := notes.WriteSwap(m.cfg.SwapDir(), "plan.md", tt.swap); err != nil {
+				t.Fatal(err)
+			}
+
+			if err := m.OpenNote("plan.md"); err != nil {
+				t.Fatal(err)
+			}
+			if m.showRecover != tt.dialog {
+				t.Fatalf("showRecover = %v, want %v", m.showRecover, tt.dialog)
+			}
+			switch tt.key {
+			case "":
+			case "esc":
+				m = press(t, m, tea.KeyMsg{Type: tea.KeyEsc})
+			default:
+				m = press(t, m, runes(tt.key))
+			}
+
+			if m.showRecover {
+				t.Error("recover dialog still open")
+			}
+			if got := m.textArea.Value(); got != tt.value {
+				t.Errorf("editor holds %q, want %q", got, tt.value)
+			}
+			if _, ok := swapContent(t, m, "plan.md"); ok != tt.keepSwap {
+				t.Errorf("swap file kept = %v, want %v", ok, tt.keepSwap)
+			}
+		})
+	}
+}
+
+func TestRenameMovesSwap(t *testing.T) {
+	m, _ := newTestModel(t, map[string]string{"plan.md": "saved"})
+	if err := notes.WriteSwap(m.cfg.SwapDir(), "plan.md", "lost edits"); err != nil {
+		t.Fatal(err)
+	}
+
+	to := filepath.Join("work", "plan.md")
+	if err := m.relocateNote("Renamed", "plan.md", to); err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := swapContent(t, m, "plan.md"); ok {
+		t.Error("swap file of the old name left behind")
+	}
+	if content, ok := swapContent(t, m, to); !ok || content != "lost edits" {
+		t.Fatalf("swap file of the new name = %q, %v", content, ok)
+	}
+
+	// The edits are offered when the note is opened under its new name
+	if err := m.OpenNote(to); err != nil {
+		t.Fatal(err)
+	}
+	if !m.showRecover {
+		t.Error("recover dialog not shown for the renamed note")
+	}
+}
@@ -0,0 +1,296 @@
+package app
+
+import (
+	"errors"
+	"fmt"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/charmbracelet/bubbles/list"
+	"github.com/charmbracelet/lipgloss"
+
+	"github.com/shalshcode08/Term-Note/internal/notes"
+	"github.com/shalshcode08/Term-Note/internal/ui/styles"
+)
+
+// undoTimeout is how long the undo toast stays up after a delete
+const undoTimeout = 10 * time.Second
+
+// trashEntry shows a trashed note in the trash list
+type trashEntry struct {
+	notes.TrashItem
+}
+
+func (t trashEntry) Title() string       { return t.Path }
+func (t trashEntry) Description() string { return "Deleted " + notes.FormatRelativeTime(t.Deleted) }
+func (t trashEntry) FilterValue() string { return t.Path }
+
+// newTrashList creates the list shown in the trash view
+func newTrashList() list.Model {
+	l := list.New(nil, list.NewDefaultDelegate(), 0, 0)
+	l.Title = "Trash"
+	styleList(&l)
+	l.SetShowStatusBar(true)
+	l.SetFilteringEnabled(false)
+	l.SetStatusBarItemName("note", "notes")
+	l.SetShowHelp(false)
+	return l
+}
+
+// trashNote moves the note chosen in the delete dialog to the trash and
+// offers to undo it for a few seconds
+func (m *Model) trashNote() {
+	item, err := notes.Trash(m.vault, m.fileToDelete, time.Now())
+	if err != nil {
+		m.statusMessage = fmt.Sprintf("Failed to delete note: %v", err)
+		m.statusType = "error"
+		return
+	}
+
+	m.lastTrashed = item
+	m.undoUntil = time.Now().Add(undoTimeout)
+	m.statusMessage = fmt.Sprintf("Moved %s to the trash · %s to undo", item.Path, m.keys.label(contextList, actionUndo))
+	m.statusType = "success"
+	m.commitNotes("Delete "+filepath.ToSlash(item.Path), item.Path)
+	m.refreshList()
+}
+
+// undoDelete puts the last deleted note back while the undo toast is up
+func (m *Model) undoDelete() {
+	if m.lastTrashed.Name == "" {
+		m.statusMessage = "Nothing to undo"
+		m.statusType = "warning"
+		return
+	}
+
+	item := m.lastTrashed
+	m.lastTrashed = notes.TrashItem{}
+	m.undoUntil = time.Time{}
+	m.restoreTrashItem(item)
+}
+
+// expireUndo takes the undo toast down once its time is up
+func (m *Model) expireUndo(now time.Time) {
+	if m.lastTrashed.Name == "" || now.Before(m.undoUntil) {
+		return
+	}
+	if strings.HasPrefix(m.statusMessage, "Moved "+m.lastTrashed.Path+" to the trash") {
+		m.statusMessage = ""
+		m.statusType = ""
+	}
+	m.lastTrashed = notes.TrashItem{}
+	m.undoUntil = time.Time{}
+}
+
+// openTrash shows the trash view, after purging notes past their time
+func (m *Model) openTrash() {
+	m.purgeOldTrash()
+	m.refreshTrash()
+	m.trashList.ResetSelected()
+	m.showTrash = true
+}
+
+// refreshTrash reloads the trash list from disk
+func (m *Model) refreshTrash() {
+	trashed, err := notes.ListTrash(m.vault)
+	if err != nil {
+		m.statusMessage = err.Error()
+		m.statusType = "error"
+	}
+	items := make([]list.Item, 0, len(trashed))
+	for _, item := range trashed {
+		items = append(items, trashEntry{item})
+	}
+	m.trashList.SetItems(items)
+}
+
+// purgeOldTrash deletes the notes that have been in the trash too long
+func (m *Model) purgeOldTrash() {
+	if _, err := notes.PurgeOldTrash(m.vault, m.cfg.Trash.PurgeAfter, time.Now()); err != nil {
+		m.statusMessage = err.Error()
+		m.statusType = "error"
+	}
+}
+
+// restoreTrashed restores the note selected in the trash view
+func (m *Model) restoreTrashed() {
+	entry, ok := m.trashList.SelectedItem().(trashEntry)
+	if !ok {
+		return
+	}
+	m.restoreTrashItem(entry.TrashItem)
+	m.refreshTrash()
+}
+
+// restoreTrashItem moves a note back from the trash and refreshes the note list
+func (m *Model) restoreTrashItem(item notes.TrashItem) {
+	err := notes.RestoreTrash(m.vault, item)
+	switch {
+	case errors.Is(err, notes.ErrExists):
+		m.statusMessage = "File already exists with this name: " + item.Path
+		m.statusType = "error"
+		return
+	case err != nil:
+		m.statusMessage = err.Error()
+		m.statusType = "error"
+		return
+	}
+
+	m.statusMessage = "Restored " + item.Path
+	m.statusType = "success"
+	m.commitNotes("Restore "+filepath.ToSlash(item.Path), item.Path)
+	m.refreshList()
+}
+
+// purgeTrashed deletes the note selected in the trash view for good
+func (m *Model) purgeTrashed() {
+	m.showPurgeConfirm = false
+	entry, ok := m.trashList.SelectedItem().(trashEntry)
+	if !ok {
+		return
+	}
+	if err := notes.PurgeTrash(m.vault, entry.TrashItem); err != nil {
+		m.statusMessage = err.Error()
+		m.statusType = "error"
+		return
+	}
+	m.statusMessage = "Deleted " + entry.Path + " for good"
+	m.statusType = "success"
+	m.refreshTrash()
+}
+
+// renderTrashView renders the trash list with its status and purge dialog
+func renderTrashView(trashList list.Model, keys keyMap, showPurgeConfirm bool, statusMessage, statusType string, windowWidth, windowHeight int) string {
+	if showPurgeConfirm {
+		name := ""
+		if entry, ok := trashList.SelectedItem().(trashEntry); ok {
+			name = entry.Path
+		}
+		return lipgloss.Place(
+			windowWidth, windowHeight,
+			lipgloss.Center, lipgloss.Center,
+			renderPurgeConfirm(name, keys),
+		)
+	}
+
+	var view string
+	if len(trashList.Items()) == 0 {
+		header := styles.ListTitleStyle.Render("🗑  Trash")
+		emptyState := lipgloss.NewStyle().
+			Foreground(styles.ColorMuted).
+			Align(lipgloss.Center).
+			Padding(10, 2).
+			Render(fmt.Sprintf("The trash is empty\n\nPress %s to go back to your notes", keys.label(contextTrash, actionBack)))
+		view = lipgloss.JoinVertical(lipgloss.Left, header, emptyState)
+	} else {
+		helpText := lipgloss.NewStyle().
+			Foreground(styles.ColorMuted).
+			Padding(1, 2).
+			Render(shortHelp(keys, contextTrash))
+		view = lipgloss.JoinVertical(lipgloss.Left, trashList.View(), helpText)
+	}
+
+	if statusMessage != "" {
+		statusBar := lipgloss.NewStyle().
+			Padding(0, 2).
+			Render(statusStyle(statusType).Render(statusMessage))
+		return lipgloss.JoinVertical(lipgloss.Left, statusBar, view)
+	}
+	return view
+}
+
+// renderPurgeConfirm renders the confirmation for deleting a note for good
+func renderPurgeConfirm(filename string, keys keyMap) string {
+	dialogStyle := lipgloss.NewStyle().
+		Border(lipgloss.RoundedBorder()).
+		BorderForeground(styles.ColorError).
+		Padding(2, 4).
+		Width(60)
+
+	titleStyle := lipgloss.NewStyle().
+		Foreground(styles.ColorError).
+		Bold(true).
+		Align(lipgloss.Center).
+		Width(52)
+
+	filenameStyle := lipgloss.NewStyle().
+		Foreground(styles.ColorPrimary).
+		Bold(true).
+		Align(lipgloss.Center).
+		Width(52)
+
+	messageStyle := lipgloss.NewStyle().
+		Foreground(styles.ColorMuted).
+		Align(lipgloss.Center).
+		Width(52).
+		MarginTop(1)
+
+	buttonsStyle := lipgloss.NewStyle().
+		Align(lipgloss.Center).
+		Width(52).
+		MarginTop(2)
+
+	title := titleStyle.Render("⚠️  PURGE NOTE")
+	file := filenameStyle.Render(filename)
+	message := messageStyle.Render("Delete this note from the trash for good?\nThis action cannot be undone.")
+
+	yesButton := lipgloss.NewStyle().
+		Foreground(styles.ColorBg).
+		Background(styles.ColorError).
+		Bold(true).
+		Padding(0, 2).
+		Render(fmt.Sprintf(" Purge (%s) ", keys.label(contextPurge, actionConfirm)))
+
+	noButton := lipgloss.NewStyle().
+		Foreground(styles.ColorText).
+		Background(styles.ColorMuted).
+		Bold(true).
+		Padding(0, 2).
+		Render(fmt.Sprintf(" Keep (%s) ", keys.label(contextPurge, actionCancel)))
+
+	buttons := buttonsStyle.Render(yesButton + "  " + noButton)
+
+	content := lipgloss.JoinVertical(
+		lipgloss.Left,
+		title,
+		"",
+		file,
+		message,
+		buttons,
+	)
+
+	return dialogStyle.Render(content)
+}
+
+// renderPlainTrashView renders the trash view in plain mode
+func renderPlainTrashView(trashList list.Model, keys keyMap, showPurgeConfirm bool, statusMessage, statusType string) string {
+	if showPurgeConfirm {
+		name := ""
+		if entry, ok := trashList.SelectedItem().(trashEntry); ok {
+			name = entry.Path
+		}
+		return strings.Join([]string{
+			"Purge note: " + name,
+			"",
+			"Delete this note from the trash for good? This action cannot be undone.",
+			fmt.Sprintf("Press %s to purge or %s to keep it.",
+				plainLabel(keys, contextPurge, actionConfirm), plainLabel(keys, contextPurge, actionCancel)),
+		}, "\n")
+	}
+
+	var lines []string
+	if statusMessage != "" {
+		lines = append(lines, plainStatus(statusMessage, statusType), "")
+	}
+	if len(trashList.Items()) == 0 {
+		lines = append(lines,
+			"The trash is empty.",
+			fmt.Sprintf("Press %s to go back to your notes.", plainLabel(keys, contextTrash, actionBack)))
+		return strings.Join(lines, "\n")
+	}
+
+	lines = append(lines, trashList.View(), "", "Keys:")
+	lines = append(lines, plainHelp(keys, contextTrash)...)
+	return strings.Join(lines, "\n")
+}
@@ -1,21 +1,33 @@
 package app
 
 import (
+	"errors"
 	"fmt"
-	"os"
+	"path/filepath"
 	"strings"
+	"time"
 
-	"github.com/charmbracelet/bubbles/list"
+	"github.com/charmbracelet/bubbles/key"
 	tea "github.com/charmbracelet/bubbletea"
 
-	"github.com/shalshcode08/Term-Note/internal/config"
 	"github.com/shalshcode08/Term-Note/internal/notes"
 )
 
-// Update handles messages and updates the model (Bubble Tea interface)
+// Update handles messages and updates the model (Bubble Tea interface).
+// Git work queued while handling the message is started afterwards.
 func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
-	var cmd tea.Cmd
+	next, cmd := m.update(msg)
+	if nm, ok := next.(Model); ok {
+		if git := nm.startGit(); git != nil {
+			return nm, tea.Batch(cmd, git)
+		}
+		return nm, cmd
+	}
+	return next, cmd
+}
 
+// update handles a message
+func (m Model) update(msg tea.Msg) (tea.Model, tea.Cmd) {
 	switch msg := msg.(type) {
 	case tea.WindowSizeMsg:
 		// Store window dimensions for centering dialogs
@@ -24,317 +36,774 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 
 		h, v := DocStyle.GetFrameSize()
 		m.fileList.SetSize(msg.Width-h, msg.Height-v)
+		m.trashList.SetSize(msg.Width-h, msg.Height-v)
 		// Resize textarea for editor view - use full window
 		m.textArea.SetWidth(msg.Width)
 		m.textArea.SetHeight(msg.Height - 4) // Leave space for header and status bar
 
-	case tea.KeyMsg:
-		switch msg.String() {
-		case "ctrl+c", "q":
+	case editorTickMsg:
+		return m.handleEditorTick(time.Time(msg))
+
+	case signalMsg:
+		// Keep unsaved edits in the swap file, then stop
+		if m.currentFile != "" && !m.showRecover {
+			m.updateSwap()
+		}
+		m.recordAutosaves()
+		m.unlockNote()
+		if m.gitRunning || len(m.commits) > 0 {
+			// Let the queued commits finish, but not for long: the
+			// terminal may already be gone
+			m.quitting = true
+			m.statusMessage = "Waiting for git to finish..."
+			m.statusType = ""
+			return m, tea.Tick(gitQuitTimeout, func(time.Time) tea.Msg {
+				return gitQuitTimeoutMsg{}
+			})
+		}
+		return m, tea.Quit
+
+	case gitQuitTimeoutMsg:
+		return m, tea.Quit
+
+	case tea.BlurMsg:
+		// Save when the terminal loses focus
+		m.autosave()
+		return m, nil
+
+	case gitCommitMsg:
+		m.finishCommits(msg)
+		if m.quitting && len(m.commits) == 0 {
 			return m, tea.Quit
+		}
+		return m, nil
 
-		case "ctrl+n":
-			m.createFileInputVisible = true
-			m.statusMessage = ""
+	case gitSyncMsg:
+		m.finishSync(msg.err)
+		if m.quitting && len(m.commits) == 0 {
+			return m, tea.Quit
+		}
+		return m, nil
+
+	case vaultChangedMsg:
+		if msg.watcher != m.watcher {
+			return m, nil
+		}
+		m.handleVaultChange(msg.paths)
+		return m, waitForChanges(m.watcher)
+
+	case whichKeyMsg:
+		if m.chordPending && msg.seq == m.chordSeq {
+			m.showWhichKey = true
+		}
+		return m, nil
+
+	case tea.KeyMsg:
+		ctx := m.activeContext()
+		if ctx == contextEditor && m.lockedOut() {
+			return m.readOnlyKey(ctx, msg)
+		}
+		return m.handleKey(ctx, msg)
+	}
+
+	// Other messages (cursor blink, filter results, ...) go to every visible component
+	var cmds []tea.Cmd
+	var cmd tea.Cmd
+	if m.createFileInputVisible {
+		m.newFileInput, cmd = m.newFileInput.Update(msg)
+		cmds = append(cmds, cmd)
+	}
+	if m.currentFile != "" {
+		m.textArea, cmd = m.textArea.Update(msg)
+		cmds = append(cmds, cmd)
+	}
+	if m.showingList {
+		m.fileList, cmd = m.fileList.Update(msg)
+		cmds = append(cmds, cmd)
+	}
+	if m.showTrash {
+		m.trashList, cmd = m.trashList.Update(msg)
+		cmds = append(cmds, cmd)
+	}
+	if m.showCommand {
+		m.commandInput, cmd = m.commandInput.Update(msg)
+		cmds = append(cmds, cmd)
+	}
+
+	return m, tea.Batch(cmds...)
+}
+
+// handleKey runs the binding, chord or focused component a key belongs to
+func (m Model) handleKey(ctx context, msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	if m.chordPending {
+		return m.continueChord(ctx, msg)
+	}
+	if len(m.keys.chords[ctx]) > 0 && key.Matches(msg, m.keys.leader) {
+		return m.startChord()
+	}
+	if act, ok := m.keys.lookup(ctx, msg); ok {
+		return m.perform(ctx, act, msg)
+	}
+	return m.forwardKey(ctx, msg)
+}
+
+// forwardKey passes a key without a binding to the focused component of the context
+func (m Model) forwardKey(ctx context, msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	var cmd tea.Cmd
+
+	switch ctx {
+	case contextCreate:
+		m.newFileInput, cmd = m.newFileInput.Update(msg)
+	case contextEditor:
+		m.textArea, cmd = m.textArea.Update(msg)
+	case contextList, contextFilter:
+		m.fileList, cmd = m.fileList.Update(msg)
+	case contextTrash:
+		m.trashList, cmd = m.trashList.Update(msg)
+	case contextCommand:
+		m.commandInput, cmd = m.commandInput.Update(msg)
+	case contextMove:
+		m.moveInput, cmd = m.moveInput.Update(msg)
+	case contextRename:
+		m.renameInput, cmd = m.renameInput.Update(msg)
+	case contextPassphrase:
+		m.passphraseInput, cmd = m.passphraseInput.Update(msg)
+	}
+
+	return m, cmd
+}
+
+// perform runs the action bound to a key in the given context
+func (m Model) perform(ctx context, act action, msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch act {
+	case actionQuit:
+		if m.confirmUnsaved(actionQuit) {
+			return m, nil
+		}
+		m.recordAutosaves()
+		m.dropSwap()
+		m.unlockNote()
+		if m.gitRunning || len(m.commits) > 0 {
+			// Quit once the commits are made
+			m.quitting = true
+			m.statusMessage = "Waiting for git to finish..."
 			m.statusType = ""
-			m.newFileInput.SetValue("")
 			return m, nil
+		}
+		return m, tea.Quit
+
+	case actionNewNote:
+		m.createFileInputVisible = true
+		m.statusMessage = ""
+		m.statusType = ""
+		m.newFileInput.SetValue("")
+
+	case actionListNotes:
+		m.statusMessage = ""
+		m.statusType = ""
+		m.refreshList()
+		m.showingList = true
+
+	case actionSwitchVault:
+		m.showVaultSwitcher = true
+		m.vaultCursor = 0
+		for i, name := range m.cfg.VaultNames() {
+			if name == m.cfg.VaultName {
+				m.vaultCursor = i
+			}
+		}
 
-		case "ctrl+l":
-			notesList := notes.ListFiles(config.VaultDir)
-			m.fileList.SetItems(notesList)
-			m.showingList = true
+	case actionBack:
+		if ctx == contextTrash {
+			m.showTrash = false
 			m.statusMessage = ""
 			m.statusType = ""
-			return m, nil
+		} else {
+			m.showingList = false
+		}
 
-		case "d", "delete":
-			// Delete note - only works in list view
-			if m.showingList && !m.showDeleteConfirm {
-				selectedItem, ok := m.fileList.SelectedItem().(notes.Item)
-				if ok {
-					m.fileToDelete = selectedItem.Filename()
-					m.showDeleteConfirm = true
-				}
+	case actionOpen:
+		if ctx == contextGitConflict {
+			m.openConflict()
+			break
+		}
+		selectedItem, ok := m.fileList.SelectedItem().(notes.Item)
+		if ok && selectedItem.IsDir() {
+			m.openFolder(selectedItem.Filename())
+		} else if ok {
+			if err := m.OpenNote(selectedItem.Filename()); err != nil {
+				m.statusMessage = fmt.Sprintf("Cannot read the file: %v", err)
+				m.statusType = "error"
 			}
-			return m, nil
+		}
 
-		case "y":
-			// Confirm delete
-			if m.showDeleteConfirm {
-				filePath := fmt.Sprintf("%s/%s", config.VaultDir, m.fileToDelete)
-				if err := os.Remove(filePath); err != nil {
-					m.statusMessage = "Failed to delete note"
-					m.statusType = "error"
-				} else {
-					m.statusMessage = "Note deleted successfully"
-					m.statusType = "success"
-					// Refresh the list
-					notesList := notes.ListFiles(config.VaultDir)
-					m.fileList.SetItems(notesList)
-				}
-				m.showDeleteConfirm = false
-				m.fileToDelete = ""
-			}
-			return m, nil
+	case actionParent:
+		m.parentFolder()
 
-		case "n":
-			// Cancel delete
-			if m.showDeleteConfirm {
-				m.showDeleteConfirm = false
-				m.fileToDelete = ""
-			}
+	case actionMove:
+		m.openMove()
+
+	case actionRename:
+		m.openRename()
+
+	case actionSync:
+		m.syncVault()
+
+	case actionAbortMerge:
+		m.abortMerge()
+
+	case actionDelete:
+		selectedItem, ok := m.fileList.SelectedItem().(notes.Item)
+		if ok && selectedItem.IsDir() {
+			m.statusMessage = "Only notes can be deleted, not folders"
+			m.statusType = "warning"
+		} else if ok {
+			m.fileToDelete = selectedItem.Filename()
+			m.showDeleteConfirm = true
+		}
+
+	case actionConfirm:
+		if ctx == contextPurge {
+			m.purgeTrashed()
+		} else if ctx == contextMove {
+			m.moveNote()
+		} else if ctx == contextRename {
+			m.renameNote()
+		} else if ctx == contextPassphrase {
+			m.submitPassphrase()
+		} else if ctx == contextImport {
+			m.importNotes()
+		} else {
+			m.trashNote()
+			m.showDeleteConfirm = false
+			m.fileToDelete = ""
+		}
+
+	case actionUndo:
+		m.undoDelete()
+
+	case actionTrash:
+		m.openTrash()
+
+	case actionPurge:
+		if _, ok := m.trashList.SelectedItem().(trashEntry); ok {
+			m.showPurgeConfirm = true
+		}
+
+	case actionCancel:
+		switch ctx {
+		case contextDelete:
+			m.showDeleteConfirm = false
+			m.fileToDelete = ""
+		case contextCreate:
+			m.createFileInputVisible = false
+			m.statusMessage = ""
+			m.statusType = ""
+			m.newFileInput.SetValue("")
+		case contextCommand:
+			m.showCommand = false
+			m.commandInput.Blur()
+		case contextUnsaved:
+			m.showUnsaved = false
+			m.unsavedNext = actionNone
+		case contextPurge:
+			m.showPurgeConfirm = false
+		case contextMove:
+			m.closeMove()
+			m.statusMessage = ""
+			m.statusType = ""
+		case contextRename:
+			m.closeRename()
+			m.statusMessage = ""
+			m.statusType = ""
+		case contextHistory:
+			m.showHistory = false
+		case contextGitLog:
+			m.showGitLog = false
+		case contextPassphrase:
+			m.closePassphrase()
+			m.statusMessage = ""
+			m.statusType = ""
+		case contextImport:
+			m.closeImport()
+		case contextGitConflict:
+			// Conflicts stay in the notes until resolved or the merge is aborted
+			m.showGitConflicts = false
+		case contextLocked:
+			// Leave the note to the instance that has it open
+			m.closeNote()
+			m.showingList = true
+			m.refreshList()
+		case contextRecover:
+			// Open the note as it is and leave the swap file alone
+			m.showRecover = false
+		case contextConflict:
+			// Keep editing; saving later asks again
+			m.showConflict = false
+			m.dismissedDisk = m.diskContent
+		}
+
+	case actionReload:
+		m.showConflict = false
+		if m.diskSealed {
+			m.reopenNote()
 			return m, nil
+		}
+		m.reloadNote(m.diskContent)
+		m.statusMessage = "Reloaded " + m.currentFile + " from disk"
+		m.statusType = "success"
 
-		case "ctrl+s":
-			if m.currentFile == nil {
-				break
-			}
-			// Save the file content
-			if err := m.currentFile.Truncate(0); err != nil {
-				fmt.Println("cannot save the file :(")
-				return m, nil
-			}
+	case actionOverwrite:
+		m.showConflict = false
+		m.stampDisk(m.diskContent)
+		m.saveNote()
 
-			if _, err := m.currentFile.Seek(0, 0); err != nil {
-				fmt.Println("cannot save the file :(")
-				return m, nil
-			}
+	case actionMerge:
+		m.showConflict = false
+		m.mergeNote()
+
+	case actionRecover:
+		m.recoverSwap()
+
+	case actionShowDiff:
+		m.showSwapDiff = !m.showSwapDiff
+
+	case actionReadOnly:
+		m.showLocked = false
+		m.statusMessage = "Opened read-only: " + (&notes.LockedError{Holder: m.lockHolder}).Error()
+		m.statusType = "warning"
+
+	case actionStealLock:
+		m.stealLock()
+
+	case actionHistory:
+		m.openHistory()
 
-			if _, err := m.currentFile.WriteString(m.textArea.Value()); err != nil {
-				fmt.Println("cannot save the file :(")
+	case actionGitLog:
+		m.openGitLog()
+
+	case actionRestore:
+		if ctx == contextTrash {
+			m.restoreTrashed()
+		} else {
+			m.restoreSnapshot()
+		}
+
+	case actionPageUp:
+		m.scrollHistory(-historyPage(m.windowHeight))
+
+	case actionPageDown:
+		m.scrollHistory(historyPage(m.windowHeight))
+
+	case actionCreate:
+		return m.createNote()
+
+	case actionUp:
+		switch {
+		case ctx == contextHistory:
+			m.selectSnapshot(m.historyCursor - 1)
+		case ctx == contextGitLog:
+			m.selectCommit(m.gitLogCursor - 1)
+		case ctx == contextGitConflict:
+			m.gitConflictCursor = max(0, m.gitConflictCursor-1)
+		case m.vaultCursor > 0:
+			m.vaultCursor--
+		}
+
+	case actionDown:
+		switch {
+		case ctx == contextHistory:
+			m.selectSnapshot(m.historyCursor + 1)
+		case ctx == contextGitLog:
+			m.selectCommit(m.gitLogCursor + 1)
+		case ctx == contextGitConflict:
+			m.gitConflictCursor = min(len(m.gitConflicts)-1, m.gitConflictCursor+1)
+		case m.vaultCursor < len(m.cfg.Vaults)-1:
+			m.vaultCursor++
+		}
+
+	case actionSelectVault:
+		m.showVaultSwitcher = false
+		names := m.cfg.VaultNames()
+		if m.vaultCursor < len(names) {
+			cmd := m.switchVault(names[m.vaultCursor])
+			return m, cmd
+		}
+
+	case actionCloseSwitcher:
+		m.showVaultSwitcher = false
+
+	case actionSave:
+		err := m.saveNote()
+		if errors.Is(err, errChangedOnDisk) {
+			m.showUnsaved = false
+			return m, nil
+		}
+		if ctx == contextUnsaved {
+			if err != nil {
+				// Keep the note open so the error can be dealt with
+				m.showUnsaved = false
 				return m, nil
 			}
+			return m.resolveUnsaved(msg)
+		}
 
-			// Sync to disk but keep file open
-			if err := m.currentFile.Sync(); err != nil {
-				fmt.Println("cannot sync the file")
-			}
+	case actionDiscard:
+		if ctx == contextRecover {
+			m.deleteSwap()
+			return m, nil
+		}
+		// Treat the editor content as saved so the pending action goes ahead
+		m.savedContent = m.textArea.Value()
+		return m.resolveUnsaved(msg)
 
-			// Don't close file, don't clear textarea - just save and continue editing
+	case actionClose:
+		if m.confirmUnsaved(actionClose) {
 			return m, nil
+		}
+		m.closeNote()
+		m.statusMessage = ""
+		m.statusType = ""
+
+	case actionHelp:
+		m.showHelp = !m.showHelp
+
+	case actionBullet:
+		m.textArea.InsertString("- ")
+	case actionTodo:
+		m.textArea.InsertString("- [ ] ")
+	case actionToggleTodo:
+		// Toggle todo checkbox (check/uncheck) on current cursor line
+		currentLine := m.textArea.Line()
+		newText := notes.ToggleTodo(m.textArea.Value(), currentLine)
+		m.textArea.SetValue(newText)
+	case actionHeading1:
+		m.textArea.InsertString("# ")
+	case actionHeading2:
+		m.textArea.InsertString("## ")
+	case actionHeading3:
+		m.textArea.InsertString("### ")
+	case actionTable:
+		m.textArea.InsertString(notes.InsertTable(3, 3))
+	case actionCodeBlock:
+		m.textArea.InsertString(notes.InsertCodeBlock(""))
+	case actionLink:
+		m.textArea.InsertString(notes.InsertLink())
+	case actionImage:
+		m.textArea.InsertString(notes.InsertImage())
+	case actionRule:
+		m.textArea.InsertString(notes.InsertHorizontalRule())
+
+	case actionDailyNote:
+		if ctx == contextEditor && m.confirmUnsaved(actionDailyNote) {
+			return m, nil
+		}
+		m.openDailyNote()
 
-		case "esc":
-			if m.showDeleteConfirm {
-				m.showDeleteConfirm = false
-				m.fileToDelete = ""
-				return m, nil
-			}
+	case actionCommand:
+		return m.openCommand()
 
-			if m.createFileInputVisible {
-				m.createFileInputVisible = false
-				m.statusMessage = ""
-				m.statusType = ""
-				m.newFileInput.SetValue("")
-			}
+	case actionRunCommand:
+		return m.runCommand()
 
-			if m.showHelp {
-				m.showHelp = false
-				return m, nil
-			}
+	case actionContinueList:
+		if !m.cfg.Editor.AutoContinueLists {
+			return m.forwardKey(ctx, msg)
+		}
+		m.textArea.InsertString(continueList(m.textArea.Value()))
+	}
 
-			if m.currentFile != nil {
-				// Close the file before exiting
-				if err := m.currentFile.Close(); err != nil {
-					fmt.Println("error closing file")
-				}
-				m.currentFile = nil
-				m.textArea.SetValue("")
-			}
+	return m, nil
+}
 
-			if m.showingList {
-				if m.fileList.FilterState() == list.Filtering {
-					break
-				}
-				m.showingList = false
-			}
+// confirmUnsaved autosaves the open note and reports whether it still has
+// unsaved changes. If so, the unsaved changes dialog is shown and next runs
+// once the user has saved or discarded them.
+func (m *Model) confirmUnsaved(next action) bool {
+	m.autosave()
+	if !m.dirty() {
+		return false
+	}
+	m.showUnsaved = true
+	m.unsavedNext = next
+	return true
+}
 
-			return m, nil
+// resolveUnsaved closes the unsaved changes dialog and runs the action
+// that was waiting on it
+func (m Model) resolveUnsaved(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	next := m.unsavedNext
+	m.showUnsaved = false
+	m.unsavedNext = actionNone
+	return m.perform(contextEditor, next, msg)
+}
 
-		case "enter":
-			if m.currentFile != nil {
-				break
-			}
+// continueList returns the text to insert on Enter so that bullet, todo and
+// numbered lists carry on to the next line
+func continueList(text string) string {
+	lines := strings.Split(text, "\n")
+	lastLine := strings.TrimRight(lines[len(lines)-1], " \t")
 
-			if m.showingList {
-				selectedItem, ok := m.fileList.SelectedItem().(notes.Item)
-				if ok {
-					filepath := fmt.Sprintf("%s/%s", config.VaultDir, selectedItem.Filename())
-					content, err := os.ReadFile(filepath)
-					if err != nil {
-						fmt.Printf("cannot read the file: %v", err)
-						return m, nil
-					}
-					m.textArea.SetValue(string(content))
-
-					file, err := os.OpenFile(filepath, os.O_RDWR, 0644)
-					if err != nil {
-						fmt.Printf("cannot read the file: %v", err)
-						return m, nil
-					}
-
-					m.currentFile = file
-					m.showingList = false
-				}
-				return m, nil
-			}
+	// Check for todo items FIRST (before bullets)
+	if strings.HasPrefix(lastLine, "- [ ] ") && len(lastLine) > 6 {
+		return "\n- [ ] "
+	}
+	if strings.HasPrefix(lastLine, "- [x] ") && len(lastLine) > 6 {
+		return "\n- [ ] "
+	}
 
-			if m.createFileInputVisible {
-				if m.createFileInputVisible {
-					filename := strings.TrimSpace(m.newFileInput.Value())
-
-					// Validate filename
-					if filename == "" {
-						m.statusMessage = "Please enter a note name"
-						m.statusType = "error"
-						return m, nil
-					}
-
-					// Check for invalid characters
-					invalidChars := []string{"/", "\\", ":", "*", "?", "\"", "<", ">", "|"}
-					for _, char := range invalidChars {
-						if strings.Contains(filename, char) {
-							m.statusMessage = "Filename contains invalid characters"
-							m.statusType = "error"
-							return m, nil
-						}
-					}
-
-					filePath := fmt.Sprintf("%s/%s.md", config.VaultDir, filename)
-
-					// Check if file already exists
-					_, err := os.Stat(filePath)
-					if err == nil {
-						m.statusMessage = "File already exists with this name"
-						m.statusType = "error"
-						return m, nil
-					}
-
-					// Create the file
-					f, err := os.Create(filePath)
-					if err != nil {
-						m.statusMessage = fmt.Sprintf("Failed to create file: %v", err)
-						m.statusType = "error"
-						return m, nil
-					}
-
-					m.currentFile = f
-					m.createFileInputVisible = false
-					m.newFileInput.SetValue("")
-					m.statusMessage = ""
-					m.statusType = ""
-					return m, nil
-				}
-			}
+	// Check for bullet points (after todos)
+	if strings.HasPrefix(lastLine, "- ") && len(lastLine) > 2 {
+		return "\n- "
+	}
+	if strings.HasPrefix(lastLine, "* ") && len(lastLine) > 2 {
+		return "\n* "
+	}
+
+	// Check for numbered lists
+	if len(lastLine) > 2 && lastLine[0] >= '0' && lastLine[0] <= '9' && lastLine[1] == '.' && lastLine[2] == ' ' {
+		nextNum := int(lastLine[0]-'0') + 1
+		if nextNum <= 9 {
+			return fmt.Sprintf("\n%d. ", nextNum)
 		}
 	}
 
-	if m.createFileInputVisible {
-		m.newFileInput, cmd = m.newFileInput.Update(msg)
+	// If not a list, just add newline normally
+	return "\n"
+}
+
+// createNote creates a note from the name typed in the create dialog
+func (m Model) createNote() (tea.Model, tea.Cmd) {
+	// Validate the name and create the file
+	// New notes go into the folder being browsed
+	name := strings.TrimSpace(m.newFileInput.Value())
+	if name != "" && m.folder != "" {
+		name = filepath.ToSlash(m.folder) + "/" + name
+	}
+	filename, err := notes.Create(m.vault, name, m.cfg.DefaultExtension)
+	switch {
+	case errors.Is(err, notes.ErrEmptyName):
+		m.statusMessage = "Please enter a note name"
+		m.statusType = "error"
+		return m, nil
+	case errors.Is(err, notes.ErrInvalidName):
+		m.statusMessage = "Filename contains invalid characters"
+		m.statusType = "error"
+		return m, nil
+	case errors.Is(err, notes.ErrExists):
+		m.statusMessage = "File already exists with this name"
+		m.statusType = "error"
+		return m, nil
+	case err != nil:
+		m.statusMessage = fmt.Sprintf("Failed to create file: %v", err)
+		m.statusType = "error"
+		return m, nil
 	}
 
-	if m.currentFile != nil {
-		// Check for markdown shortcuts before passing to textarea
-		if msg, ok := msg.(tea.KeyMsg); ok {
-			switch msg.String() {
-			case "ctrl+h":
-				// Toggle help menu
-				m.showHelp = !m.showHelp
-				return m, nil
-			case "ctrl+b":
-				// Toggle bullet point - insert at cursor
-				m.textArea.InsertString("- ")
-				return m, nil
-			case "ctrl+t":
-				// Insert todo checkbox
-				m.textArea.InsertString("- [ ] ")
-				return m, nil
-			case "ctrl+d":
-				// Toggle todo checkbox (check/uncheck) on current cursor line
-				// Get current line number from textarea
-				currentLine := m.textArea.Line()
-				newText := notes.ToggleTodo(m.textArea.Value(), currentLine)
-				m.textArea.SetValue(newText)
-				return m, nil
-			case "ctrl+1":
-				// Insert H1 header
-				m.textArea.InsertString("# ")
-				return m, nil
-			case "ctrl+2":
-				// Insert H2 header
-				m.textArea.InsertString("## ")
-				return m, nil
-			case "ctrl+3":
-				// Insert H3 header
-				m.textArea.InsertString("### ")
-				return m, nil
-			case "alt+t":
-				// Insert table
-				m.textArea.InsertString(notes.InsertTable(3, 3))
-				return m, nil
-			case "alt+c":
-				// Insert code block
-				m.textArea.InsertString(notes.InsertCodeBlock(""))
-				return m, nil
-			case "alt+l":
-				// Insert link
-				m.textArea.InsertString(notes.InsertLink())
-				return m, nil
-			case "alt+i":
-				// Insert image
-				m.textArea.InsertString(notes.InsertImage())
-				return m, nil
-			case "alt+r":
-				// Insert horizontal rule
-				m.textArea.InsertString(notes.InsertHorizontalRule())
-				return m, nil
-			case "enter":
-				// Auto-continue lists on Enter
-				text := m.textArea.Value()
-				lines := strings.Split(text, "\n")
-				if len(lines) > 0 {
-					lastLine := strings.TrimRight(lines[len(lines)-1], " \t")
-
-					// Check for todo items FIRST (before bullets)
-					if strings.HasPrefix(lastLine, "- [ ] ") && len(lastLine) > 6 {
-						m.textArea.InsertString("\n- [ ] ")
-						return m, nil
-					}
-					if strings.HasPrefix(lastLine, "- [x] ") && len(lastLine) > 6 {
-						m.textArea.InsertString("\n- [ ] ")
-						return m, nil
-					}
-
-					// Check for bullet points (after todos)
-					if strings.HasPrefix(lastLine, "- ") && len(lastLine) > 2 {
-						m.textArea.InsertString("\n- ")
-						return m, nil
-					}
-					if strings.HasPrefix(lastLine, "* ") && len(lastLine) > 2 {
-						m.textArea.InsertString("\n* ")
-						return m, nil
-					}
-
-					// Check for numbered lists
-					if len(lastLine) > 2 && lastLine[0] >= '0' && lastLine[0] <= '9' && lastLine[1] == '.' && lastLine[2] == ' ' {
-						nextNum := int(lastLine[0]-'0') + 1
-						if nextNum <= 9 {
-							m.textArea.InsertString(fmt.Sprintf("\n%d. ", nextNum))
-							return m, nil
-						}
-					}
-				}
-				// If not a list, just add newline normally
-				m.textArea.InsertString("\n")
-				return m, nil
-			}
+	m.unlockNote()
+	m.setNote(filename, "")
+	m.noteKey = nil
+	m.stampDisk("")
+	m.lockNote()
+	m.createFileInputVisible = false
+	m.newFileInput.SetValue("")
+	m.statusMessage = ""
+	m.statusType = ""
+	m.commitNotes("Add "+filepath.ToSlash(filename), filename)
+	return m, nil
+}
+
+// saveNote writes the textarea content to the open note and reports the result
+func (m *Model) saveNote() error {
+	err := m.writeNote(true)
+	if errors.Is(err, errChangedOnDisk) {
+		m.promptConflict(m.diskContent, true)
+		m.statusMessage = m.currentFile + " changed on disk"
+		m.statusType = "warning"
+		return err
+	}
+	if errors.Is(err, errNoSnapshot) {
+		m.statusMessage = fmt.Sprintf("Saved %s, %v", m.currentFile, err)
+		m.statusType = "warning"
+		return nil
+	}
+	if err != nil {
+		m.statusMessage = fmt.Sprintf("Cannot save note: %v", err)
+		m.statusType = "error"
+		return err
+	}
+
+	m.statusMessage = "Saved " + m.currentFile
+	m.statusType = "success"
+	return nil
+}
+
+// writeNote writes the textarea content to the open note, unless the note
+// was changed on disk since it was loaded or saved. Unless explicit, the
+// history snapshot and git commit are put off until the note is saved
+// explicitly or left.
+func (m *Model) writeNote(explicit bool) error {
+	if m.lockedOut() {
+		return &notes.LockedError{Holder: m.lockHolder}
+	}
+	if changed, disk := m.checkDisk(); changed {
+		m.diskContent = disk
+		return errChangedOnDisk
+	}
+
+	content := m.textArea.Value()
+	data := []byte(content)
+	if m.noteKey != nil {
+		var err error
+		if data, err = m.noteKey.Encrypt(data); err != nil {
+			return err
 		}
-		m.textArea, cmd = m.textArea.Update(msg)
+	} else if notes.IsEncrypted(m.currentFile) {
+		return notes.ErrEncrypted
+	}
+	if err := m.vault.Write(m.currentFile, data); err != nil {
+		return err
 	}
 
-	if m.showingList {
-		m.fileList, cmd = m.fileList.Update(msg)
+	previous := savedVersion{m.savedContent, m.diskStamp.ModTime}
+	m.savedContent = content
+	m.lastSaved = time.Now()
+	var snapshotErr error
+	if explicit {
+		snapshotErr = m.snapshot(previous, content)
+		m.commitNotes("Update "+filepath.ToSlash(m.currentFile), m.currentFile)
+	} else if m.autosavedFrom == nil {
+		m.autosavedFrom = &previous
+	}
+	m.stampDisk(content)
+	if snapshotErr != nil {
+		return fmt.Errorf("%w: %v", errNoSnapshot, snapshotErr)
+	}
+	return nil
+}
+
+// OpenNote loads a note from the active vault into the editor, saving the
+// note that was open before if autosave is on. Encrypted notes ask for
+// their passphrase first unless it is remembered.
+func (m *Model) OpenNote(filename string) error {
+	if !notes.IsEncrypted(filename) {
+		return m.openNote(filename, nil)
 	}
 
-	return m, cmd
+	data, err := m.vault.Read(filename)
+	if err != nil {
+		return err
+	}
+	key := m.passphrases.find(data, m.cfg.Encryption.CacheTimeout, time.Now())
+	if key == nil {
+		m.askPassphrase(passphraseUnlock, filename)
+		return nil
+	}
+	return m.openNote(filename, key)
+}
+
+// openNote loads a note into the editor, decrypting it with key if it is
+// encrypted
+func (m *Model) openNote(filename string, key *notes.Key) error {
+	data, err := m.vault.Read(filename)
+	if err != nil {
+		return err
+	}
+	info, err := m.vault.Stat(filename)
+	if err != nil {
+		return err
+	}
+	content := data
+	if key != nil {
+		if content, err = key.Decrypt(data); err != nil {
+			return err
+		}
+	}
+
+	m.autosave()
+	if !m.dirty() {
+		m.dropSwap()
+	}
+	m.unlockNote()
+	m.setNote(filename, string(content))
+	m.noteKey = key
+	m.readOnly = info.Mode().Perm()&0200 == 0
+	m.diskStamp = notes.NewStamp(info, data)
+	m.lockNote()
+	if !m.showLocked {
+		m.checkSwap()
+	}
+	m.showingList = false
+	m.statusMessage = ""
+	m.statusType = ""
+	return nil
+}
+
+// closeNote closes the open note, releasing its swap file and lock
+func (m *Model) closeNote() {
+	m.recordAutosaves()
+	m.dropSwap()
+	m.unlockNote()
+	m.currentFile = ""
+	m.noteKey = nil
+	m.textArea.SetValue("")
+}
+
+// setNote puts a note in the editor and resets the save tracking for it
+func (m *Model) setNote(filename, content string) {
+	m.recordAutosaves()
+	m.textArea.SetValue(content)
+	m.currentFile = filename
+	m.savedContent = content
+	m.lastSeen = content
+	m.lastSaved = time.Time{}
+	m.lastChange = time.Now()
+	m.readOnly = false
+	m.showConflict = false
+	m.dismissedDisk = ""
+	m.swapWritten = false
+	m.lastSwapped = ""
+	m.showRecover = false
+	m.showHistory = false
+	m.showGitLog = false
+}
+
+// switchVault makes the named vault active and reloads the note list from
+// it. It returns the command waiting for changes to the new vault.
+func (m *Model) switchVault(name string) tea.Cmd {
+	cfg, err := m.cfg.UseVault(name)
+	if err != nil {
+		m.statusMessage = fmt.Sprintf("Cannot open vault: %v", err)
+		m.statusType = "error"
+		return nil
+	}
+
+	if cfg.Theme != m.cfg.Theme && !cfg.Plain {
+		if err := m.setTheme(cfg.Theme); err != nil {
+			m.statusMessage = fmt.Sprintf("Cannot apply vault theme: %v", err)
+			m.statusType = "error"
+			return nil
+		}
+	}
+
+	m.cfg = cfg
+	if !cfg.Demo {
+		// The demo vault is only ever in memory
+		m.vault = notes.DirVault(cfg.VaultDir)
+	}
+	m.gitRepo = notes.IsGitRepo(cfg.VaultDir)
+	m.showGitConflicts = false
+	m.gitConflicts = nil
+	applyEditorConfig(&m.textArea, cfg)
+	m.fileList.ResetFilter()
+	m.folder = ""
+	m.refreshList()
+	m.fileList.ResetSelected()
+	m.showingList = true
+	m.showTrash = false
+	m.lastTrashed = notes.TrashItem{}
+	m.purgeOldTrash()
+	m.statusMessage = fmt.Sprintf("Switched to vault %q", name)
+	m.statusType = "success"
+	return m.watchVault()
 }
@@ -2,7 +2,7 @@ package app
 
 import (
 	"fmt"
-	"os"
+	"path/filepath"
 	"strings"
 
 	"github.com/charmbracelet/bubbles/list"
@@ -14,7 +14,7 @@ import (
 )
 
 // renderLanding renders the beautiful landing page
-func renderLanding() string {
+func renderLanding(keys keyMap) string {
 	// Header with ASCII art (using "box" style, can be changed)
 	header := styles.HeaderStyle.Render(styles.GetASCIIArt("box"))
 
@@ -24,23 +24,20 @@ func renderLanding() string {
 	// Help section
 	helpTitle := styles.HelpTitleStyle.Render("⌨️  Keyboard Shortcuts")
 
-	helpItems := []struct {
-		key  string
-		desc string
-	}{
-		{"Ctrl + N", "Create a new note"},
-		{"Ctrl + L", "List all notes"},
-		{"Ctrl + S", "Save current note"},
-		{"Esc", "Go back / Close view"},
-		{"Ctrl + C", "Quit application"},
-	}
-
 	var helpLines []string
-	for _, item := range helpItems {
+	for _, b := range keys.bindings(contextLanding) {
+		line := lipgloss.JoinHorizontal(
+			lipgloss.Left,
+			styles.KeyStyle.Render(b.Help().Key),
+			styles.DescStyle.Render(b.Help().Desc),
+		)
+		helpLines = append(helpLines, styles.HelpItemStyle.Render(line))
+	}
+	if keys.leader.Enabled() {
 		line := lipgloss.JoinHorizontal(
 			lipgloss.Left,
-			styles.KeyStyle.Render(item.key),
-			styles.DescStyle.Render(item.desc),
+			styles.KeyStyle.Render(keys.leader.Help().Key),
+			styles.DescStyle.Render("Leader chords"),
 		)
 		helpLines = append(helpLines, styles.HelpItemStyle.Render(line))
 	}
@@ -63,7 +60,7 @@ func renderLanding() string {
 }
 
 // renderCreateNoteDialog renders a beautiful dialog for creating new notes
-func renderCreateNoteDialog(input textinput.Model, statusMsg string, statusType string) string {
+func renderCreateNoteDialog(input textinput.Model, keys keyMap, extension, folder string, statusMsg string, statusType string) string {
 	// Title with icon
 	title := styles.DialogTitleStyle.Render("📝  CREATE NEW NOTE")
 
@@ -75,9 +72,13 @@ func renderCreateNoteDialog(input textinput.Model, statusMsg string, statusType
 		counterStyle = counterStyle.Foreground(styles.ColorWarning)
 	}
 
+	label := "Note Name:"
+	if folder != "" {
+		label = fmt.Sprintf("Note Name (in %s/):", filepath.ToSlash(folder))
+	}
 	labelWithCounter := lipgloss.JoinHorizontal(
 		lipgloss.Left,
-		styles.InputLabelStyle.Render("Note Name:"),
+		styles.InputLabelStyle.Render(label),
 		counterStyle.Render(fmt.Sprintf("  %d/%d", charCount, maxChars)),
 	)
 
@@ -91,7 +92,10 @@ func renderCreateNoteDialog(input textinput.Model, statusMsg string, statusType
 	inputBox := styles.InputBoxStyle.Render(inputValue)
 
 	// File extension hint
-	extensionHint := styles.FileExtensionStyle.Render(".md extension will be added automatically")
+	extensionHint := styles.FileExtensionStyle.Render(extension + " extension will be added automatically")
+	if extension == "" {
+		extensionHint = styles.FileExtensionStyle.Render("No extension will be added")
+	}
 
 	// Status message (if any)
 	var statusLine string
@@ -112,7 +116,8 @@ func renderCreateNoteDialog(input textinput.Model, statusMsg string, statusType
 	}
 
 	// Help text
-	helpText := styles.InputHelpStyle.Render("⏎ Enter to create  •  Esc to cancel")
+	helpText := styles.InputHelpStyle.Render(fmt.Sprintf("⏎ %s to create  •  %s to cancel",
+		keys.label(contextCreate, actionCreate), keys.label(contextCreate, actionCancel)))
 
 	// Combine all elements
 	content := lipgloss.JoinVertical(
@@ -140,7 +145,7 @@ func renderCreateNoteDialog(input textinput.Model, statusMsg string, statusType
 }
 
 // renderDeleteConfirm renders the delete confirmation dialog
-func renderDeleteConfirm(filename string) string {
+func renderDeleteConfirm(filename string, keys keyMap) string {
 	dialogStyle := lipgloss.NewStyle().
 		Border(lipgloss.RoundedBorder()).
 		BorderForeground(styles.ColorError).
@@ -172,21 +177,21 @@ func renderDeleteConfirm(filename string) string {
 
 	title := titleStyle.Render("⚠️  DELETE NOTE")
 	file := filenameStyle.Render(filename)
-	message := messageStyle.Render("Are you sure you want to delete this note?\nThis action cannot be undone.")
+	message := messageStyle.Render("Move this note to the trash?\nIt can be restored from the trash view.")
 
 	yesButton := lipgloss.NewStyle().
-		Foreground(lipgloss.Color("0")).
+		Foreground(styles.ColorBg).
 		Background(styles.ColorError).
 		Bold(true).
 		Padding(0, 2).
-		Render(" Yes (Y) ")
+		Render(fmt.Sprintf(" Yes (%s) ", keys.label(contextDelete, actionConfirm)))
 
 	noButton := lipgloss.NewStyle().
 		Foreground(styles.ColorText).
 		Background(styles.ColorMuted).
 		Bold(true).
 		Padding(0, 2).
-		Render(" No (N) ")
+		Render(fmt.Sprintf(" No (%s) ", keys.label(contextDelete, actionCancel)))
 
 	buttons := buttonsStyle.Render(yesButton + "  " + noButton)
 
@@ -202,17 +207,127 @@ func renderDeleteConfirm(filename string) string {
 	return dialogStyle.Render(content)
 }
 
+// renderUnsavedConfirm renders the dialog asking what to do with unsaved changes
+func renderUnsavedConfirm(filename string, keys keyMap) string {
+	dialogStyle := lipgloss.NewStyle().
+		Border(lipgloss.RoundedBorder()).
+		BorderForeground(styles.ColorWarning).
+		Padding(2, 4).
+		Width(60)
+
+	titleStyle := lipgloss.NewStyle().
+		Foreground(styles.ColorWarning).
+		Bold(true).
+		Align(lipgloss.Center).
+		Width(52)
+
+	filenameStyle := lipgloss.NewStyle().
+		Foreground(styles.ColorPrimary).
+		Bold(true).
+		Align(lipgloss.Center).
+		Width(52)
+
+	messageStyle := lipgloss.NewStyle().
+		Foreground(styles.ColorMuted).
+		Align(lipgloss.Center).
+		Width(52).
+		MarginTop(1)
+
+	buttonsStyle := lipgloss.NewStyle().
+		Align(lipgloss.Center).
+		Width(52).
+		MarginTop(2)
+
+	title := titleStyle.Render("⚠️  UNSAVED CHANGES")
+	file := filenameStyle.Render(filename)
+	message := messageStyle.Render("This note has changes that are not saved.\nSave them before continuing?")
+
+	saveButton := lipgloss.NewStyle().
+		Foreground(styles.ColorBg).
+		Background(styles.ColorSuccess).
+		Bold(true).
+		Padding(0, 1).
+		Render(fmt.Sprintf(" Save (%s) ", keys.label(contextUnsaved, actionSave)))
+
+	discardButton := lipgloss.NewStyle().
+		Foreground(styles.ColorBg).
+		Background(styles.ColorError).
+		Bold(true).
+		Padding(0, 1).
+		Render(fmt.Sprintf(" Discard (%s) ", keys.label(contextUnsaved, actionDiscard)))
+
+	cancelButton := lipgloss.NewStyle().
+		Foreground(styles.ColorText).
+		Background(styles.ColorMuted).
+		Bold(true).
+		Padding(0, 1).
+		Render(fmt.Sprintf(" Cancel (%s) ", keys.label(contextUnsaved, actionCancel)))
+
+	buttons := buttonsStyle.Render(saveButton + "  " + discardButton + "  " + cancelButton)
+
+	content := lipgloss.JoinVertical(
+		lipgloss.Left,
+		title,
+		"",
+		file,
+		message,
+		buttons,
+	)
+
+	return dialogStyle.Render(content)
+}
+
+// renderVaultSwitcher renders the dialog for choosing the active vault
+func renderVaultSwitcher(keys keyMap, names []string, vaults map[string]string, active string, cursor int) string {
+	title := styles.DialogTitleStyle.Render("🗄️  SWITCH VAULT")
+
+	var rows []string
+	for i, name := range names {
+		marker := "  "
+		nameStyle := lipgloss.NewStyle().Foreground(styles.ColorText)
+		if i == cursor {
+			marker = "› "
+			nameStyle = nameStyle.Foreground(styles.ColorPrimary).Bold(true)
+		}
+
+		label := name
+		if name == active {
+			label += " (current)"
+		}
+
+		row := lipgloss.JoinHorizontal(
+			lipgloss.Left,
+			lipgloss.NewStyle().Foreground(styles.ColorPrimary).Bold(true).Render(marker),
+			nameStyle.Width(24).Render(label),
+			styles.FileExtensionStyle.Render(vaults[name]),
+		)
+		rows = append(rows, row)
+	}
+
+	helpText := styles.InputHelpStyle.Render(shortHelp(keys, contextVault))
+
+	content := lipgloss.JoinVertical(
+		lipgloss.Left,
+		title,
+		strings.Join(rows, "\n"),
+		"",
+		helpText,
+	)
+
+	return styles.DialogBoxStyle.Render(content)
+}
+
 // renderFileListView renders the file list with enhanced styling
-func renderFileListView(fileList list.Model, showDeleteConfirm bool, fileToDelete string) string {
+func renderFileListView(fileList list.Model, keys keyMap) string {
 	// Check if list is empty
 	if len(fileList.Items()) == 0 {
 		emptyState := lipgloss.NewStyle().
 			Foreground(styles.ColorMuted).
 			Align(lipgloss.Center).
 			Padding(10, 2).
-			Render("📝 No notes yet!\n\nPress Ctrl+N to create your first note")
+			Render(fmt.Sprintf("📝 No notes yet!\n\nPress %s to create your first note", keys.label(contextList, actionNewNote)))
 
-		header := styles.ListTitleStyle.Render("📋 All Notes")
+		header := styles.ListTitleStyle.Render("📋 " + fileList.Title)
 		return lipgloss.JoinVertical(lipgloss.Left, header, emptyState)
 	}
 
@@ -223,7 +338,7 @@ func renderFileListView(fileList list.Model, showDeleteConfirm bool, fileToDelet
 		helpText := lipgloss.NewStyle().
 			Foreground(styles.ColorMuted).
 			Padding(1, 2).
-			Render("↑/↓: navigate  •  /: filter  •  Enter: open  •  d: delete  •  Esc: back  •  q: quit")
+			Render(shortHelp(keys, contextList))
 
 		listView = lipgloss.JoinVertical(lipgloss.Left, listView, helpText)
 	}
@@ -231,13 +346,22 @@ func renderFileListView(fileList list.Model, showDeleteConfirm bool, fileToDelet
 	return listView
 }
 
+// shortHelp renders the bindings of a context as a single help line
+func shortHelp(keys keyMap, ctx context) string {
+	var parts []string
+	for _, b := range keys.bindings(ctx) {
+		parts = append(parts, b.Help().Key+": "+b.Help().Desc)
+	}
+	return strings.Join(parts, "  •  ")
+}
+
 // renderFileListViewWithStatus renders the file list with status messages
-func renderFileListViewWithStatus(fileList list.Model, showDeleteConfirm bool, fileToDelete string, statusMessage string, statusType string, windowWidth int, windowHeight int) string {
-	listView := renderFileListView(fileList, false, "")
+func renderFileListViewWithStatus(fileList list.Model, keys keyMap, showDeleteConfirm bool, fileToDelete string, statusMessage string, statusType string, windowWidth int, windowHeight int) string {
+	listView := renderFileListView(fileList, keys)
 
 	// Show delete confirmation overlay if active - do this FIRST to center it
 	if showDeleteConfirm {
-		confirmDialog := renderDeleteConfirm(fileToDelete)
+		confirmDialog := renderDeleteConfirm(fileToDelete, keys)
 		return lipgloss.Place(
 			windowWidth, windowHeight,
 			lipgloss.Center,
@@ -248,21 +372,9 @@ func renderFileListViewWithStatus(fileList list.Model, showDeleteConfirm bool, f
 
 	// Show status message if present
 	if statusMessage != "" {
-		var statusStyle lipgloss.Style
-		switch statusType {
-		case "success":
-			statusStyle = styles.SuccessStyle
-		case "error":
-			statusStyle = styles.ErrorStyle
-		case "warning":
-			statusStyle = styles.WarningStyle
-		default:
-			statusStyle = lipgloss.NewStyle().Foreground(styles.ColorText)
-		}
-
 		statusBar := lipgloss.NewStyle().
 			Padding(0, 2).
-			Render(statusStyle.Render(statusMessage))
+			Render(statusStyle(statusType).Render(statusMessage))
 
 		return lipgloss.JoinVertical(lipgloss.Left, statusBar, listView)
 	}
@@ -270,8 +382,22 @@ func renderFileListViewWithStatus(fileList list.Model, showDeleteConfirm bool, f
 	return listView
 }
 
+// statusStyle returns the style for a status message of the given type
+func statusStyle(statusType string) lipgloss.Style {
+	switch statusType {
+	case "success":
+		return styles.SuccessStyle
+	case "error":
+		return styles.ErrorStyle
+	case "warning":
+		return styles.WarningStyle
+	default:
+		return lipgloss.NewStyle().Foreground(styles.ColorText)
+	}
+}
+
 // renderHelpOverlay renders the help menu with all shortcuts
-func renderHelpOverlay() string {
+func renderHelpOverlay(keys keyMap) string {
 	helpStyle := lipgloss.NewStyle().
 		Border(lipgloss.RoundedBorder()).
 		BorderForeground(styles.ColorPrimary).
@@ -291,49 +417,35 @@ func renderHelpOverlay() string {
 
 	title := titleStyle.Render("⌨️  KEYBOARD SHORTCUTS")
 
-	// Format shortcuts with proper alignment
-	shortcuts := []struct {
-		section string
-		items   [][2]string
-	}{
-		{
-			section: "Basic Commands:",
-			items: [][2]string{
-				{"Ctrl+S", "Save note"},
-				{"Ctrl+H", "Toggle this help"},
-				{"Esc   ", "Close without saving"},
-			},
-		},
-		{
-			section: "Markdown Formatting:",
-			items: [][2]string{
-				{"Ctrl+B", "Insert bullet point (- )"},
-				{"Ctrl+T", "Insert todo checkbox (- [ ] )"},
-				{"Ctrl+D", "Toggle todo (check/uncheck)"},
-				{"Ctrl+1", "Insert H1 header (# )"},
-				{"Ctrl+2", "Insert H2 header (## )"},
-				{"Ctrl+3", "Insert H3 header (### )"},
-			},
-		},
-		{
-			section: "Advanced Features:",
-			items: [][2]string{
-				{"Alt+T ", "Insert table"},
-				{"Alt+C ", "Insert code block"},
-				{"Alt+L ", "Insert link template"},
-				{"Alt+I ", "Insert image template"},
-				{"Alt+R ", "Insert horizontal rule"},
-			},
-		},
+	// Group editor bindings by section, keeping registry order
+	var groups []string
+	items := make(map[string][]binding)
+	keyWidth := 0
+	for _, b := range keys.bindings(contextEditor) {
+		if _, ok := items[b.group]; !ok {
+			groups = append(groups, b.group)
+		}
+		items[b.group] = append(items[b.group], b)
+		keyWidth = max(keyWidth, lipgloss.Width(b.Help().Key))
+	}
+
+	if keys.leader.Enabled() {
+		// Every editor binding may be unbound, leaving no section to join
+		if len(groups) == 0 {
+			groups = append(groups, "Basic Commands:")
+		}
+		leader := newBinding(actionNone, "Leader chords (pause to list)", groups[0], keys.leader.Keys()...)
+		items[groups[0]] = append(items[groups[0]], leader)
+		keyWidth = max(keyWidth, lipgloss.Width(leader.Help().Key))
 	}
 
 	var sections []string
-	for _, s := range shortcuts {
+	for _, group := range groups {
 		sections = append(sections, "")
-		sections = append(sections, sectionStyle.Render(s.section))
-		for _, item := range s.items {
-			key := lipgloss.NewStyle().Foreground(styles.ColorText).Bold(true).Render(item[0])
-			desc := lipgloss.NewStyle().Foreground(styles.ColorMuted).Render("  " + item[1])
+		sections = append(sections, sectionStyle.Render(group))
+		for _, b := range items[group] {
+			key := lipgloss.NewStyle().Foreground(styles.ColorText).Bold(true).Width(keyWidth).Render(b.Help().Key)
+			desc := lipgloss.NewStyle().Foreground(styles.ColorMuted).Render("  " + b.Help().Desc)
 			sections = append(sections, key+desc)
 		}
 	}
@@ -344,7 +456,7 @@ func renderHelpOverlay() string {
 		Align(lipgloss.Center).
 		Width(58).
 		MarginTop(2).
-		Render("Press Ctrl+H or Esc to close")
+		Render(fmt.Sprintf("Press %s to close", strings.Join(keys.labels(contextHelp, actionHelp), " or ")))
 
 	content := lipgloss.JoinVertical(
 		lipgloss.Left,
@@ -358,13 +470,8 @@ func renderHelpOverlay() string {
 }
 
 // renderEditorView renders the note editing interface
-func renderEditorView(currentFile *os.File, textArea textarea.Model, showHelp bool) string {
-	// Extract just the filename from the full path
-	fullPath := currentFile.Name()
-	fileName := fullPath
-	if idx := strings.LastIndex(fullPath, "/"); idx != -1 {
-		fileName = fullPath[idx+1:]
-	}
+func renderEditorView(currentFile string, textArea textarea.Model, showHelp bool, keys keyMap, modified, readOnly bool, saved string, statusMessage string, statusType string) string {
+	fileName := filepath.ToSlash(currentFile) // Includes the folders the note is in
 
 	// Header section with file info
 	headerStyle := lipgloss.NewStyle().
@@ -372,6 +479,12 @@ func renderEditorView(currentFile *os.File, textArea textarea.Model, showHelp bo
 		Bold(true)
 
 	header := headerStyle.Render("✏️  " + fileName)
+	if modified {
+		header += lipgloss.NewStyle().Foreground(styles.ColorWarning).Render("  ● modified")
+	}
+	if readOnly {
+		header += lipgloss.NewStyle().Foreground(styles.ColorMuted).Render("  🔒 read-only")
+	}
 
 	// Editor without border - clean and minimal
 	editor := textArea.View()
@@ -381,17 +494,27 @@ func renderEditorView(currentFile *os.File, textArea textarea.Model, showHelp bo
 		Foreground(styles.ColorMuted)
 
 	// Main commands
-	statusLeft := lipgloss.NewStyle().Foreground(styles.ColorText).Render("Ctrl+S")
+	statusLeft := lipgloss.NewStyle().Foreground(styles.ColorText).Render(keys.label(contextEditor, actionSave))
 	statusLeftDesc := lipgloss.NewStyle().Foreground(styles.ColorMuted).Render(" Save")
 
-	statusRight := lipgloss.NewStyle().Foreground(styles.ColorText).Render("  •  Esc")
+	statusRight := lipgloss.NewStyle().Foreground(styles.ColorText).Render("  •  " + keys.label(contextEditor, actionClose))
 	statusRightDesc := lipgloss.NewStyle().Foreground(styles.ColorMuted).Render(" Close")
 
 	// Help hint
-	helpHint := lipgloss.NewStyle().Foreground(styles.ColorMuted).Render("  •  Ctrl+H Help")
+	helpHint := lipgloss.NewStyle().Foreground(styles.ColorMuted).Render("  •  " + keys.label(contextEditor, actionHelp) + " Help")
 
 	statusBar := statusBarStyle.Render(statusLeft + statusLeftDesc + statusRight + statusRightDesc + helpHint)
 
+	// When the note was last saved, kept current by the editor tick
+	if saved != "" {
+		statusBar += lipgloss.NewStyle().Foreground(styles.ColorMuted).Render("  •  " + saved)
+	}
+
+	// Result of the last action, e.g. a failed save
+	if statusMessage != "" {
+		statusBar += "  •  " + statusStyle(statusType).Render(statusMessage)
+	}
+
 	// Combine all parts
 	view := lipgloss.JoinVertical(
 		lipgloss.Left,
@@ -404,7 +527,7 @@ func renderEditorView(currentFile *os.File, textArea textarea.Model, showHelp bo
 
 	// Overlay help if toggled
 	if showHelp {
-		helpOverlay := renderHelpOverlay()
+		helpOverlay := renderHelpOverlay(keys)
 		// Place help overlay centered on top of the editor view
 		return lipgloss.Place(
 			lipgloss.Width(view),
@@ -413,7 +536,7 @@ func renderEditorView(currentFile *os.File, textArea textarea.Model, showHelp bo
 			lipgloss.Center,
 			helpOverlay,
 			lipgloss.WithWhitespaceChars(" "),
-			lipgloss.WithWhitespaceForeground(lipgloss.Color("0")),
+			lipgloss.WithWhitespaceForeground(styles.ColorBg),
 		)
 	}
 
@@ -422,21 +545,73 @@ func renderEditorView(currentFile *os.File, textArea textarea.Model, showHelp bo
 
 // View renders the current state of the application (Bubble Tea interface)
 func (m Model) View() string {
-	// If showing the file input
-	if m.createFileInputVisible {
-		return renderCreateNoteDialog(m.newFileInput, m.statusMessage, m.statusType)
+	view := m.screen()
+
+	if m.showCommand {
+		line := renderCommandLine(m.commandInput, m.windowWidth)
+		if m.cfg.Plain {
+			line = m.commandInput.View()
+		}
+		return overlayBottom(view, line, m.windowHeight)
 	}
 
-	// If editing a file
-	if m.currentFile != nil {
-		return renderEditorView(m.currentFile, m.textArea, m.showHelp)
+	// Show the chord continuations over the bottom of the screen
+	if m.showWhichKey {
+		chords := m.keys.chords[m.activeContext()]
+		popup := renderWhichKey(chords, m.chordKeys, m.keys.leader.Help().Key)
+		if m.cfg.Plain {
+			popup = renderPlainWhichKey(chords, m.chordKeys, m.keys.leader.Help().Key)
+		}
+		return overlayBottom(view, popup, m.windowHeight)
 	}
 
-	// If showing the file list
-	if m.showingList {
-		return renderFileListViewWithStatus(m.fileList, m.showDeleteConfirm, m.fileToDelete, m.statusMessage, m.statusType, m.windowWidth, m.windowHeight)
+	return view
+}
+
+// screen renders the view for the current mode
+func (m Model) screen() string {
+	if m.cfg.Plain {
+		return m.plainScreen()
 	}
 
-	// Default: show landing page
-	return renderLanding()
+	// placed centers a dialog on the screen
+	placed := func(dialog string) string {
+		return lipgloss.Place(m.windowWidth, m.windowHeight, lipgloss.Center, lipgloss.Center, dialog)
+	}
+
+	switch m.screenContext() {
+	case contextLocked:
+		return placed(renderLockedDialog(m.currentFile, m.lockHolder, m.keys))
+	case contextRecover:
+		return placed(renderRecoverDialog(m.currentFile, m.swapDiff, m.swapTime, m.showSwapDiff, m.keys))
+	case contextConflict:
+		return placed(renderConflictDialog(m.currentFile, m.diskDiff, m.diskSealed, m.keys))
+	case contextUnsaved:
+		return placed(renderUnsavedConfirm(m.currentFile, m.keys))
+	case contextVault:
+		return placed(renderVaultSwitcher(m.keys, m.cfg.VaultNames(), m.cfg.Vaults, m.cfg.VaultName, m.vaultCursor))
+	case contextPassphrase:
+		return placed(renderPassphraseDialog(m.passphraseStep, m.passphraseNote(), m.passphraseInput, m.keys, m.statusMessage, m.statusType))
+	case contextImport:
+		return placed(renderImportDialog(m.importPlan, m.importReport, m.folder, m.keys))
+	case contextCreate:
+		return renderCreateNoteDialog(m.newFileInput, m.keys, m.cfg.DefaultExtension, m.folder, m.statusMessage, m.statusType)
+	case contextGitConflict:
+		return placed(renderGitConflicts(m.gitConflicts, m.gitConflictCursor, m.keys))
+	case contextHistory:
+		return renderHistoryView(m.currentFile, m.snapshots, m.historyCursor, m.historyDiff, m.historyScroll, m.windowWidth, m.windowHeight, m.keys, m.statusMessage, m.statusType)
+	case contextGitLog:
+		return renderGitLogView(m.currentFile, m.gitLog, m.gitLogCursor, m.historyDiff, m.historyScroll, m.windowWidth, m.windowHeight, m.keys, m.statusMessage, m.statusType)
+	case contextHelp, contextEditor:
+		return renderEditorView(m.currentFile, m.textArea, m.showHelp, m.keys, m.dirty(), m.readOnly, savedAgo(m.lastSaved), m.statusMessage, m.statusType)
+	case contextPurge, contextTrash:
+		return renderTrashView(m.trashList, m.keys, m.showPurgeConfirm, m.statusMessage, m.statusType, m.windowWidth, m.windowHeight)
+	case contextRename:
+		return placed(renderRenameDialog(m.fileToRename, m.renameInput, m.keys, m.statusMessage, m.statusType))
+	case contextMove:
+		return placed(renderMoveDialog(m.fileToMove, m.moveInput, m.keys, m.statusMessage, m.statusType))
+	case contextDelete, contextFilter, contextList:
+		return renderFileListViewWithStatus(m.fileList, m.keys, m.showDeleteConfirm, m.fileToDelete, m.statusMessage, m.statusType, m.windowWidth, m.windowHeight)
+	}
+	return renderLanding(m.keys)
 }
@@ -0,0 +1,46 @@
+package app
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/charmbracelet/bubbles/list"
+)
+
+func TestViewsShowRemappedKeys(t *testing.T) {
+	keys, err := newKeyMap(map[string][]string{
+		"delete.confirm": {"d"},
+		"delete.cancel":  {"k"},
+		"new_note":       {"alt+n"},
+	}, "ctrl+space")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	dialog := renderDeleteConfirm("plan.md", keys)
+	for _, want := range []string{"Yes (d)", "No (k)"} {
+		if !strings.Contains(dialog, want) {
+			t.Errorf("delete dialog does not show %q:\n%s", want, dialog)
+		}
+	}
+
+	empty := list.New(nil, list.NewDefaultDelegate(), 80, 20)
+	if view := renderFileListView(empty, keys); !strings.Contains(view, "Press Alt+N") {
+		t.Errorf("empty note list does not show the new note key:\n%s", view)
+	}
+}
+
+func TestHelpOverlayWithEditorUnbound(t *testing.T) {
+	overrides := make(map[string][]string)
+	for _, b := range defaultBindings[contextEditor] {
+		overrides["editor."+string(b.action)] = nil
+	}
+	keys, err := newKeyMap(overrides, "ctrl+space")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if help := renderHelpOverlay(keys); !strings.Contains(help, "Leader chords") {
+		t.Errorf("help overlay does not list the leader key:\n%s", help)
+	}
+}
@@ -0,0 +1,102 @@
+package app
+
+import (
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/charmbracelet/bubbles/list"
+	tea "github.com/charmbracelet/bubbletea"
+
+	"github.com/shalshcode08/Term-Note/internal/notes"
+)
+
+// watchInterval is how often vaults that inotify cannot watch are listed again
+const watchInterval = 2 * time.Second
+
+// vaultChangedMsg reports notes and folders that changed in the vault
+type vaultChangedMsg struct {
+	watcher *notes.Watcher // Watcher that saw the changes, stale after a vault switch
+	paths   []string
+}
+
+// waitForChanges waits for the next batch of changes seen by a watcher
+func waitForChanges(w *notes.Watcher) tea.Cmd {
+	return func() tea.Msg {
+		paths, ok := <-w.Changes()
+		if !ok {
+			return nil
+		}
+		return vaultChangedMsg{watcher: w, paths: paths}
+	}
+}
+
+// watchVault starts watching the active vault, stopping the watcher of the
+// previous one
+func (m *Model) watchVault() tea.Cmd {
+	if m.watcher != nil {
+		m.watcher.Close()
+	}
+	m.watcher = notes.Watch(m.vault, watchInterval)
+	return waitForChanges(m.watcher)
+}
+
+// handleVaultChange brings the note list up to date after the vault changed
+// and checks the open note right away if it was among the changes. Listing
+// the vault walks all of it, so changes the list cannot show are skipped.
+func (m *Model) handleVaultChange(paths []string) {
+	if m.listShows(paths) {
+		m.refreshList()
+	}
+	if m.currentFile == "" {
+		return
+	}
+	for _, path := range paths {
+		if path == "" || path == m.currentFile {
+			m.watchDisk()
+			return
+		}
+	}
+}
+
+// listShows reports whether any of the changed paths can alter the note
+// list: those in or below the folder it shows, which count towards its
+// subfolders, and the folder itself or one above it, which may be gone
+func (m *Model) listShows(paths []string) bool {
+	for _, path := range paths {
+		if path == "" || m.folder == "" || inFolder(path, m.folder) || inFolder(m.folder, path) {
+			return true
+		}
+	}
+	return false
+}
+
+// inFolder reports whether a vault-relative path is a folder or lies below it
+func inFolder(path, folder string) bool {
+	rel, err := filepath.Rel(folder, path)
+	return err == nil && rel != ".." && !strings.HasPrefix(rel, ".."+string(filepath.Separator))
+}
+
+// updateListItems replaces the items of the note list, keeping the selected
+// item selected wherever it moved to, or the same position if it is gone.
+// An active filter is applied to the new items straight away.
+func (m *Model) updateListItems(items []list.Item) {
+	var selected string
+	if item, ok := m.fileList.SelectedItem().(notes.Item); ok {
+		selected = item.Filename()
+	}
+	index := m.fileList.Index()
+
+	if cmd := m.fileList.SetItems(items); cmd != nil {
+		m.fileList, _ = m.fileList.Update(cmd())
+	}
+
+	visible := m.fileList.VisibleItems()
+	for i, item := range visible {
+		if note, ok := item.(notes.Item); ok && selected != "" && note.Filename() == selected {
+			m.fileList.Select(i)
+			return
+		}
+	}
+	m.fileList.Select(max(0, min(index, len(visible)-1)))
+}
@@ -0,0 +1,78 @@
+package app
+
+import (
+	"path/filepath"
+	"slices"
+	"testing"
+
+	"github.com/shalshcode08/Term-Note/internal/notes"
+)
+
+// listedNames returns the filenames shown in the note list
+func listedNames(m Model) []string {
+	var names []string
+	for _, item := range m.fileList.Items() {
+		names = append(names, item.(notes.Item).Filename())
+	}
+	slices.Sort(names)
+	return names
+}
+
+func TestVaultChangeRefreshesList(t *testing.T) {
+	m, vault := newTestModel(t, map[string]string{
+		"work/a.md":  "a",
+		"other/b.md": "b",
+	})
+	m.openFolder("work")
+	change := func(paths ...string) {
+		t.Helper()
+		next, _ := m.Update(vaultChangedMsg{watcher: m.watcher, paths: paths})
+		m = next.(Model)
+	}
+	write := func(name string) {
+		t.Helper()
+		if err := vault.Write(name, nil); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	tests := []struct {
+		name  string
+		write string
+		paths []string
+		want  []string
+	}{
+		{"change outside the folder", "work/c.md", []string{"other/b.md"}, []string{"work/a.md"}},
+		{"change in the folder", "", []string{"work/c.md"}, []string{"work/a.md", "work/c.md"}},
+		{"change below the folder", "work/deep/d.md", []string{"work/deep/d.md"}, []string{"work/a.md", "work/c.md", "work/deep"}},
+		{"anything changed", "work/e.md", []string{""}, []string{"work/a.md", "work/c.md", "work/deep", "work/e.md"}},
+	}
+	for _, tt := range tests {
+		if tt.write != "" {
+			write(filepath.FromSlash(tt.write))
+		}
+		var paths []string
+		for _, path := range tt.paths {
+			paths = append(paths, filepath.FromSlash(path))
+		}
+		change(paths...)
+		var want []string
+		for _, name := range tt.want {
+			want = append(want, filepath.FromSlash(name))
+		}
+		if got := listedNames(m); !slices.Equal(got, want) {
+			t.Errorf("%s: list = %q, want %q", tt.name, got, want)
+		}
+	}
+
+	// The folder shown going away moves the list up to the vault
+	for _, name := range []string{"a.md", "c.md", "e.md", "deep/d.md", "deep", ""} {
+		if err := vault.Delete(filepath.Join("work", filepath.FromSlash(name))); err != nil {
+			t.Fatal(err)
+		}
+	}
+	change("work")
+	if got := listedNames(m); m.folder != "" || !slices.Equal(got, []string{"other"}) {
+		t.Errorf("after the folder went away, folder = %q, list = %q", m.folder, got)
+	}
+}
@@ -0,0 +1,540 @@
+package cli
+
+import (
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/muesli/termenv"
+
+	"github.com/shalshcode08/Term-Note/internal/app"
+	"github.com/shalshcode08/Term-Note/internal/config"
+	"github.com/shalshcode08/Term-Note/internal/notes"
+	"github.com/shalshcode08/Term-Note/internal/ui/styles"
+)
+
+// env carries everything a subcommand needs to run
+type env struct {
+	cfg    *config.Config
+	vault  notes.Vault
+	stdin  io.Reader
+	stdout io.Writer
+	stderr io.Writer
+	tty    bool // stdout is a terminal, so styled output is allowed
+
+	// teaOptions are added to the interactive programs started by edit
+	// and capture, so tests can feed them keys
+	teaOptions []tea.ProgramOption
+}
+
+// command is a non-interactive subcommand
+type command struct {
+	usage string
+	run   func(e *env, args []string) error
+}
+
+var commands = map[string]command{
+	"new":     {"new <name>", runNew},
+	"list":    {"list [--json]", runList},
+	"cat":     {"cat <name>", runCat},
+	"rm":      {"rm [--force] <name>", runRm},
+	"search":  {"search <query>", runSearch},
+	"edit":    {"edit <name>", runEdit},
+	"append":  {"append [--code] [--lang <lang>] [--timestamp] [--daily] [<name>] < input", runAppend},
+	"prepend": {"prepend [--code] [--lang <lang>] [--timestamp] [--daily] [<name>] < input", runPrepend},
+	"capture": {"capture [--inbox <name>]", runCapture},
+	"import":  {"import [--dry-run] [--into <folder>] [--format <format>] <source>", runImport},
+}
+
+// commandOrder is the order commands are listed in the usage text
+var commandOrder = []string{"new", "list", "cat", "rm", "search", "edit", "append", "prepend", "capture", "import"}
+
+// Run executes the subcommand named by args[0] against the notes of vault
+// and returns the exit code
+func Run(cfg *config.Config, vault notes.Vault, args []string, stdin io.Reader, stdout, stderr io.Writer) int {
+	e := &env{
+		cfg:    cfg,
+		vault:  vault,
+		stdin:  stdin,
+		stdout: stdout,
+		stderr: stderr,
+		tty:    isTerminal(stdout),
+	}
+	return e.run(args)
+}
+
+// run executes the subcommand named by args[0] and returns the exit code
+func (e *env) run(args []string) int {
+	if e.cfg.Plain {
+		// Keep the styles used for terminal output free of color
+		lipgloss.SetColorProfile(termenv.Ascii)
+	} else if e.tty {
+		// Style terminal output with the configured theme. A broken theme
+		// only costs the colors, so carry on with the default one.
+		if err := app.LoadThemes(e.cfg); err != nil {
+			fmt.Fprintf(e.stderr, "termnote: warning: %v\n", err)
+		}
+	}
+
+	if len(args) == 0 || args[0] == "help" {
+		printUsage(e.stdout)
+		return 0
+	}
+
+	cmd, ok := commands[args[0]]
+	if !ok {
+		fmt.Fprintf(e.stderr, "termnote: unknown command %q\n\n", args[0])
+		printUsage(e.stderr)
+		return 2
+	}
+
+	if err := cmd.run(e, args[1:]); err != nil {
+		if errors.Is(err, flag.ErrHelp) {
+			return 0
+		}
+		fmt.Fprintf(e.stderr, "termnote %s: %v\n", args[0], err)
+		return 1
+	}
+	return 0
+}
+
+func printUsage(w io.Writer) {
+	fmt.Fprintln(w, "Usage: termnote [flags] [command]")
+	fmt.Fprintln(w)
+	fmt.Fprintln(w, "Without a command the interactive app is started.")
+	fmt.Fprintln(w)
+	fmt.Fprintln(w, "Commands:")
+	for _, name := range commandOrder {
+		fmt.Fprintf(w, "  termnote %s\n", commands[name].usage)
+	}
+}
+
+// isTerminal reports whether w is an interactive terminal
+func isTerminal(w io.Writer) bool {
+	f, ok := w.(*os.File)
+	if !ok {
+		return false
+	}
+	info, err := f.Stat()
+	if err != nil {
+		return false
+	}
+	return info.Mode()&os.ModeCharDevice != 0
+}
+
+// newFlagSet returns a flag set for a subcommand that reports errors to stderr
+func newFlagSet(e *env, name string) *flag.FlagSet {
+	fs := flag.NewFlagSet("termnote "+name, flag.ContinueOnError)
+	fs.SetOutput(e.stderr)
+	return fs
+}
+
+// parseArgs parses flags that may appear before or after positional
+// arguments and returns the positional arguments
+func parseArgs(fs *flag.FlagSet, args []string) ([]string, error) {
+	var positional []string
+	for {
+		if err := fs.Parse(args); err != nil {
+			return nil, err
+		}
+		args = fs.Args()
+		if len(args) == 0 {
+			return positional, nil
+		}
+		positional = append(positional, args[0])
+		args = args[1:]
+	}
+}
+
+// oneArg parses flags and returns the single positional argument
+func oneArg(fs *flag.FlagSet, args []string, what string) (string, error) {
+	positional, err := parseArgs(fs, args)
+	if err != nil {
+		return "", err
+	}
+	if len(positional) != 1 {
+		return "", fmt.Errorf("expected exactly one %s", what)
+	}
+	return positional[0], nil
+}
+
+// success prints a confirmation, styled when writing to a terminal
+func (e *env) success(format string, a ...any) {
+	msg := fmt.Sprintf(format, a...)
+	if e.tty && !e.cfg.Plain {
+		msg = styles.SuccessStyle.Render("✓ " + msg)
+	}
+	fmt.Fprintln(e.stdout, msg)
+}
+
+// commit commits notes when the vault is a git repository with auto-commit
+// on. A failed commit is only a warning: the notes are already written.
+func (e *env) commit(cmd, message string, filenames ...string) {
+	if !e.cfg.Git.AutoCommit || !notes.IsGitRepo(e.cfg.VaultDir) {
+		return
+	}
+	if err := notes.GitCommit(e.cfg.VaultDir, message, filenames...); err != nil {
+		fmt.Fprintf(e.stderr, "termnote %s: warning: no git commit was made: %v\n", cmd, err)
+	}
+}
+
+func runNew(e *env, args []string) error {
+	name, err := oneArg(newFlagSet(e, "new"), args, "note name")
+	if err != nil {
+		return err
+	}
+
+	filename, err := notes.Create(e.vault, name, e.cfg.DefaultExtension)
+	if err != nil {
+		return err
+	}
+
+	e.commit("new", "Add "+filepath.ToSlash(filename), filename)
+	if e.tty {
+		e.success("Created %s", filepath.ToSlash(filename))
+	} else {
+		fmt.Fprintln(e.stdout, filepath.Join(e.cfg.VaultDir, filename))
+	}
+	return nil
+}
+
+func runList(e *env, args []string) error {
+	fs := newFlagSet(e, "list")
+	asJSON := fs.Bool("json", false, "print notes as JSON")
+	if _, err := parseArgs(fs, args); err != nil {
+		return err
+	}
+
+	found, err := notes.ListNotes(e.vault)
+	if err != nil {
+		return err
+	}
+
+	if *asJSON {
+		enc := json.NewEncoder(e.stdout)
+		enc.SetIndent("", "  ")
+		return enc.Encode(found)
+	}
+
+	if !e.tty {
+		for _, note := range found {
+			fmt.Fprintf(e.stdout, "%s\t%s\n", note.Name, note.Modified.Format("2006-01-02T15:04:05Z07:00"))
+		}
+		return nil
+	}
+
+	if len(found) == 0 {
+		fmt.Fprintln(e.stdout, styles.ViewHelpStyle.Render("No notes yet"))
+		return nil
+	}
+
+	width := 0
+	for _, note := range found {
+		width = max(width, lipgloss.Width(note.Name))
+	}
+	nameStyle := styles.ListItemTitleStyle.Width(width + 2)
+	for _, note := range found {
+		fmt.Fprintln(e.stdout, nameStyle.Render(note.Name)+styles.ListItemDescStyle.Render(notes.FormatRelativeTime(note.Modified)))
+	}
+	return nil
+}
+
+func runCat(e *env, args []string) error {
+	name, err := oneArg(newFlagSet(e, "cat"), args, "note name")
+	if err != nil {
+		return err
+	}
+
+	filename, err := notes.Resolve(e.vault, name, e.cfg.DefaultExtension)
+	if err != nil {
+		return err
+	}
+	if notes.IsEncrypted(filename) {
+		return fmt.Errorf("%s: %w, open it with termnote edit", filename, notes.ErrEncrypted)
+	}
+
+	content, err := e.vault.Read(filename)
+	if err != nil {
+		return err
+	}
+	_, err = e.stdout.Write(content)
+	return err
+}
+
+func runRm(e *env, args []string) error {
+	fs := newFlagSet(e, "rm")
+	force := fs.Bool("force", false, "trash the note even if it is open elsewhere")
+	name, err := oneArg(fs, args, "note name")
+	if err != nil {
+		return err
+	}
+
+	filename, err := notes.Resolve(e.vault, name, e.cfg.DefaultExtension)
+	if err != nil {
+		return err
+	}
+	if !*force {
+		if err := notes.CheckLock(e.cfg.LockDir(), filename); err != nil {
+			return fmt.Errorf("%s: %w, use --force to trash it anyway", filename, err)
+		}
+	}
+
+	if _, err := notes.Trash(e.vault, filename, time.Now()); err != nil {
+		return err
+	}
+	e.commit("rm", "Delete "+filepath.ToSlash(filename), filename)
+
+	if e.tty {
+		e.success("Moved %s to the trash", filename)
+	}
+	return nil
+}
+
+func runSearch(e *env, args []string) error {
+	positional, err := parseArgs(newFlagSet(e, "search"), args)
+	if err != nil {
+		return err
+	}
+	if len(positional) == 0 {
+		return fmt.Errorf("expected a search query")
+	}
+	query := strings.Join(positional, " ")
+
+	matches, err := notes.Search(e.vault, query)
+	if err != nil {
+		return err
+	}
+
+	for _, match := range matches {
+		if match.Line == 0 {
+			if e.tty {
+				fmt.Fprintln(e.stdout, styles.ListItemSelectedTitleStyle.Render(match.Note))
+			} else {
+				fmt.Fprintln(e.stdout, match.Note)
+			}
+			continue
+		}
+
+		if e.tty {
+			fmt.Fprintf(e.stdout, "%s%s %s\n",
+				styles.ListItemSelectedTitleStyle.Render(match.Note),
+				styles.ListItemDescStyle.Render(fmt.Sprintf(":%d:", match.Line)),
+				match.Text,
+			)
+		} else {
+			fmt.Fprintf(e.stdout, "%s:%d:%s\n", match.Note, match.Line, match.Text)
+		}
+	}
+
+	if len(matches) == 0 && e.tty {
+		fmt.Fprintln(e.stdout, styles.ViewHelpStyle.Render("No matches"))
+	}
+	return nil
+}
+
+func runEdit(e *env, args []string) error {
+	name, err := oneArg(newFlagSet(e, "edit"), args, "note name")
+	if err != nil {
+		return err
+	}
+
+	// Create the note first if it does not exist yet
+	filename, err := notes.Resolve(e.vault, name, e.cfg.DefaultExtension)
+	if errors.Is(err, notes.ErrNotFound) {
+		filename, err = notes.Create(e.vault, name, e.cfg.DefaultExtension)
+	}
+	if err != nil {
+		return err
+	}
+
+	m, err := app.New(e.cfg, e.vault)
+	if err != nil {
+		return err
+	}
+	if err := m.OpenNote(filename); err != nil {
+		return err
+	}
+
+	p := tea.NewProgram(m, append([]tea.ProgramOption{tea.WithReportFocus(), tea.WithoutSignalHandler()}, e.teaOptions...)...)
+	app.WatchSignals(p)
+	_, err = p.Run()
+	return err
+}
+
+func runAppend(e *env, args []string) error {
+	return addFromStdin(e, "append", args, false)
+}
+
+func runPrepend(e *env, args []string) error {
+	return addFromStdin(e, "prepend", args, true)
+}
+
+// addFromStdin reads stdin and adds it to a note, creating the note if needed
+func addFromStdin(e *env, name string, args []string, prepend bool) error {
+	fs := newFlagSet(e, name)
+	code := fs.Bool("code", false, "wrap the input in a fenced code block")
+	lang := fs.String("lang", "text", "language of the code block")
+	timestamp := fs.Bool("timestamp", false, "add a timestamp heading above the input")
+	daily := fs.Bool("daily", false, "add to today's daily note")
+	positional, err := parseArgs(fs, args)
+	if err != nil {
+		return err
+	}
+
+	now := time.Now()
+	var note string
+	switch {
+	case *daily && len(positional) == 0:
+		note = notes.DailyNoteName(now)
+	case !*daily && len(positional) == 1:
+		note = positional[0]
+	default:
+		return fmt.Errorf("expected a note name or --daily")
+	}
+
+	input, err := io.ReadAll(e.stdin)
+	if err != nil {
+		return fmt.Errorf("error reading stdin: %w", err)
+	}
+	text := string(input)
+	if strings.TrimSpace(text) == "" {
+		return fmt.Errorf("nothing to %s: stdin is empty", name)
+	}
+
+	if *code {
+		text = notes.CodeBlock(text, *lang)
+	}
+	if *timestamp {
+		text = notes.TimestampHeading(now) + "\n\n" + text
+	}
+
+	filename, err := notes.AddToNote(e.vault, note, e.cfg.DefaultExtension, text, prepend)
+	if err != nil {
+		return err
+	}
+	e.commit(name, "Update "+filepath.ToSlash(filename), filename)
+
+	if e.tty {
+		e.success("Added to %s", filename)
+	}
+	return nil
+}
+
+func runCapture(e *env, args []string) error {
+	fs := newFlagSet(e, "capture")
+	inbox := fs.String("inbox", e.cfg.Inbox, "note to append the entry to")
+	positional, err := parseArgs(fs, args)
+	if err != nil {
+		return err
+	}
+	if len(positional) != 0 {
+		return fmt.Errorf("unexpected argument %q", positional[0])
+	}
+	if err := notes.ValidateName(*inbox); err != nil {
+		return fmt.Errorf("inbox %q: %w", *inbox, err)
+	}
+
+	capture, err := app.NewCapture(e.cfg, e.vault, *inbox)
+	if err != nil {
+		return err
+	}
+	final, err := tea.NewProgram(capture, e.teaOptions...).Run()
+	if err != nil {
+		return err
+	}
+
+	if m, ok := final.(app.CaptureModel); ok && m.SavedTo() != "" {
+		e.commit("capture", "Update "+filepath.ToSlash(m.SavedTo()), m.SavedTo())
+		if e.tty {
+			e.success("Captured to %s", m.SavedTo())
+		}
+	}
+	return nil
+}
+
+func runImport(e *env, args []string) error {
+	fs := newFlagSet(e, "import")
+	dryRun := fs.Bool("dry-run", false, "report what would be imported without writing anything")
+	into := fs.String("into", "", "vault folder to import into")
+	format := fs.String("format", "", "format of the source: enex, joplin, obsidian or text (detected if empty)")
+	source, err := oneArg(fs, args, "source")
+	if err != nil {
+		return err
+	}
+	if *into != "" {
+		if err := notes.ValidateName(*into); err != nil {
+			return fmt.Errorf("folder %q: %w", *into, err)
+		}
+	}
+
+	plan, err := notes.ReadImport(source, notes.ImportFormat(*format), e.cfg.DefaultExtension)
+	if err != nil {
+		return err
+	}
+	report, err := notes.Import(e.vault, plan, filepath.FromSlash(*into), *dryRun)
+	// Commit what was written even if the import stopped early
+	if len(report.Created) > 0 && !*dryRun {
+		e.commit("import", fmt.Sprintf("Import %s", filepath.Base(source)), report.Created...)
+	}
+	if err != nil {
+		return err
+	}
+
+	printImport(e, report)
+	if len(report.Existing) > 0 {
+		return fmt.Errorf("%d %s already exist and %s left alone", len(report.Existing),
+			plural(len(report.Existing), "file", "files"), plural(len(report.Existing), "was", "were"))
+	}
+	return nil
+}
+
+// printImport lists what an import did, one file per line, then sums it up
+func printImport(e *env, report notes.ImportReport) {
+	created := "imported"
+	if report.DryRun {
+		created = "import"
+	}
+	var lines [][2]string
+	for _, name := range report.Created {
+		lines = append(lines, [2]string{created, filepath.ToSlash(name)})
+	}
+	for _, name := range report.Existing {
+		lines = append(lines, [2]string{"exists", filepath.ToSlash(name)})
+	}
+	for _, source := range report.Skipped {
+		lines = append(lines, [2]string{"skipped", source})
+	}
+
+	if !e.tty {
+		for _, line := range lines {
+			fmt.Fprintf(e.stdout, "%s\t%s\n", line[0], line[1])
+		}
+		return
+	}
+
+	labelStyle := styles.ListItemDescStyle.Width(10)
+	for _, line := range lines {
+		fmt.Fprintln(e.stdout, labelStyle.Render(line[0])+line[1])
+	}
+	if len(report.Existing) == 0 {
+		e.success("%s", report.Summary())
+	} else {
+		fmt.Fprintln(e.stdout, styles.WarningStyle.Render("! "+report.Summary()))
+	}
+}
+
+// plural returns one or many depending on n
+func plural(n int, one, many string) string {
+	if n == 1 {
+		return one
+	}
+	return many
+}
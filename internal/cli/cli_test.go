@@ -0,0 +1,258 @@
+package cli
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+
+	"github.com/shalshcode08/Term-Note/internal/config"
+	"github.com/shalshcode08/Term-Note/internal/notes"
+)
+
+// newTestEnv returns an env for a vault in a temp directory
+func newTestEnv(t *testing.T) *env {
+	t.Helper()
+	dir := t.TempDir()
+	t.Setenv("HOME", dir)
+
+	cfg, err := config.Default()
+	if err != nil {
+		t.Fatal(err)
+	}
+	cfg.Path = filepath.Join(dir, "config.toml")
+	cfg.VaultDir = filepath.Join(dir, "vault")
+	cfg.Git.AutoCommit = false
+	if err := os.MkdirAll(cfg.VaultDir, 0755); err != nil {
+		t.Fatal(err)
+	}
+	return &env{cfg: cfg, vault: notes.DirVault(cfg.VaultDir)}
+}
+
+// runCommand runs a subcommand with stdin and returns its exit code and output
+func runCommand(t *testing.T, e *env, stdin string, args ...string) (int, string, string) {
+	t.Helper()
+	var stdout, stderr bytes.Buffer
+	e.stdin = strings.NewReader(stdin)
+	e.stdout = &stdout
+	e.stderr = &stderr
+	code := e.run(args)
+	return code, stdout.String(), stderr.String()
+}
+
+// mustRun runs a subcommand that is expected to succeed and returns its output
+func mustRun(t *testing.T, e *env, stdin string, args ...string) string {
+	t.Helper()
+	code, stdout, stderr := runCommand(t, e, stdin, args...)
+	if code != 0 {
+		t.Fatalf("termnote %s exited with %d: %s", strings.Join(args, " "), code, stderr)
+	}
+	return stdout
+}
+
+// readNote returns the contents of a note in the vault
+func readNote(t *testing.T, e *env, filename string) string {
+	t.Helper()
+	data, err := os.ReadFile(filepath.Join(e.cfg.VaultDir, filename))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return string(data)
+}
+
+func TestRunUsage(t *testing.T) {
+	e := newTestEnv(t)
+
+	code, stdout, _ := runCommand(t, e, "", "help")
+	if code != 0 || !strings.Contains(stdout, "termnote rm [--force] <name>") {
+		t.Errorf("help = %d %q", code, stdout)
+	}
+
+	code, _, stderr := runCommand(t, e, "", "nope")
+	if code != 2 || !strings.Contains(stderr, `unknown command "nope"`) {
+		t.Errorf("unknown command = %d %q", code, stderr)
+	}
+
+	code, _, stderr = runCommand(t, e, "", "cat")
+	if code != 1 || !strings.Contains(stderr, "expected exactly one note name") {
+		t.Errorf("cat without a name = %d %q", code, stderr)
+	}
+}
+
+func TestRunNewListCat(t *testing.T) {
+	e := newTestEnv(t)
+
+	stdout := mustRun(t, e, "", "new", "work/meeting")
+	if want := filepath.Join(e.cfg.VaultDir, "work", "meeting.md") + "\n"; stdout != want {
+		t.Errorf("new printed %q, want %q", stdout, want)
+	}
+	code, _, stderr := runCommand(t, e, "", "new", "work/meeting")
+	if code != 1 || stderr == "" {
+		t.Errorf("new over an existing note = %d %q", code, stderr)
+	}
+
+	stdout = mustRun(t, e, "", "list")
+	if !strings.HasPrefix(stdout, "work/meeting.md\t") || strings.Count(stdout, "\n") != 1 {
+		t.Errorf("list printed %q", stdout)
+	}
+	stdout = mustRun(t, e, "", "list", "--json")
+	if !strings.Contains(stdout, `"work/meeting.md"`) {
+		t.Errorf("list --json printed %q", stdout)
+	}
+
+	if err := os.WriteFile(filepath.Join(e.cfg.VaultDir, "work", "meeting.md"), []byte("agenda\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if stdout := mustRun(t, e, "", "cat", "work/meeting"); stdout != "agenda\n" {
+		t.Errorf("cat printed %q", stdout)
+	}
+	code, _, _ = runCommand(t, e, "", "cat", "missing")
+	if code != 1 {
+		t.Errorf("cat of a missing note exited with %d", code)
+	}
+}
+
+func TestRunAppendPrepend(t *testing.T) {
+	e := newTestEnv(t)
+
+	mustRun(t, e, "middle\n", "append", "log")
+	mustRun(t, e, "last\n", "append", "log", "--code", "--lang", "sh")
+	mustRun(t, e, "first\n", "prepend", "log")
+	want := "first\n\nmiddle\n\n```sh\nlast\n```\n"
+	if got := readNote(t, e, "log.md"); got != want {
+		t.Errorf("log.md = %q, want %q", got, want)
+	}
+
+	code, _, stderr := runCommand(t, e, "  \n", "append", "log")
+	if code != 1 || !strings.Contains(stderr, "stdin is empty") {
+		t.Errorf("append of nothing = %d %q", code, stderr)
+	}
+	code, _, _ = runCommand(t, e, "x", "append")
+	if code != 1 {
+		t.Errorf("append without a note exited with %d", code)
+	}
+
+	mustRun(t, e, "standup\n", "append", "--daily")
+	if got := readNote(t, e, notes.DailyNoteName(time.Now())+".md"); !strings.Contains(got, "standup") {
+		t.Errorf("daily note = %q", got)
+	}
+}
+
+func TestRunSearch(t *testing.T) {
+	e := newTestEnv(t)
+	mustRun(t, e, "ship the release\nthen rest\n", "append", "plans")
+	mustRun(t, e, "nothing here\n", "append", "other")
+
+	if stdout := mustRun(t, e, "", "search", "the", "release"); stdout != "plans.md:1:ship the release\n" {
+		t.Errorf("search printed %q", stdout)
+	}
+	if stdout := mustRun(t, e, "", "search", "plans"); !strings.HasPrefix(stdout, "plans.md\n") {
+		t.Errorf("search by name printed %q", stdout)
+	}
+	if stdout := mustRun(t, e, "", "search", "zebra"); stdout != "" {
+		t.Errorf("search without matches printed %q", stdout)
+	}
+}
+
+func TestRunRm(t *testing.T) {
+	e := newTestEnv(t)
+	mustRun(t, e, "", "new", "done")
+	mustRun(t, e, "", "new", "open")
+
+	mustRun(t, e, "", "rm", "done")
+	if _, err := os.Stat(filepath.Join(e.cfg.VaultDir, "done.md")); !os.IsNotExist(err) {
+		t.Errorf("done.md still in the vault: %v", err)
+	}
+	items, err := notes.ListTrash(e.vault)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(items) != 1 || items[0].Path != "done.md" {
+		t.Errorf("trash = %+v, want done.md", items)
+	}
+
+	// A note open in another instance is refused unless forced
+	lock := notes.LockPath(e.cfg.LockDir(), "open.md")
+	if err := os.MkdirAll(filepath.Dir(lock), 0700); err != nil {
+		t.Fatal(err)
+	}
+	holder := fmt.Sprintf("1\nsomewhere.else\n%s\n", time.Now().Format(time.RFC3339))
+	if err := os.WriteFile(lock, []byte(holder), 0600); err != nil {
+		t.Fatal(err)
+	}
+	code, _, stderr := runCommand(t, e, "", "rm", "open")
+	if code != 1 || !strings.Contains(stderr, "--force") {
+		t.Errorf("rm of a locked note = %d %q", code, stderr)
+	}
+	if _, err := os.Stat(filepath.Join(e.cfg.VaultDir, "open.md")); err != nil {
+		t.Errorf("locked note was removed: %v", err)
+	}
+	mustRun(t, e, "", "rm", "--force", "open")
+	if _, err := os.Stat(filepath.Join(e.cfg.VaultDir, "open.md")); !os.IsNotExist(err) {
+		t.Errorf("forced rm left open.md: %v", err)
+	}
+}
+
+func TestRunEdit(t *testing.T) {
+	e := newTestEnv(t)
+	e.teaOptions = []tea.ProgramOption{
+		tea.WithInput(strings.NewReader("hello\x13\x03")), // type, ctrl+s, ctrl+c
+		tea.WithOutput(io.Discard),
+	}
+
+	mustRun(t, e, "", "edit", "draft")
+	if got := readNote(t, e, "draft.md"); got != "hello" {
+		t.Errorf("draft.md = %q, want %q", got, "hello")
+	}
+}
+
+func TestRunCapture(t *testing.T) {
+	e := newTestEnv(t)
+	e.teaOptions = []tea.ProgramOption{
+		tea.WithInput(strings.NewReader("call back\x13")), // type, ctrl+s
+		tea.WithOutput(io.Discard),
+	}
+
+	mustRun(t, e, "", "capture", "--inbox", "inbox")
+	if got := readNote(t, e, "inbox.md"); !strings.HasSuffix(got, "\n\ncall back\n") {
+		t.Errorf("inbox.md = %q", got)
+	}
+
+	code, _, stderr := runCommand(t, e, "", "capture", "--inbox", "../outside")
+	if code != 1 || !strings.Contains(stderr, "inbox") {
+		t.Errorf("capture to a bad inbox = %d %q", code, stderr)
+	}
+}
+
+func TestRunImport(t *testing.T) {
+	e := newTestEnv(t)
+	source := t.TempDir()
+	if err := os.WriteFile(filepath.Join(source, "todo.txt"), []byte("milk\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	if stdout := mustRun(t, e, "", "import", "--dry-run", source); stdout != "import\ttodo.md\n" {
+		t.Errorf("dry run printed %q", stdout)
+	}
+	if _, err := os.Stat(filepath.Join(e.cfg.VaultDir, "todo.md")); !os.IsNotExist(err) {
+		t.Errorf("dry run wrote todo.md: %v", err)
+	}
+
+	if stdout := mustRun(t, e, "", "import", "--into", "old", source); stdout != "imported\told/todo.md\n" {
+		t.Errorf("import printed %q", stdout)
+	}
+	if got := readNote(t, e, filepath.Join("old", "todo.md")); got != "milk\n" {
+		t.Errorf("old/todo.md = %q", got)
+	}
+
+	code, stdout, stderr := runCommand(t, e, "", "import", "--into", "old", source)
+	if code != 1 || stdout != "exists\told/todo.md\n" || !strings.Contains(stderr, "1 file already exist") {
+		t.Errorf("second import = %d %q %q", code, stdout, stderr)
+	}
+}
@@ -1,33 +1,664 @@
 package config
 
 import (
+	"errors"
+	"flag"
 	"fmt"
-	"log"
 	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
 )
 
-var (
-	VaultDir string
-)
+// DefaultVaultName is the name given to the vault set by the top-level
+// vault key when no named vault overrides it
+const DefaultVaultName = "default"
+
+// SettingsDir is the per-vault directory holding TermNote metadata
+const SettingsDir = ".termnote"
+
+// Config holds all user-configurable settings
+type Config struct {
+	VaultDir         string              // Directory where notes are stored
+	VaultName        string              // Name of the active vault
+	Vaults           map[string]string   // Named vaults: name -> directory
+	DefaultVault     string              // Vault opened when none is requested
+	DefaultExtension string              // Extension added to new notes
+	Theme            string              // Color theme name
+	Plain            bool                // ASCII-only, colorless, screen-reader friendly UI
+	Demo             bool                // Sample notes kept in memory instead of a vault
+	Inbox            string              // Note that quick captures are appended to
+	Editor           EditorConfig        // Editor behaviour
+	History          HistoryConfig       // Snapshots kept of every saved note
+	Trash            TrashConfig         // Deleted notes
+	Git              GitConfig           // Versioning of vaults that are git repositories
+	Encryption       EncryptionConfig    // Passphrase-protected notes
+	Keys             map[string][]string // Action name -> key overrides
+	Leader           string              // Key that starts a chord, empty to disable
+	WhichKeyDelay    time.Duration       // Pause before the chord popup appears
+	Path             string              // Config file that was loaded (may not exist)
+
+	global *Config // Settings before per-vault overrides were applied
+}
+
+// EditorConfig holds options for the note editor
+type EditorConfig struct {
+	ShowLineNumbers   bool
+	CharLimit         int // 0 means no limit
+	AutoContinueLists bool
+	Autosave          time.Duration // Idle time before changes are saved, 0 to disable
+}
+
+// HistoryConfig holds the retention of note snapshots
+type HistoryConfig struct {
+	Keep   int           // Snapshots kept per note, 0 for no limit
+	MaxAge time.Duration // Age after which snapshots are deleted, 0 for no limit
+}
+
+// TrashConfig holds how long deleted notes are kept
+type TrashConfig struct {
+	PurgeAfter time.Duration // Time in the trash before a note is deleted for good, 0 to keep
+}
+
+// GitConfig holds how notes in a git repository vault are versioned
+type GitConfig struct {
+	AutoCommit bool // Commit every save, delete, restore and rename
+}
+
+// EncryptionConfig holds how long passphrases of encrypted notes are remembered
+type EncryptionConfig struct {
+	CacheTimeout time.Duration // Time a passphrase is kept after its last use, 0 to always ask
+}
+
+// Default returns the built-in configuration
+func Default() (*Config, error) {
+	homeDir, err := os.UserHomeDir()
+	if err != nil {
+		return nil, fmt.Errorf("error getting home directory: %w", err)
+	}
+
+	return &Config{
+		VaultDir:         filepath.Join(homeDir, ".termnote"),
+		DefaultExtension: ".md",
+		Theme:            "auto",
+		Inbox:            "inbox",
+		Editor: EditorConfig{
+			ShowLineNumbers:   false,
+			CharLimit:         0,
+			AutoContinueLists: true,
+			Autosave:          5 * time.Second,
+		},
+		History: HistoryConfig{
+			Keep:   50,
+			MaxAge: 0,
+		},
+		Trash: TrashConfig{
+			PurgeAfter: 30 * 24 * time.Hour,
+		},
+		Git: GitConfig{
+			AutoCommit: true,
+		},
+		Encryption: EncryptionConfig{
+			CacheTimeout: 15 * time.Minute,
+		},
+		Vaults:        make(map[string]string),
+		DefaultVault:  DefaultVaultName,
+		Keys:          make(map[string][]string),
+		Leader:        "ctrl+space",
+		WhichKeyDelay: 500 * time.Millisecond,
+	}, nil
+}
+
+// DefaultPath returns the default config file location,
+// $XDG_CONFIG_HOME/termnote/config.toml or ~/.config/termnote/config.toml
+func DefaultPath() (string, error) {
+	if dir := os.Getenv("XDG_CONFIG_HOME"); dir != "" {
+		return filepath.Join(dir, "termnote", "config.toml"), nil
+	}
 
-func InitConfig() error {
 	homeDir, err := os.UserHomeDir()
 	if err != nil {
-		return fmt.Errorf("error getting home directory: %w", err)
+		return "", fmt.Errorf("error getting home directory: %w", err)
+	}
+	return filepath.Join(homeDir, ".config", "termnote", "config.toml"), nil
+}
+
+// Load builds the configuration from defaults, the config file,
+// TERMNOTE_* environment variables and command-line flags, in that order
+// of precedence. It returns the arguments left over after flag parsing.
+func Load(args []string) (*Config, []string, error) {
+	cfg, err := Default()
+	if err != nil {
+		return nil, nil, err
+	}
+
+	fs := flag.NewFlagSet("termnote", flag.ContinueOnError)
+	configPath := fs.String("config", "", "path to config file")
+	vaultName := fs.String("vault", "", "name of the vault to open")
+	vaultDir := fs.String("vault-dir", "", "directory where notes are stored")
+	extension := fs.String("ext", "", "extension for new notes")
+	theme := fs.String("theme", "", "color theme")
+	plain := fs.Bool("plain", false, "plain ASCII interface without color")
+	demo := fs.Bool("demo", false, "try TermNote on sample notes kept in memory")
+	if err := fs.Parse(args); err != nil {
+		return nil, nil, err
 	}
 
-	VaultDir = fmt.Sprintf("%s/.termnote", homeDir)
+	// Resolve which config file to read
+	cfg.Path = *configPath
+	if cfg.Path == "" {
+		cfg.Path = os.Getenv("TERMNOTE_CONFIG")
+	}
+	if cfg.Path == "" {
+		if cfg.Path, err = DefaultPath(); err != nil {
+			return nil, nil, err
+		}
+	}
+	cfg.Path = ExpandHome(cfg.Path)
+
+	if err := cfg.loadFile(cfg.Path); err != nil {
+		return nil, nil, err
+	}
+
+	if err := cfg.applyEnv(); err != nil {
+		return nil, nil, err
+	}
+
+	// Flags win over everything else
+	if *vaultDir != "" {
+		cfg.VaultDir = *vaultDir
+	}
+	if *extension != "" {
+		cfg.DefaultExtension = *extension
+	}
+	if *theme != "" {
+		cfg.Theme = *theme
+	}
+	if *plain {
+		cfg.Plain = true
+	}
+
+	if *vaultName != "" {
+		cfg.DefaultVault = *vaultName
+	}
+
+	cfg.normalize()
+	// An explicit vault directory wins over the one the config file gives
+	// the selected vault
+	explicitDir := *vaultDir
+	if explicitDir == "" {
+		explicitDir = os.Getenv("TERMNOTE_VAULT_DIR")
+	}
+	if explicitDir != "" {
+		cfg.VaultDir = filepath.Clean(ExpandHome(explicitDir))
+		cfg.Vaults[cfg.DefaultVault] = cfg.VaultDir
+	}
+	if *demo {
+		if err := cfg.useDemo(); err != nil {
+			return nil, nil, err
+		}
+	}
+	cfg.global = cfg.clone()
 
-	err = os.MkdirAll(VaultDir, 0750)
+	active, err := cfg.UseVault(cfg.DefaultVault)
 	if err != nil {
-		return fmt.Errorf("error creating vault directory: %w", err)
+		return nil, nil, err
 	}
 
+	return active, fs.Args(), nil
+}
+
+// DemoVaultName is the only vault in demo mode
+const DemoVaultName = "demo"
+
+// useDemo replaces the configured vaults with the demo vault. Its notes
+// live in memory; the history, swap files and locks of the session go to a
+// temp directory that the caller removes on exit.
+func (c *Config) useDemo() error {
+	dir, err := os.MkdirTemp("", "termnote-demo-")
+	if err != nil {
+		return fmt.Errorf("error creating demo directory: %w", err)
+	}
+	c.Demo = true
+	c.VaultDir = dir
+	c.Vaults = map[string]string{DemoVaultName: dir}
+	c.DefaultVault = DemoVaultName
 	return nil
 }
 
-func init() {
-	if err := InitConfig(); err != nil {
-		log.Fatal(err)
+// UseVault returns a copy of the configuration pointed at the named vault,
+// with that vault's .termnote/settings.toml applied on top of the global settings
+func (c *Config) UseVault(name string) (*Config, error) {
+	global := c.global
+	if global == nil {
+		global = c
+	}
+
+	dir, ok := global.Vaults[name]
+	if !ok {
+		return nil, fmt.Errorf("unknown vault %q (available: %s)", name, strings.Join(global.VaultNames(), ", "))
+	}
+
+	v := global.clone()
+	v.global = global
+	v.VaultName = name
+	v.VaultDir = dir
+
+	if err := os.MkdirAll(v.VaultDir, 0750); err != nil {
+		return nil, fmt.Errorf("error creating vault directory: %w", err)
+	}
+
+	if err := v.loadVaultSettings(); err != nil {
+		return nil, err
+	}
+	v.normalize()
+
+	return v, nil
+}
+
+// VaultNames returns the names of all configured vaults in sorted order
+func (c *Config) VaultNames() []string {
+	names := make([]string, 0, len(c.Vaults))
+	for name := range c.Vaults {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// ThemesDir returns the directory holding user theme files, next to the config file
+func (c *Config) ThemesDir() string {
+	return filepath.Join(filepath.Dir(c.Path), "themes")
+}
+
+// LoadThemes reads every <name>.toml file in the themes directory and returns
+// its color settings by theme name. A missing directory is not an error.
+func (c *Config) LoadThemes() (map[string]map[string]string, error) {
+	dir := c.ThemesDir()
+	entries, err := os.ReadDir(dir)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("error reading themes: %w", err)
+	}
+
+	themes := make(map[string]map[string]string)
+	for _, entry := range entries {
+		if entry.IsDir() || filepath.Ext(entry.Name()) != ".toml" {
+			continue
+		}
+
+		path := filepath.Join(dir, entry.Name())
+		f, err := os.Open(path)
+		if err != nil {
+			return nil, fmt.Errorf("error opening theme: %w", err)
+		}
+		data, err := parseTOML(f)
+		f.Close()
+		if err != nil {
+			return nil, fmt.Errorf("error parsing %s: %w", path, err)
+		}
+
+		colors := make(map[string]string, len(data))
+		for key := range data {
+			var value string
+			if err := setString(data, key, &value); err != nil {
+				return nil, fmt.Errorf("error in %s: %w", path, err)
+			}
+			colors[key] = value
+		}
+		themes[strings.TrimSuffix(entry.Name(), ".toml")] = colors
+	}
+
+	return themes, nil
+}
+
+// SettingsPath returns the location of the active vault's settings file
+func (c *Config) SettingsPath() string {
+	return filepath.Join(c.VaultDir, SettingsDir, "settings.toml")
+}
+
+// SwapDir returns the directory holding swap files of the active vault
+func (c *Config) SwapDir() string {
+	return filepath.Join(c.VaultDir, SettingsDir, "swap")
+}
+
+// HistoryDir returns the directory holding note snapshots of the active vault
+func (c *Config) HistoryDir() string {
+	return filepath.Join(c.VaultDir, SettingsDir, "history")
+}
+
+// LockDir returns the directory holding the note locks of the active vault
+func (c *Config) LockDir() string {
+	return filepath.Join(c.VaultDir, SettingsDir, "locks")
+}
+
+// loadVaultSettings merges the active vault's settings file. Only
+// note-related settings are honoured there; vault paths and keys stay global.
+func (c *Config) loadVaultSettings() error {
+	path := c.SettingsPath()
+	f, err := os.Open(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("error opening vault settings: %w", err)
 	}
+	defer f.Close()
+
+	data, err := parseTOML(f)
+	if err != nil {
+		return fmt.Errorf("error parsing %s: %w", path, err)
+	}
+
+	delete(data, "vault")
+	delete(data, "vaults")
+	delete(data, "default_vault")
+	delete(data, "keys")
+	delete(data, "leader")
+	delete(data, "plain")
+
+	if err := c.apply(data); err != nil {
+		return fmt.Errorf("error in %s: %w", path, err)
+	}
+	return nil
+}
+
+// clone returns a deep copy of the configuration
+func (c *Config) clone() *Config {
+	cp := *c
+	cp.Vaults = make(map[string]string, len(c.Vaults))
+	for name, dir := range c.Vaults {
+		cp.Vaults[name] = dir
+	}
+	cp.Keys = make(map[string][]string, len(c.Keys))
+	for action, bindings := range c.Keys {
+		cp.Keys[action] = append([]string(nil), bindings...)
+	}
+	return &cp
+}
+
+// loadFile merges settings from a TOML config file. A missing file is not an error.
+func (c *Config) loadFile(path string) error {
+	f, err := os.Open(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("error opening config file: %w", err)
+	}
+	defer f.Close()
+
+	data, err := parseTOML(f)
+	if err != nil {
+		return fmt.Errorf("error parsing %s: %w", path, err)
+	}
+
+	if err := c.apply(data); err != nil {
+		return fmt.Errorf("error in %s: %w", path, err)
+	}
+	return nil
+}
+
+// apply copies recognised keys from parsed TOML data into the config
+func (c *Config) apply(data map[string]any) error {
+	if err := setString(data, "vault", &c.VaultDir); err != nil {
+		return err
+	}
+	if err := setString(data, "default_extension", &c.DefaultExtension); err != nil {
+		return err
+	}
+	if err := setString(data, "theme", &c.Theme); err != nil {
+		return err
+	}
+	if err := setString(data, "inbox", &c.Inbox); err != nil {
+		return err
+	}
+	if err := setBool(data, "plain", &c.Plain); err != nil {
+		return err
+	}
+	if err := setString(data, "default_vault", &c.DefaultVault); err != nil {
+		return err
+	}
+	if err := setString(data, "leader", &c.Leader); err != nil {
+		return err
+	}
+	delay := int(c.WhichKeyDelay / time.Millisecond)
+	if err := setInt(data, "which_key_delay", &delay); err != nil {
+		return err
+	}
+	c.WhichKeyDelay = time.Duration(delay) * time.Millisecond
+
+	if vaults, ok := data["vaults"].(map[string]any); ok {
+		for name := range vaults {
+			var dir string
+			if err := setString(vaults, name, &dir); err != nil {
+				return err
+			}
+			c.Vaults[name] = dir
+		}
+	}
+
+	if editor, ok := data["editor"].(map[string]any); ok {
+		if err := setBool(editor, "show_line_numbers", &c.Editor.ShowLineNumbers); err != nil {
+			return err
+		}
+		if err := setInt(editor, "char_limit", &c.Editor.CharLimit); err != nil {
+			return err
+		}
+		if err := setBool(editor, "auto_continue_lists", &c.Editor.AutoContinueLists); err != nil {
+			return err
+		}
+		autosave := int(c.Editor.Autosave / time.Second)
+		if err := setInt(editor, "autosave", &autosave); err != nil {
+			return err
+		}
+		c.Editor.Autosave = time.Duration(autosave) * time.Second
+	}
+
+	if history, ok := data["history"].(map[string]any); ok {
+		if err := setInt(history, "keep", &c.History.Keep); err != nil {
+			return err
+		}
+		days := int(c.History.MaxAge / (24 * time.Hour))
+		if err := setInt(history, "max_age", &days); err != nil {
+			return err
+		}
+		c.History.MaxAge = time.Duration(days) * 24 * time.Hour
+	}
+
+	if trash, ok := data["trash"].(map[string]any); ok {
+		days := int(c.Trash.PurgeAfter / (24 * time.Hour))
+		if err := setInt(trash, "purge_after", &days); err != nil {
+			return err
+		}
+		c.Trash.PurgeAfter = time.Duration(days) * 24 * time.Hour
+	}
+
+	if git, ok := data["git"].(map[string]any); ok {
+		if err := setBool(git, "auto_commit", &c.Git.AutoCommit); err != nil {
+			return err
+		}
+	}
+
+	if encryption, ok := data["encryption"].(map[string]any); ok {
+		minutes := int(c.Encryption.CacheTimeout / time.Minute)
+		if err := setInt(encryption, "cache_timeout", &minutes); err != nil {
+			return err
+		}
+		c.Encryption.CacheTimeout = time.Duration(minutes) * time.Minute
+	}
+
+	// [keys] maps action names to keys; [keys.<view>] tables scope
+	// overrides to one view and are stored as "<view>.<action>"
+	if keys, ok := data["keys"].(map[string]any); ok {
+		for name, value := range keys {
+			if scoped, ok := value.(map[string]any); ok {
+				for action := range scoped {
+					var bindings []string
+					if err := setStrings(scoped, action, &bindings); err != nil {
+						return err
+					}
+					c.Keys[name+"."+action] = bindings
+				}
+				continue
+			}
+
+			var bindings []string
+			if err := setStrings(keys, name, &bindings); err != nil {
+				return err
+			}
+			c.Keys[name] = bindings
+		}
+	}
+
+	return nil
+}
+
+// applyEnv applies TERMNOTE_* environment variable overrides
+func (c *Config) applyEnv() error {
+	if v := os.Getenv("TERMNOTE_VAULT_DIR"); v != "" {
+		c.VaultDir = v
+	}
+	if v := os.Getenv("TERMNOTE_VAULT"); v != "" {
+		c.DefaultVault = v
+	}
+	if v := os.Getenv("TERMNOTE_EXTENSION"); v != "" {
+		c.DefaultExtension = v
+	}
+	if v := os.Getenv("TERMNOTE_THEME"); v != "" {
+		c.Theme = v
+	}
+	if v := os.Getenv("TERMNOTE_INBOX"); v != "" {
+		c.Inbox = v
+	}
+	// NO_COLOR (https://no-color.org) turns on plain mode when set to anything
+	if os.Getenv("NO_COLOR") != "" {
+		c.Plain = true
+	}
+	if v := os.Getenv("TERMNOTE_PLAIN"); v != "" {
+		b, err := strconv.ParseBool(v)
+		if err != nil {
+			return fmt.Errorf("TERMNOTE_PLAIN must be true or false")
+		}
+		c.Plain = b
+	}
+	if v := os.Getenv("TERMNOTE_SHOW_LINE_NUMBERS"); v != "" {
+		b, err := strconv.ParseBool(v)
+		if err != nil {
+			return fmt.Errorf("TERMNOTE_SHOW_LINE_NUMBERS must be true or false")
+		}
+		c.Editor.ShowLineNumbers = b
+	}
+	return nil
+}
+
+// normalize cleans up values so the rest of the app can rely on them
+func (c *Config) normalize() {
+	c.VaultDir = filepath.Clean(ExpandHome(c.VaultDir))
+	for name, dir := range c.Vaults {
+		c.Vaults[name] = filepath.Clean(ExpandHome(dir))
+	}
+	// The top-level vault key becomes the "default" vault unless named
+	// vaults are configured and another one is chosen as the default
+	if _, ok := c.Vaults[DefaultVaultName]; !ok && (len(c.Vaults) == 0 || c.DefaultVault == DefaultVaultName) {
+		c.Vaults[DefaultVaultName] = c.VaultDir
+	}
+	if c.DefaultExtension != "" && !strings.HasPrefix(c.DefaultExtension, ".") {
+		c.DefaultExtension = "." + c.DefaultExtension
+	}
+	if c.Editor.CharLimit < 0 {
+		c.Editor.CharLimit = 0
+	}
+	if c.Editor.Autosave < 0 {
+		c.Editor.Autosave = 0
+	}
+	if c.History.Keep < 0 {
+		c.History.Keep = 0
+	}
+	if c.History.MaxAge < 0 {
+		c.History.MaxAge = 0
+	}
+	if c.Trash.PurgeAfter < 0 {
+		c.Trash.PurgeAfter = 0
+	}
+}
+
+// ExpandHome replaces a leading ~ with the user's home directory
+func ExpandHome(path string) string {
+	if path != "~" && !strings.HasPrefix(path, "~/") {
+		return path
+	}
+	homeDir, err := os.UserHomeDir()
+	if err != nil {
+		return path
+	}
+	return filepath.Join(homeDir, strings.TrimPrefix(path, "~"))
+}
+
+func setString(table map[string]any, key string, dst *string) error {
+	v, ok := table[key]
+	if !ok {
+		return nil
+	}
+	s, ok := v.(string)
+	if !ok {
+		return fmt.Errorf("%s must be a string", key)
+	}
+	*dst = s
+	return nil
+}
+
+func setBool(table map[string]any, key string, dst *bool) error {
+	v, ok := table[key]
+	if !ok {
+		return nil
+	}
+	b, ok := v.(bool)
+	if !ok {
+		return fmt.Errorf("%s must be true or false", key)
+	}
+	*dst = b
+	return nil
+}
+
+func setInt(table map[string]any, key string, dst *int) error {
+	v, ok := table[key]
+	if !ok {
+		return nil
+	}
+	n, ok := v.(int64)
+	if !ok {
+		return fmt.Errorf("%s must be an integer", key)
+	}
+	*dst = int(n)
+	return nil
+}
+
+// setStrings accepts either a single string or an array of strings
+func setStrings(table map[string]any, key string, dst *[]string) error {
+	v, ok := table[key]
+	if !ok {
+		return nil
+	}
+	switch v := v.(type) {
+	case string:
+		*dst = []string{v}
+	case []any:
+		out := make([]string, 0, len(v))
+		for _, el := range v {
+			s, ok := el.(string)
+			if !ok {
+				return fmt.Errorf("%s must be a list of strings", key)
+			}
+			out = append(out, s)
+		}
+		*dst = out
+	default:
+		return fmt.Errorf("%s must be a string or list of strings", key)
+	}
+	return nil
 }
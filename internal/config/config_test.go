@@ -0,0 +1,87 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// writeConfig writes a config file to a fresh home directory and points
+// the environment at it
+func writeConfig(t *testing.T, content string) string {
+	t.Helper()
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	t.Setenv("XDG_CONFIG_HOME", "")
+	t.Setenv("TERMNOTE_CONFIG", "")
+	t.Setenv("TERMNOTE_VAULT_DIR", "")
+	t.Setenv("TERMNOTE_VAULT", "")
+
+	path := filepath.Join(home, "config.toml")
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestLoadExplicitVaultDirWins(t *testing.T) {
+	explicit := t.TempDir()
+
+	tests := []struct {
+		name   string
+		config string
+		args   []string
+		env    string
+	}{
+		{
+			name:   "flag over named default vault",
+			config: "[vaults]\ndefault = \"~/file-default\"\n",
+			args:   []string{"--vault-dir", explicit},
+		},
+		{
+			name:   "flag over default_vault",
+			config: "default_vault = \"work\"\n[vaults]\nwork = \"~/file-work\"\n",
+			args:   []string{"--vault-dir", explicit},
+		},
+		{
+			name:   "env over named default vault",
+			config: "[vaults]\ndefault = \"~/file-default\"\n",
+			env:    explicit,
+		},
+		{
+			name:   "env over top-level vault",
+			config: "vault = \"~/file-vault\"\n",
+			env:    explicit,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := writeConfig(t, tt.config)
+			if tt.env != "" {
+				t.Setenv("TERMNOTE_VAULT_DIR", tt.env)
+			}
+
+			cfg, _, err := Load(append([]string{"--config", path}, tt.args...))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if cfg.VaultDir != explicit {
+				t.Errorf("VaultDir = %q, want %q", cfg.VaultDir, explicit)
+			}
+		})
+	}
+}
+
+func TestLoadVaultFromFile(t *testing.T) {
+	path := writeConfig(t, "default_vault = \"work\"\n[vaults]\nwork = \"~/work\"\npersonal = \"~/personal\"\n")
+	home := os.Getenv("HOME")
+
+	cfg, _, err := Load([]string{"--config", path})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := filepath.Join(home, "work"); cfg.VaultDir != want || cfg.VaultName != "work" {
+		t.Errorf("vault = %s at %q, want work at %q", cfg.VaultName, cfg.VaultDir, want)
+	}
+}
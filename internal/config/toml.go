@@ -0,0 +1,252 @@
+package config
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+)
+
+// parseTOML parses the subset of TOML used by TermNote config files:
+// tables ([a] and [a.b]), comments, and key = value pairs where values are
+// strings, integers, booleans or arrays of those.
+func parseTOML(r io.Reader) (map[string]any, error) {
+	root := make(map[string]any)
+	current := root
+
+	scanner := bufio.NewScanner(r)
+	lineNo := 0
+	for scanner.Scan() {
+		lineNo++
+		line := strings.TrimSpace(stripComment(scanner.Text()))
+		if line == "" {
+			continue
+		}
+
+		// Table header
+		if strings.HasPrefix(line, "[") {
+			if !strings.HasSuffix(line, "]") {
+				return nil, fmt.Errorf("line %d: unterminated table header", lineNo)
+			}
+			name := strings.TrimSpace(line[1 : len(line)-1])
+			if name == "" {
+				return nil, fmt.Errorf("line %d: empty table name", lineNo)
+			}
+			table, err := lookupTable(root, name)
+			if err != nil {
+				return nil, fmt.Errorf("line %d: %w", lineNo, err)
+			}
+			current = table
+			continue
+		}
+
+		eq := strings.Index(line, "=")
+		if eq == -1 {
+			return nil, fmt.Errorf("line %d: expected key = value", lineNo)
+		}
+		key := unquoteKey(strings.TrimSpace(line[:eq]))
+		raw := strings.TrimSpace(line[eq+1:])
+		if key == "" {
+			return nil, fmt.Errorf("line %d: empty key", lineNo)
+		}
+
+		// Arrays may span multiple lines
+		if strings.HasPrefix(raw, "[") {
+			for !arrayClosed(raw) && scanner.Scan() {
+				lineNo++
+				raw += " " + strings.TrimSpace(stripComment(scanner.Text()))
+			}
+		}
+
+		value, err := parseValue(raw)
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %w", lineNo, err)
+		}
+		current[key] = value
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+
+	return root, nil
+}
+
+// lookupTable returns the (possibly nested) table for a dotted name,
+// creating intermediate tables as needed
+func lookupTable(root map[string]any, name string) (map[string]any, error) {
+	table := root
+	for _, part := range splitDotted(name) {
+		part = unquoteKey(strings.TrimSpace(part))
+		next, ok := table[part]
+		if !ok {
+			child := make(map[string]any)
+			table[part] = child
+			table = child
+			continue
+		}
+		child, ok := next.(map[string]any)
+		if !ok {
+			return nil, fmt.Errorf("%q is not a table", part)
+		}
+		table = child
+	}
+	return table, nil
+}
+
+// splitDotted splits a dotted table name at the dots outside quotes
+func splitDotted(name string) []string {
+	var parts []string
+	var quote byte
+	start := 0
+	for i := 0; i < len(name); i++ {
+		c := name[i]
+		switch {
+		case quote != 0:
+			if c == quote {
+				quote = 0
+			}
+		case c == '"' || c == '\'':
+			quote = c
+		case c == '.':
+			parts = append(parts, name[start:i])
+			start = i + 1
+		}
+	}
+	return append(parts, name[start:])
+}
+
+// stripComment removes a trailing # comment that is not inside a string
+func stripComment(line string) string {
+	var quote byte
+	for i := 0; i < len(line); i++ {
+		c := line[i]
+		switch {
+		case quote != 0:
+			if c == '\\' && quote == '"' {
+				i++
+			} else if c == quote {
+				quote = 0
+			}
+		case c == '"' || c == '\'':
+			quote = c
+		case c == '#':
+			return line[:i]
+		}
+	}
+	return line
+}
+
+// arrayClosed reports whether the brackets in an array literal are balanced
+func arrayClosed(raw string) bool {
+	depth := 0
+	var quote byte
+	for i := 0; i < len(raw); i++ {
+		c := raw[i]
+		switch {
+		case quote != 0:
+			if c == '\\' && quote == '"' {
+				i++
+			} else if c == quote {
+				quote = 0
+			}
+		case c == '"' || c == '\'':
+			quote = c
+		case c == '[':
+			depth++
+		case c == ']':
+			depth--
+		}
+	}
+	return depth <= 0
+}
+
+func unquoteKey(key string) string {
+	if len(key) >= 2 && (key[0] == '"' || key[0] == '\'') && key[len(key)-1] == key[0] {
+		return key[1 : len(key)-1]
+	}
+	return key
+}
+
+// parseValue converts a raw TOML value into a Go value
+func parseValue(raw string) (any, error) {
+	switch {
+	case raw == "":
+		return nil, fmt.Errorf("missing value")
+	case raw == "true":
+		return true, nil
+	case raw == "false":
+		return false, nil
+	case raw[0] == '"':
+		if len(raw) < 2 || raw[len(raw)-1] != '"' {
+			return nil, fmt.Errorf("unterminated string")
+		}
+		s, err := strconv.Unquote(raw)
+		if err != nil {
+			return nil, fmt.Errorf("invalid string %s", raw)
+		}
+		return s, nil
+	case raw[0] == '\'':
+		if len(raw) < 2 || raw[len(raw)-1] != '\'' {
+			return nil, fmt.Errorf("unterminated string")
+		}
+		return raw[1 : len(raw)-1], nil
+	case raw[0] == '[':
+		return parseArray(raw)
+	}
+
+	n, err := strconv.ParseInt(strings.ReplaceAll(raw, "_", ""), 10, 64)
+	if err != nil {
+		return nil, fmt.Errorf("unsupported value %q", raw)
+	}
+	return n, nil
+}
+
+// parseArray parses a single-level array literal
+func parseArray(raw string) ([]any, error) {
+	if !strings.HasSuffix(raw, "]") {
+		return nil, fmt.Errorf("unterminated array")
+	}
+	body := strings.TrimSpace(raw[1 : len(raw)-1])
+
+	values := make([]any, 0)
+	var quote byte
+	start := 0
+	for i := 0; i <= len(body); i++ {
+		if i < len(body) {
+			c := body[i]
+			if quote != 0 {
+				if c == '\\' && quote == '"' {
+					if i+1 == len(body) {
+						return nil, fmt.Errorf("unterminated string")
+					}
+					i++
+				} else if c == quote {
+					quote = 0
+				}
+				continue
+			}
+			if c == '"' || c == '\'' {
+				quote = c
+				continue
+			}
+			if c != ',' {
+				continue
+			}
+		}
+
+		element := strings.TrimSpace(body[start:i])
+		start = i + 1
+		if element == "" {
+			// Allow a trailing comma
+			continue
+		}
+		v, err := parseValue(element)
+		if err != nil {
+			return nil, err
+		}
+		values = append(values, v)
+	}
+
+	return values, nil
+}
@@ -0,0 +1,195 @@
+package config
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestParseTOML(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+		want  map[string]any
+	}{
+		{
+			name:  "basic string",
+			input: `theme = "dark"`,
+			want:  map[string]any{"theme": "dark"},
+		},
+		{
+			name:  "string escapes",
+			input: `path = "C:\\notes\t\"quoted\"\n\u00e9"`,
+			want:  map[string]any{"path": "C:\\notes\t\"quoted\"\né"},
+		},
+		{
+			name:  "literal string keeps backslashes",
+			input: `path = 'C:\notes\n'`,
+			want:  map[string]any{"path": `C:\notes\n`},
+		},
+		{
+			name:  "hash inside strings",
+			input: `a = "#not a comment" # a comment` + "\n" + `b = '#also not'`,
+			want:  map[string]any{"a": "#not a comment", "b": "#also not"},
+		},
+		{
+			name:  "integers",
+			input: "a = 42\nb = -7\nc = 1_000",
+			want:  map[string]any{"a": int64(42), "b": int64(-7), "c": int64(1000)},
+		},
+		{
+			name:  "booleans",
+			input: "a = true\nb = false",
+			want:  map[string]any{"a": true, "b": false},
+		},
+		{
+			name:  "arrays",
+			input: `a = ["ctrl+s", 'alt+s', ]` + "\nb = []\nc = [1, 2]",
+			want: map[string]any{
+				"a": []any{"ctrl+s", "alt+s"},
+				"b": []any{},
+				"c": []any{int64(1), int64(2)},
+			},
+		},
+		{
+			name:  "multi-line array with comments",
+			input: "a = [\n  \"x\", # first\n  \"]\",\n]\nb = 1",
+			want:  map[string]any{"a": []any{"x", "]"}, "b": int64(1)},
+		},
+		{
+			name:  "tables and nested tables",
+			input: "top = 1\n[editor]\nautosave = 10\n[keys.editor]\nsave = \"ctrl+w\"\n[keys]\nquit = \"q\"",
+			want: map[string]any{
+				"top":    int64(1),
+				"editor": map[string]any{"autosave": int64(10)},
+				"keys": map[string]any{
+					"editor": map[string]any{"save": "ctrl+w"},
+					"quit":   "q",
+				},
+			},
+		},
+		{
+			name:  "quoted keys and table names",
+			input: "[vaults]\n\"my notes\" = \"~/notes\"\n['a.b']\nx = 1",
+			want: map[string]any{
+				"vaults": map[string]any{"my notes": "~/notes"},
+				"a.b":    map[string]any{"x": int64(1)},
+			},
+		},
+		{
+			name:  "comments and blank lines",
+			input: "# header\n\n   # indented\ntheme = \"light\"   # trailing\n",
+			want:  map[string]any{"theme": "light"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := parseTOML(strings.NewReader(tt.input))
+			if err != nil {
+				t.Fatalf("parseTOML: %v", err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("parseTOML = %#v, want %#v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseTOMLErrors(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+		want  string
+	}{
+		{"unterminated table header", "[editor", "line 1: unterminated table header"},
+		{"empty table name", "[ ]", "line 1: empty table name"},
+		{"missing equals", "theme", "line 1: expected key = value"},
+		{"empty key", "= 1", "line 1: empty key"},
+		{"missing value", "a =", "line 1: missing value"},
+		{"unterminated string", `a = "dark`, "line 1: unterminated string"},
+		{"unterminated literal string", "a = 'dark", "line 1: unterminated string"},
+		{"invalid escape", `a = "\q"`, "line 1: invalid string"},
+		{"unsupported value", "a = yes", `line 1: unsupported value "yes"`},
+		{"unterminated array", "a = [1, 2", "line 1: unterminated array"},
+		{"bad array element", "a = [1, nope]", `line 1: unsupported value "nope"`},
+		{"escape cut off in array", `a = ["a", "b\ ]`, "line 1: unterminated string"},
+		{"table over a value", "keys = 1\n[keys.editor]", `line 2: "keys" is not a table`},
+		{"line numbers count", "a = 1\n\n# c\nb = ?", `line 4: unsupported value "?"`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := parseTOML(strings.NewReader(tt.input))
+			if err == nil {
+				t.Fatalf("parseTOML(%q) succeeded, want error %q", tt.input, tt.want)
+			}
+			if !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("error = %q, want %q", err, tt.want)
+			}
+		})
+	}
+}
+
+func TestApplyDurations(t *testing.T) {
+	input := `
+which_key_delay = 250
+[editor]
+autosave = 10
+[history]
+max_age = 7
+[trash]
+purge_after = 0
+[encryption]
+cache_timeout = 30
+`
+	data, err := parseTOML(strings.NewReader(input))
+	if err != nil {
+		t.Fatal(err)
+	}
+	cfg := &Config{Vaults: map[string]string{}, Keys: map[string][]string{}}
+	cfg.Trash.PurgeAfter = 30 * 24 * time.Hour
+	if err := cfg.apply(data); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, tt := range []struct {
+		name      string
+		got, want time.Duration
+	}{
+		{"which_key_delay", cfg.WhichKeyDelay, 250 * time.Millisecond},
+		{"editor.autosave", cfg.Editor.Autosave, 10 * time.Second},
+		{"history.max_age", cfg.History.MaxAge, 7 * 24 * time.Hour},
+		{"trash.purge_after", cfg.Trash.PurgeAfter, 0},
+		{"encryption.cache_timeout", cfg.Encryption.CacheTimeout, 30 * time.Minute},
+	} {
+		if tt.got != tt.want {
+			t.Errorf("%s = %v, want %v", tt.name, tt.got, tt.want)
+		}
+	}
+}
+
+func TestApplyTypeErrors(t *testing.T) {
+	tests := []struct {
+		input string
+		want  string
+	}{
+		{`theme = 1`, "theme must be a string"},
+		{`plain = "yes"`, "plain must be true or false"},
+		{"[editor]\nautosave = \"5s\"", "autosave must be an integer"},
+		{"[keys]\nsave = [1]", "save must be a list of strings"},
+		{"[keys]\nsave = true", "save must be a string or list of strings"},
+	}
+
+	for _, tt := range tests {
+		data, err := parseTOML(strings.NewReader(tt.input))
+		if err != nil {
+			t.Fatalf("parseTOML(%q): %v", tt.input, err)
+		}
+		cfg := &Config{Vaults: map[string]string{}, Keys: map[string][]string{}}
+		if err := cfg.apply(data); err == nil || err.Error() != tt.want {
+			t.Errorf("apply(%q) = %v, want %q", tt.input, err, tt.want)
+		}
+	}
+}
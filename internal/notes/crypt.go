@@ -0,0 +1,283 @@
+package notes
+
+import (
+	"bytes"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/pbkdf2"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io/fs"
+	"math/bits"
+	"strings"
+)
+
+// EncryptedExt is added to the filename of encrypted notes, e.g. "secrets.md.enc"
+const EncryptedExt = ".enc"
+
+var (
+	// ErrWrongPassphrase is returned when an encrypted note cannot be
+	// decrypted with the given passphrase or key
+	ErrWrongPassphrase = errors.New("wrong passphrase or damaged note")
+	// ErrNotEncrypted is returned when a file does not hold an encrypted note
+	ErrNotEncrypted = errors.New("not an encrypted note")
+	// ErrEncrypted is returned when a plain text operation is asked of an
+	// encrypted note
+	ErrEncrypted = errors.New("note is encrypted")
+)
+
+// encryptedMagic starts every encrypted note. The NUL byte makes git and
+// other tools treat the file as binary.
+const encryptedMagic = "\x00termnote-enc-v1\n"
+
+// scrypt parameters for new keys (N = 2^15, r = 8, p = 1) and the salt size
+const (
+	scryptLogN = 15
+	scryptR    = 8
+	scryptP    = 1
+	saltSize   = 16
+)
+
+// Limits on the scrypt parameters of a note being opened, so that a crafted
+// header cannot make deriving its key take gigabytes of memory or minutes
+const (
+	scryptMaxMem = 256 << 20 // Bytes of the table ROMix fills, 128 * r * N
+	scryptMaxP   = 4
+)
+
+// headerSize is the length of the magic, scrypt parameters and salt that
+// start an encrypted note
+const headerSize = len(encryptedMagic) + 3 + saltSize
+
+// Key is an AES-256-GCM key derived from a passphrase with scrypt. An
+// encrypted note is its header (magic, scrypt parameters and salt), a random
+// nonce and the sealed content, with the header authenticated too.
+type Key struct {
+	header []byte
+	aead   cipher.AEAD
+}
+
+// IsEncrypted reports whether a filename names an encrypted note
+func IsEncrypted(filename string) bool {
+	return strings.HasSuffix(filename, EncryptedExt)
+}
+
+// NewKey derives a key from a passphrase with a new random salt
+func NewKey(passphrase string) (*Key, error) {
+	salt := make([]byte, saltSize)
+	if _, err := rand.Read(salt); err != nil {
+		return nil, fmt.Errorf("error generating salt: %w", err)
+	}
+	header := append([]byte(encryptedMagic), scryptLogN, scryptR, scryptP)
+	return newKey(passphrase, append(header, salt...))
+}
+
+// DeriveKey derives the key that opens an encrypted note from its
+// passphrase, returning ErrWrongPassphrase if it does not open it
+func DeriveKey(passphrase string, data []byte) (*Key, error) {
+	header, err := encryptedHeader(data)
+	if err != nil {
+		return nil, err
+	}
+	key, err := newKey(passphrase, header)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := key.Decrypt(data); err != nil {
+		return nil, err
+	}
+	return key, nil
+}
+
+// encryptedHeader returns the header of an encrypted note. Parameters that
+// would take unreasonable time or memory are refused.
+func encryptedHeader(data []byte) ([]byte, error) {
+	if len(data) < headerSize || !bytes.HasPrefix(data, []byte(encryptedMagic)) {
+		return nil, ErrNotEncrypted
+	}
+	logN, r, p := data[len(encryptedMagic)], data[len(encryptedMagic)+1], data[len(encryptedMagic)+2]
+	if logN < 1 || logN > 22 || r < 1 || p < 1 || p > scryptMaxP || uint64(128*int(r))<<logN > scryptMaxMem {
+		return nil, ErrNotEncrypted
+	}
+	return data[:headerSize:headerSize], nil
+}
+
+// newKey derives the key for a header
+func newKey(passphrase string, header []byte) (*Key, error) {
+	params := header[len(encryptedMagic):]
+	raw, err := scrypt([]byte(passphrase), params[3:], 1<<params[0], int(params[1]), int(params[2]), 32)
+	if err != nil {
+		return nil, fmt.Errorf("error deriving key: %w", err)
+	}
+	block, err := aes.NewCipher(raw)
+	if err != nil {
+		return nil, fmt.Errorf("error deriving key: %w", err)
+	}
+	aead, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, fmt.Errorf("error deriving key: %w", err)
+	}
+	return &Key{header: header, aead: aead}, nil
+}
+
+// Matches reports whether data was encrypted with the salt and parameters
+// of this key, so that the key may open it
+func (k *Key) Matches(data []byte) bool {
+	return bytes.HasPrefix(data, k.header)
+}
+
+// Encrypt seals a note's content with a fresh nonce
+func (k *Key) Encrypt(plaintext []byte) ([]byte, error) {
+	nonce := make([]byte, k.aead.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return nil, fmt.Errorf("error encrypting note: %w", err)
+	}
+	out := append(append([]byte(nil), k.header...), nonce...)
+	return k.aead.Seal(out, nonce, plaintext, k.header), nil
+}
+
+// Decrypt opens an encrypted note
+func (k *Key) Decrypt(data []byte) ([]byte, error) {
+	if _, err := encryptedHeader(data); err != nil {
+		return nil, err
+	}
+	if !k.Matches(data) || len(data) < headerSize+k.aead.NonceSize() {
+		return nil, ErrWrongPassphrase
+	}
+	rest := data[headerSize:]
+	nonce, sealed := rest[:k.aead.NonceSize()], rest[k.aead.NonceSize():]
+	plaintext, err := k.aead.Open(nil, nonce, sealed, k.header)
+	if err != nil {
+		return nil, ErrWrongPassphrase
+	}
+	return plaintext, nil
+}
+
+// EncryptNote writes content encrypted to filename + EncryptedExt and
+// removes the plain note, returning the new filename
+func EncryptNote(v Vault, filename string, content []byte, key *Key) (string, error) {
+	target := filename + EncryptedExt
+	if _, err := v.Stat(target); err == nil {
+		return "", ErrExists
+	}
+
+	data, err := key.Encrypt(content)
+	if err != nil {
+		return "", err
+	}
+	if err := v.Write(target, data); err != nil {
+		return "", err
+	}
+	if err := v.Delete(filename); err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return target, fmt.Errorf("error removing the plain note: %w", err)
+	}
+	return target, nil
+}
+
+// scrypt derives a key as described in RFC 7914, with PBKDF2-HMAC-SHA256
+// around the memory-hard ROMix of Salsa20/8 blocks
+func scrypt(password, salt []byte, n, r, p, keyLen int) ([]byte, error) {
+	if n < 2 || n&(n-1) != 0 {
+		return nil, errors.New("scrypt: N must be a power of 2 greater than 1")
+	}
+	b, err := pbkdf2.Key(sha256.New, string(password), salt, 1, p*128*r)
+	if err != nil {
+		return nil, err
+	}
+
+	xy := make([]uint32, 64*r)
+	v := make([]uint32, 32*n*r)
+	for i := 0; i < p; i++ {
+		roMix(b[i*128*r:(i+1)*128*r], r, n, v, xy)
+	}
+	return pbkdf2.Key(sha256.New, string(password), b, 1, keyLen)
+}
+
+// roMix mixes one 128*r byte block of b in place
+func roMix(b []byte, r, n int, v, xy []uint32) {
+	x, y := xy[:32*r], xy[32*r:]
+	for i := range x {
+		x[i] = binary.LittleEndian.Uint32(b[i*4:])
+	}
+
+	for i := 0; i < n; i++ {
+		copy(v[i*32*r:], x)
+		blockMix(x, y, r)
+		x, y = y, x
+	}
+	for i := 0; i < n; i++ {
+		j := int(x[(2*r-1)*16] & uint32(n-1))
+		for k := range x {
+			x[k] ^= v[j*32*r+k]
+		}
+		blockMix(x, y, r)
+		x, y = y, x
+	}
+
+	for i, w := range x {
+		binary.LittleEndian.PutUint32(b[i*4:], w)
+	}
+}
+
+// blockMix runs Salsa20/8 over the 2*r 64-byte blocks of in, writing the
+// even-numbered outputs to the first half of out and the odd ones to the second
+func blockMix(in, out []uint32, r int) {
+	var x [16]uint32
+	copy(x[:], in[(2*r-1)*16:])
+	for i := 0; i < 2*r; i++ {
+		for j := range x {
+			x[j] ^= in[i*16+j]
+		}
+		salsa208(&x)
+		copy(out[(i/2+(i%2)*r)*16:], x[:])
+	}
+}
+
+// salsa208 applies the Salsa20/8 core to a 64-byte block
+func salsa208(b *[16]uint32) {
+	x := *b
+	rotl := bits.RotateLeft32
+	for i := 0; i < 8; i += 2 {
+		// Columns
+		x[4] ^= rotl(x[0]+x[12], 7)
+		x[8] ^= rotl(x[4]+x[0], 9)
+		x[12] ^= rotl(x[8]+x[4], 13)
+		x[0] ^= rotl(x[12]+x[8], 18)
+		x[9] ^= rotl(x[5]+x[1], 7)
+		x[13] ^= rotl(x[9]+x[5], 9)
+		x[1] ^= rotl(x[13]+x[9], 13)
+		x[5] ^= rotl(x[1]+x[13], 18)
+		x[14] ^= rotl(x[10]+x[6], 7)
+		x[2] ^= rotl(x[14]+x[10], 9)
+		x[6] ^= rotl(x[2]+x[14], 13)
+		x[10] ^= rotl(x[6]+x[2], 18)
+		x[3] ^= rotl(x[15]+x[11], 7)
+		x[7] ^= rotl(x[3]+x[15], 9)
+		x[11] ^= rotl(x[7]+x[3], 13)
+		x[15] ^= rotl(x[11]+x[7], 18)
+
+		// Rows
+		x[1] ^= rotl(x[0]+x[3], 7)
+		x[2] ^= rotl(x[1]+x[0], 9)
+		x[3] ^= rotl(x[2]+x[1], 13)
+		x[0] ^= rotl(x[3]+x[2], 18)
+		x[6] ^= rotl(x[5]+x[4], 7)
+		x[7] ^= rotl(x[6]+x[5], 9)
+		x[4] ^= rotl(x[7]+x[6], 13)
+		x[5] ^= rotl(x[4]+x[7], 18)
+		x[11] ^= rotl(x[10]+x[9], 7)
+		x[8] ^= rotl(x[11]+x[10], 9)
+		x[9] ^= rotl(x[8]+x[11], 13)
+		x[10] ^= rotl(x[9]+x[8], 18)
+		x[12] ^= rotl(x[15]+x[14], 7)
+		x[13] ^= rotl(x[12]+x[15], 9)
+		x[14] ^= rotl(x[13]+x[12], 13)
+		x[15] ^= rotl(x[14]+x[13], 18)
+	}
+	for i := range b {
+		b[i] += x[i]
+	}
+}
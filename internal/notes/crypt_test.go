@@ -0,0 +1,130 @@
+package notes
+
+import (
+	"bytes"
+	"encoding/hex"
+	"errors"
+	"testing"
+)
+
+func TestScryptVectors(t *testing.T) {
+	// RFC 7914 section 12. The last vector needs 1 GiB and is left out.
+	tests := []struct {
+		password, salt string
+		n, r, p        int
+		want           string
+	}{
+		{"", "", 16, 1, 1,
+			"77d6576238657b203b19ca42c18a0497f16b4844e3074ae8dfdffa3fede21442" +
+				"fcd0069ded0948f8326a753a0fc81f17e8d3e0fb2e0d3628cf35e20c38d18906"},
+		{"password", "NaCl", 1024, 8, 16,
+			"fdbabe1c9d3472007856e7190d01e9fe7c6ad7cbc8237830e77376634b373162" +
+				"2eaf30d92e22a3886ff109279d9830dac727afb94a83ee6d8360cbdfa2cc0640"},
+		{"pleaseletmein", "SodiumChloride", 16384, 8, 1,
+			"7023bdcb3afd7348461c06cd81fd38ebfda8fbba904f8e3ea9b543f6545da1f2" +
+				"d5432955613f0fcf62d49705242a9af9e61e85dc0d651e40dfcf017b45575887"},
+	}
+
+	for _, tt := range tests {
+		got, err := scrypt([]byte(tt.password), []byte(tt.salt), tt.n, tt.r, tt.p, 64)
+		if err != nil {
+			t.Fatalf("scrypt(%q, %q): %v", tt.password, tt.salt, err)
+		}
+		if hex.EncodeToString(got) != tt.want {
+			t.Errorf("scrypt(%q, %q, N=%d) = %x, want %s", tt.password, tt.salt, tt.n, got, tt.want)
+		}
+	}
+}
+
+func TestEncryptDecrypt(t *testing.T) {
+	key, err := NewKey("correct horse")
+	if err != nil {
+		t.Fatal(err)
+	}
+	data, err := key.Encrypt([]byte("the secret note"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if bytes.Contains(data, []byte("secret")) {
+		t.Fatal("encrypted note holds the plain text")
+	}
+
+	// A key derived again from the passphrase opens it
+	opened, err := DeriveKey("correct horse", data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	plain, err := opened.Decrypt(data)
+	if err != nil || string(plain) != "the secret note" {
+		t.Fatalf("Decrypt = %q, %v", plain, err)
+	}
+
+	// Saving again uses a new nonce under the same header
+	again, err := opened.Encrypt(plain)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if bytes.Equal(again, data) || !key.Matches(again) {
+		t.Error("encrypting again did not use a new nonce with the same key")
+	}
+}
+
+func TestDecryptFailures(t *testing.T) {
+	key, err := NewKey("correct horse")
+	if err != nil {
+		t.Fatal(err)
+	}
+	data, err := key.Encrypt([]byte("the secret note"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	magic := len(encryptedMagic)
+
+	// modified returns a copy of data with one byte changed
+	modified := func(i int, b byte) []byte {
+		out := append([]byte(nil), data...)
+		out[i] = b
+		return out
+	}
+
+	tests := []struct {
+		name       string
+		passphrase string
+		data       []byte
+		want       error
+	}{
+		{"wrong passphrase", "battery staple", data, ErrWrongPassphrase},
+		{"tampered salt", "correct horse", modified(magic+3, data[magic+3]^1), ErrWrongPassphrase},
+		{"tampered content", "correct horse", modified(len(data)-1, data[len(data)-1]^1), ErrWrongPassphrase},
+		{"truncated content", "correct horse", data[:len(data)-4], ErrWrongPassphrase},
+		{"truncated nonce", "correct horse", data[:headerSize+4], ErrWrongPassphrase},
+		{"truncated header", "correct horse", data[:headerSize-1], ErrNotEncrypted},
+		{"plain text", "correct horse", []byte("# just a note\n"), ErrNotEncrypted},
+		{"too much memory", "correct horse", modified(magic, 22), ErrNotEncrypted},
+		{"too many passes", "correct horse", modified(magic+2, scryptMaxP+1), ErrNotEncrypted},
+		{"zero parameter", "correct horse", modified(magic+1, 0), ErrNotEncrypted},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := DeriveKey(tt.passphrase, tt.data); !errors.Is(err, tt.want) {
+				t.Errorf("DeriveKey = %v, want %v", err, tt.want)
+			}
+			if _, err := key.Decrypt(tt.data); tt.passphrase == "correct horse" && !errors.Is(err, tt.want) {
+				t.Errorf("Decrypt = %v, want %v", err, tt.want)
+			}
+		})
+	}
+}
+
+func TestIsEncrypted(t *testing.T) {
+	for name, want := range map[string]bool{
+		"secret.md.enc": true,
+		"secret.md":     false,
+		"enc":           false,
+	} {
+		if got := IsEncrypted(name); got != want {
+			t.Errorf("IsEncrypted(%q) = %v, want %v", name, got, want)
+		}
+	}
+}
@@ -0,0 +1,74 @@
+package notes
+
+// demoNotes are the sample notes of the demo vault
+var demoNotes = map[string]string{
+	"welcome.md": `# Welcome to TermNote
+
+This is a demo vault. Its notes live in memory, so edit, rename and delete
+them as you like: nothing is written to disk and everything is gone when
+you quit.
+
+## Getting around
+
+- Ctrl+L lists the notes, / filters them
+- Ctrl+N creates a note, Ctrl+S saves it
+- Ctrl+H shows every key of the editor
+- Ctrl+Space starts a leader chord, e.g. Ctrl+Space n d for today's note
+
+Have a look at [[projects/garden]], the [[reading-list]] or the
+[shopping list](shopping.md).
+`,
+	"reading-list.md": `# Reading list
+
+- [x] The Pragmatic Programmer
+- [ ] A Philosophy of Software Design
+- [ ] Designing Data-Intensive Applications
+- [ ] The Mythical Man-Month
+
+Notes on the books go in [[books/notes]].
+`,
+	"shopping.md": `# Shopping
+
+- [ ] Coffee beans
+- [ ] Oat milk
+- [x] Seeds for the [garden](projects/garden.md)
+`,
+	"projects/garden.md": `# Garden
+
+## Plan
+
+| Bed   | Spring   | Summer    |
+|-------|----------|-----------|
+| North | Lettuce  | Beans     |
+| South | Radishes | Tomatoes  |
+
+## Watering
+
+` + "```" + `sh
+# Remind me every evening at 7
+0 19 * * * notify-send "Water the garden"
+` + "```" + `
+
+Back to the [[welcome]] note.
+`,
+	"projects/termnote-ideas.md": `# Ideas
+
+- Try renaming this note: the links to it are updated
+- Move a note into another folder with the move dialog
+- Delete a note, then undo it or restore it from the trash
+`,
+	"books/notes.md": `# Book notes
+
+## A Philosophy of Software Design
+
+> The greatest limitation in writing software is our ability to
+> understand the systems we are creating.
+
+Deep modules: simple interfaces hiding a lot of functionality.
+`,
+}
+
+// NewDemoVault creates an in-memory vault holding a few sample notes
+func NewDemoVault() *MemVault {
+	return NewMemVault(demoNotes)
+}
@@ -0,0 +1,330 @@
+package notes
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// DiffOp says how a line differs between two versions of a note
+type DiffOp int
+
+const (
+	DiffEqual  DiffOp = iota // Line is in both versions
+	DiffDelete               // Line is only in the old version
+	DiffInsert               // Line is only in the new version
+)
+
+// DiffLine is one line of a line-based diff
+type DiffLine struct {
+	Op   DiffOp
+	Text string
+}
+
+// Diff returns the line-based difference between two texts, computed from
+// their longest common subsequence of lines
+func Diff(a, b string) []DiffLine {
+	return diffLines(strings.Split(a, "\n"), strings.Split(b, "\n"))
+}
+
+// diffLines diffs two slices of lines with Myers' algorithm in linear
+// space. Within each run of changed lines the removed lines come first.
+func diffLines(a, b []string) []DiffLine {
+	result := make([]DiffLine, 0, len(a)+len(b))
+	result = diffRange(result, a, b)
+
+	// Put the deletions of each run of changes before its insertions
+	for start := 0; start < len(result); {
+		if result[start].Op == DiffEqual {
+			start++
+			continue
+		}
+		end := start
+		for end < len(result) && result[end].Op != DiffEqual {
+			end++
+		}
+		sort.SliceStable(result[start:end], func(i, j int) bool {
+			return result[start+i].Op == DiffDelete && result[start+j].Op == DiffInsert
+		})
+		start = end
+	}
+	return result
+}
+
+// diffRange appends the diff of a and b to result. Common leading and
+// trailing lines are matched directly; the changed middle is split at its
+// middle snake and each half diffed in turn.
+func diffRange(result []DiffLine, a, b []string) []DiffLine {
+	prefix := 0
+	for prefix < len(a) && prefix < len(b) && a[prefix] == b[prefix] {
+		prefix++
+	}
+	suffix := 0
+	for suffix < len(a)-prefix && suffix < len(b)-prefix && a[len(a)-1-suffix] == b[len(b)-1-suffix] {
+		suffix++
+	}
+
+	for _, line := range a[:prefix] {
+		result = append(result, DiffLine{DiffEqual, line})
+	}
+
+	midA := a[prefix : len(a)-suffix]
+	midB := b[prefix : len(b)-suffix]
+	switch {
+	case len(midA) == 0:
+		for _, line := range midB {
+			result = append(result, DiffLine{DiffInsert, line})
+		}
+	case len(midB) == 0:
+		for _, line := range midA {
+			result = append(result, DiffLine{DiffDelete, line})
+		}
+	default:
+		x, y, u, v, ok := middleSnake(midA, midB)
+		if !ok {
+			// Not expected, but show the whole region as replaced
+			for _, line := range midA {
+				result = append(result, DiffLine{DiffDelete, line})
+			}
+			for _, line := range midB {
+				result = append(result, DiffLine{DiffInsert, line})
+			}
+			break
+		}
+		result = diffRange(result, midA[:x], midB[:y])
+		for _, line := range midA[x:u] {
+			result = append(result, DiffLine{DiffEqual, line})
+		}
+		result = diffRange(result, midA[u:], midB[v:])
+	}
+
+	for _, line := range a[len(a)-suffix:] {
+		result = append(result, DiffLine{DiffEqual, line})
+	}
+	return result
+}
+
+// middleSnake finds the middle snake of an optimal edit path from a to b by
+// searching from both ends at once, as in Myers' "An O(ND) Difference
+// Algorithm and Its Variations". The snake runs from (x, y) to (u, v), and
+// the lines a[x:u] and b[y:v] are equal. Since a and b share no first or
+// last line, the snake never spans the whole of both.
+func middleSnake(a, b []string) (x, y, u, v int, ok bool) {
+	n, m := len(a), len(b)
+	delta := n - m
+	odd := delta%2 != 0
+	limit := (n + m + 1) / 2
+	offset := limit + 1
+
+	// forward[k] is the furthest x reached on diagonal k = x - y from the
+	// start; backward[k] is the same from the end, on reversed lines
+	forward := make([]int, 2*offset+1)
+	backward := make([]int, 2*offset+1)
+
+	for d := 0; d <= limit; d++ {
+		for k := -d; k <= d; k += 2 {
+			var x0 int
+			if k == -d || (k != d && forward[offset+k-1] < forward[offset+k+1]) {
+				x0 = forward[offset+k+1]
+			} else {
+				x0 = forward[offset+k-1] + 1
+			}
+			y0 := x0 - k
+			x, y := x0, y0
+			for x < n && y < m && a[x] == b[y] {
+				x++
+				y++
+			}
+			forward[offset+k] = x
+			if odd && delta-k >= -(d-1) && delta-k <= d-1 && x+backward[offset+delta-k] >= n {
+				return x0, y0, x, y, true
+			}
+		}
+
+		for k := -d; k <= d; k += 2 {
+			var x0 int
+			if k == -d || (k != d && backward[offset+k-1] < backward[offset+k+1]) {
+				x0 = backward[offset+k+1]
+			} else {
+				x0 = backward[offset+k-1] + 1
+			}
+			y0 := x0 - k
+			x, y := x0, y0
+			for x < n && y < m && a[n-1-x] == b[m-1-y] {
+				x++
+				y++
+			}
+			backward[offset+k] = x
+			if !odd && delta-k >= -d && delta-k <= d && x+forward[offset+delta-k] >= n {
+				return n - x, m - y, n - x0, m - y0, true
+			}
+		}
+	}
+	return 0, 0, 0, 0, false
+}
+
+// DiffStats counts the lines added and removed by a diff
+func DiffStats(diff []DiffLine) (added, removed int) {
+	for _, line := range diff {
+		switch line.Op {
+		case DiffInsert:
+			added++
+		case DiffDelete:
+			removed++
+		}
+	}
+	return added, removed
+}
+
+// Conflict markers written by Merge3 where both sides changed the same lines
+const (
+	MarkerOurs   = "<<<<<<< editor"
+	MarkerBase   = "||||||| original"
+	MarkerSplit  = "======="
+	MarkerTheirs = ">>>>>>> disk"
+)
+
+// Merge3 merges the changes made in ours and theirs since base. Lines
+// changed differently on both sides are kept between conflict markers.
+// It returns the merged text and the number of conflicts.
+func Merge3(base, ours, theirs string) (string, int) {
+	b := strings.Split(base, "\n")
+	o := strings.Split(ours, "\n")
+	t := strings.Split(theirs, "\n")
+
+	toOurs := matchLines(b, o)
+	toTheirs := matchLines(b, t)
+
+	var merged []string
+	conflicts := 0
+	ib, io, it := 0, 0, 0
+	for {
+		// Find the next base line that both sides kept
+		i := ib
+		for i < len(b) && (toOurs[i] < 0 || toTheirs[i] < 0) {
+			i++
+		}
+		eo, et := len(o), len(t)
+		if i < len(b) {
+			eo, et = toOurs[i], toTheirs[i]
+		}
+
+		// Resolve the chunk of changes before it
+		baseChunk, oursChunk, theirsChunk := b[ib:i], o[io:eo], t[it:et]
+		switch {
+		case equalLines(oursChunk, theirsChunk), equalLines(theirsChunk, baseChunk):
+			merged = append(merged, oursChunk...)
+		case equalLines(oursChunk, baseChunk):
+			merged = append(merged, theirsChunk...)
+		default:
+			conflicts++
+			merged = append(merged, MarkerOurs)
+			merged = append(merged, oursChunk...)
+			merged = append(merged, MarkerBase)
+			merged = append(merged, baseChunk...)
+			merged = append(merged, MarkerSplit)
+			merged = append(merged, theirsChunk...)
+			merged = append(merged, MarkerTheirs)
+		}
+
+		if i == len(b) {
+			break
+		}
+		merged = append(merged, b[i])
+		ib, io, it = i+1, eo+1, et+1
+	}
+
+	return strings.Join(merged, "\n"), conflicts
+}
+
+// matchLines maps each line of a to the index of the same line in b
+// according to their diff, or -1 if the line was removed
+func matchLines(a, b []string) []int {
+	matches := make([]int, len(a))
+	i, j := 0, 0
+	for _, line := range diffLines(a, b) {
+		switch line.Op {
+		case DiffEqual:
+			matches[i] = j
+			i++
+			j++
+		case DiffDelete:
+			matches[i] = -1
+			i++
+		case DiffInsert:
+			j++
+		}
+	}
+	return matches
+}
+
+// equalLines reports whether two slices hold the same lines
+func equalLines(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
+
+// UnifiedDiff renders a diff in unified format with the given number of
+// context lines around each change
+func UnifiedDiff(diff []DiffLine, oldName, newName string, context int) string {
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "--- %s\n+++ %s\n", oldName, newName)
+
+	// Line numbers in the old and new text at the start of each diff line
+	oldLine := make([]int, len(diff)+1)
+	newLine := make([]int, len(diff)+1)
+	for i, line := range diff {
+		oldLine[i+1], newLine[i+1] = oldLine[i], newLine[i]
+		if line.Op != DiffInsert {
+			oldLine[i+1]++
+		}
+		if line.Op != DiffDelete {
+			newLine[i+1]++
+		}
+	}
+
+	for start := 0; start < len(diff); {
+		// Find the next change and the end of its hunk
+		first := start
+		for first < len(diff) && diff[first].Op == DiffEqual {
+			first++
+		}
+		if first == len(diff) {
+			break
+		}
+		last := first
+		for k := first; k < len(diff); k++ {
+			if diff[k].Op != DiffEqual {
+				last = k
+			} else if k-last > 2*context {
+				break
+			}
+		}
+
+		from := max(first-context, start)
+		to := min(last+context+1, len(diff))
+		fmt.Fprintf(&sb, "@@ -%d,%d +%d,%d @@\n",
+			oldLine[from]+1, oldLine[to]-oldLine[from],
+			newLine[from]+1, newLine[to]-newLine[from])
+		for _, line := range diff[from:to] {
+			prefix := " "
+			switch line.Op {
+			case DiffDelete:
+				prefix = "-"
+			case DiffInsert:
+				prefix = "+"
+			}
+			sb.WriteString(prefix + line.Text + "\n")
+		}
+		start = to
+	}
+
+	return sb.String()
+}
@@ -0,0 +1,213 @@
+package notes
+
+import (
+	"fmt"
+	"math/rand/v2"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestMerge3(t *testing.T) {
+	tests := []struct {
+		name      string
+		base      string
+		ours      string
+		theirs    string
+		want      string
+		conflicts int
+	}{
+		{
+			name:   "clean merge of separate edits",
+			base:   "one\ntwo\nthree\nfour",
+			ours:   "ONE\ntwo\nthree\nfour",
+			theirs: "one\ntwo\nthree\nFOUR",
+			want:   "ONE\ntwo\nthree\nFOUR",
+		},
+		{
+			name:   "insertions on both sides",
+			base:   "a\nb\nc",
+			ours:   "a\nours\nb\nc",
+			theirs: "a\nb\ntheirs\nc",
+			want:   "a\nours\nb\ntheirs\nc",
+		},
+		{
+			name:   "overlapping edits",
+			base:   "a\nb\nc",
+			ours:   "a\nmine\nc",
+			theirs: "a\nyours\nc",
+			want: strings.Join([]string{
+				"a", MarkerOurs, "mine", MarkerBase, "b", MarkerSplit, "yours", MarkerTheirs, "c",
+			}, "\n"),
+			conflicts: 1,
+		},
+		{
+			name:   "empty base, one side written",
+			base:   "",
+			ours:   "first\nsecond",
+			theirs: "",
+			want:   "first\nsecond",
+		},
+		{
+			name:   "empty base, both sides written",
+			base:   "",
+			ours:   "mine",
+			theirs: "yours",
+			want: strings.Join([]string{
+				MarkerOurs, "mine", MarkerBase, "", MarkerSplit, "yours", MarkerTheirs,
+			}, "\n"),
+			conflicts: 1,
+		},
+		{
+			name:   "changes only at the end",
+			base:   "a\nb",
+			ours:   "a\nb\nours",
+			theirs: "a\nb",
+			want:   "a\nb\nours",
+		},
+		{
+			name:   "different appends at the end",
+			base:   "a\nb",
+			ours:   "a\nb\nours",
+			theirs: "a\nb\ntheirs",
+			want: strings.Join([]string{
+				"a", "b", MarkerOurs, "ours", MarkerBase, MarkerSplit, "theirs", MarkerTheirs,
+			}, "\n"),
+			conflicts: 1,
+		},
+		{
+			name:   "identical edits on both sides",
+			base:   "a\nb\nc",
+			ours:   "a\nB\nc\nd",
+			theirs: "a\nB\nc\nd",
+			want:   "a\nB\nc\nd",
+		},
+		{
+			name:   "deleted on one side",
+			base:   "a\nb\nc",
+			ours:   "a\nb\nc",
+			theirs: "a\nc",
+			want:   "a\nc",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, conflicts := Merge3(tt.base, tt.ours, tt.theirs)
+			if got != tt.want || conflicts != tt.conflicts {
+				t.Errorf("Merge3 = %q with %d conflicts, want %q with %d", got, conflicts, tt.want, tt.conflicts)
+			}
+		})
+	}
+}
+
+func TestDiff(t *testing.T) {
+	diff := Diff("a\nb\nc", "a\nc\nd")
+	want := []DiffLine{
+		{DiffEqual, "a"},
+		{DiffDelete, "b"},
+		{DiffEqual, "c"},
+		{DiffInsert, "d"},
+	}
+	if !reflect.DeepEqual(diff, want) {
+		t.Fatalf("Diff = %v, want %v", diff, want)
+	}
+	if added, removed := DiffStats(diff); added != 1 || removed != 1 {
+		t.Errorf("DiffStats = +%d -%d, want +1 -1", added, removed)
+	}
+}
+
+// lcsLength returns the length of the longest common subsequence of a and b
+func lcsLength(a, b []string) int {
+	prev := make([]int, len(b)+1)
+	for i := range a {
+		cur := make([]int, len(b)+1)
+		for j := range b {
+			if a[i] == b[j] {
+				cur[j+1] = prev[j] + 1
+			} else {
+				cur[j+1] = max(prev[j+1], cur[j])
+			}
+		}
+		prev = cur
+	}
+	return prev[len(b)]
+}
+
+// checkDiff fails unless diff turns a into b while keeping as many lines as possible
+func checkDiff(t *testing.T, a, b []string, diff []DiffLine) {
+	t.Helper()
+	var gotA, gotB []string
+	equal := 0
+	for i, line := range diff {
+		if line.Op != DiffInsert {
+			gotA = append(gotA, line.Text)
+		}
+		if line.Op != DiffDelete {
+			gotB = append(gotB, line.Text)
+		}
+		if line.Op == DiffEqual {
+			equal++
+		}
+		if i > 0 && line.Op == DiffDelete && diff[i-1].Op == DiffInsert {
+			t.Fatalf("diff %v of %q and %q has a deletion after an insertion", diff, a, b)
+		}
+	}
+	if !equalLines(gotA, a) || !equalLines(gotB, b) {
+		t.Fatalf("diff %v does not turn %q into %q", diff, a, b)
+	}
+	if want := lcsLength(a, b); equal != want {
+		t.Fatalf("diff of %q and %q keeps %d lines, want %d", a, b, equal, want)
+	}
+}
+
+func TestDiffMinimal(t *testing.T) {
+	r := rand.New(rand.NewPCG(1, 2))
+	lines := func() []string {
+		out := make([]string, r.IntN(12))
+		for i := range out {
+			out[i] = string(rune('a' + r.IntN(3)))
+		}
+		return out
+	}
+	for range 2000 {
+		a, b := lines(), lines()
+		checkDiff(t, a, b, diffLines(a, b))
+	}
+}
+
+func TestDiffLarge(t *testing.T) {
+	// A long note edited all over would need an n×m table of 400M cells
+	const n = 20000
+	a := make([]string, n)
+	b := make([]string, n)
+	for i := range a {
+		a[i] = fmt.Sprintf("line %d", i)
+		b[i] = a[i]
+		if i%50 == 0 {
+			b[i] = fmt.Sprintf("changed %d", i)
+		}
+	}
+	b = append(b, "the end")
+
+	diff := diffLines(a, b)
+	if added, removed := DiffStats(diff); added != n/50+1 || removed != n/50 {
+		t.Errorf("DiffStats = +%d -%d, want +%d -%d", added, removed, n/50+1, n/50)
+	}
+	if len(diff) != n+n/50+1 {
+		t.Errorf("diff has %d lines, want %d", len(diff), n+n/50+1)
+	}
+
+	// Two unrelated texts are replaced as a whole
+	c := make([]string, 3000)
+	for i := range c {
+		c[i] = fmt.Sprintf("other %d", i)
+	}
+	diff = diffLines(a[:3000], c)
+	if added, removed := DiffStats(diff); added != 3000 || removed != 3000 {
+		t.Errorf("DiffStats of unrelated texts = +%d -%d, want +3000 -3000", added, removed)
+	}
+	if diff[0].Op != DiffDelete || diff[len(diff)-1].Op != DiffInsert {
+		t.Error("unrelated texts are not shown as removed then added")
+	}
+}
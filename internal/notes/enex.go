@@ -0,0 +1,436 @@
+package notes
+
+import (
+	"crypto/md5"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"os"
+	"path"
+	"strings"
+	"unicode"
+)
+
+// enexNote is a note of an Evernote export
+type enexNote struct {
+	Title     string         `xml:"title"`
+	Content   string         `xml:"content"`
+	Tags      []string       `xml:"tag"`
+	Resources []enexResource `xml:"resource"`
+}
+
+// enexResource is an attachment of an Evernote note
+type enexResource struct {
+	Data     string `xml:"data"`
+	Mime     string `xml:"mime"`
+	FileName string `xml:"resource-attributes>file-name"`
+}
+
+// readENEX converts the notes of an Evernote export to markdown. Their
+// attachments go in the attachments folder and are linked from where
+// they appeared in the note.
+func readENEX(plan *ImportPlan, source, ext string) error {
+	f, err := os.Open(source)
+	if err != nil {
+		return fmt.Errorf("error reading import source: %w", err)
+	}
+	defer f.Close()
+
+	dec := xml.NewDecoder(f)
+	attachments := make(map[string]string) // Attachment names by MD5 hash
+	for {
+		tok, err := dec.Token()
+		if errors.Is(err, io.EOF) {
+			return nil
+		}
+		if err != nil {
+			return fmt.Errorf("error reading ENEX file: %w", err)
+		}
+		start, ok := tok.(xml.StartElement)
+		if !ok || start.Name.Local != "note" {
+			continue
+		}
+
+		var note enexNote
+		if err := dec.DecodeElement(&note, &start); err != nil {
+			return fmt.Errorf("error reading ENEX file: %w", err)
+		}
+		title := noteTitle(note.Title)
+
+		media := make(map[string]enmlMedia)
+		for i, res := range note.Resources {
+			data, err := base64.StdEncoding.DecodeString(strings.Join(strings.Fields(res.Data), ""))
+			if err != nil {
+				plan.skip(fmt.Sprintf("%s (attachment %d)", title, i+1), "invalid attachment data")
+				continue
+			}
+			sum := md5.Sum(data)
+			hash := hex.EncodeToString(sum[:])
+			name, ok := attachments[hash]
+			if !ok {
+				name = plan.add(path.Join(attachmentsFolder, attachmentName(res.FileName, res.Mime, i+1)), title, data)
+				attachments[hash] = name
+			}
+			media[hash] = enmlMedia{name: name, image: strings.HasPrefix(res.Mime, "image/")}
+		}
+
+		body, err := convertENML(note.Content, media)
+		if err != nil {
+			plan.skip(title, err.Error())
+			continue
+		}
+		plan.add(title+ext, title, convertedNote(note.Title, note.Tags, body))
+	}
+}
+
+// attachmentName returns the name to save an attachment under, made from
+// its original filename or, failing that, its number and MIME type
+func attachmentName(filename, mimeType string, n int) string {
+	if name := noteTitle(path.Base(filename)); filename != "" && name != "Untitled" {
+		return name
+	}
+	name := fmt.Sprintf("attachment-%d", n)
+	if exts, _ := mime.ExtensionsByType(mimeType); len(exts) > 0 {
+		name += exts[0]
+	}
+	return name
+}
+
+// enmlMedia is an attachment an en-media element of a note refers to
+type enmlMedia struct {
+	name  string // Path below the import folder
+	image bool
+}
+
+// htmlNode is an element or, with an empty tag, a run of text of the
+// note markup being converted
+type htmlNode struct {
+	tag      string
+	attrs    map[string]string
+	text     string
+	children []*htmlNode
+}
+
+// parseHTML parses the XHTML of an Evernote note or an HTML Joplin note
+// into a tree, forgiving the mistakes HTML allows
+func parseHTML(markup string) (*htmlNode, error) {
+	dec := xml.NewDecoder(strings.NewReader(markup))
+	dec.Strict = false
+	dec.AutoClose = xml.HTMLAutoClose
+	dec.Entity = xml.HTMLEntity
+
+	root := &htmlNode{tag: "root"}
+	stack := []*htmlNode{root}
+	for {
+		tok, err := dec.Token()
+		if errors.Is(err, io.EOF) {
+			return root, nil
+		}
+		if err != nil {
+			return nil, fmt.Errorf("error reading note content: %w", err)
+		}
+
+		parent := stack[len(stack)-1]
+		switch t := tok.(type) {
+		case xml.StartElement:
+			node := &htmlNode{tag: strings.ToLower(t.Name.Local), attrs: make(map[string]string)}
+			for _, attr := range t.Attr {
+				node.attrs[strings.ToLower(attr.Name.Local)] = attr.Value
+			}
+			parent.children = append(parent.children, node)
+			stack = append(stack, node)
+		case xml.EndElement:
+			if len(stack) > 1 {
+				stack = stack[:len(stack)-1]
+			}
+		case xml.CharData:
+			parent.children = append(parent.children, &htmlNode{text: string(t)})
+		}
+	}
+}
+
+// convertENML converts the content of an Evernote note to markdown
+func convertENML(content string, media map[string]enmlMedia) (string, error) {
+	root, err := parseHTML(content)
+	if err != nil {
+		return "", err
+	}
+	c := htmlConverter{media: media}
+	return strings.Join(c.blocks(root.children), "\n\n"), nil
+}
+
+// htmlConverter turns a tree of note markup into markdown
+type htmlConverter struct {
+	media map[string]enmlMedia // Attachments by hash, for en-media elements
+}
+
+// blocks converts nodes to markdown blocks, such as paragraphs, headings
+// and lists
+func (c htmlConverter) blocks(nodes []*htmlNode) []string {
+	var blocks []string
+	var inline strings.Builder
+	flush := func() {
+		var lines []string
+		for _, line := range strings.Split(inline.String(), "\n") {
+			lines = append(lines, strings.TrimSpace(line))
+		}
+		if text := strings.Trim(strings.Join(lines, "\n"), "\n"); text != "" {
+			blocks = append(blocks, text)
+		}
+		inline.Reset()
+	}
+	add := func(block string) {
+		flush()
+		if strings.TrimSpace(block) != "" {
+			blocks = append(blocks, block)
+		}
+	}
+
+	for _, n := range nodes {
+		switch n.tag {
+		case "":
+			inline.WriteString(collapseSpace(n.text))
+		case "en-note", "body", "html", "div", "p", "section", "article", "center":
+			if strings.Contains(n.attrs["style"], "-en-codeblock") {
+				add("```\n" + strings.Trim(rawText(n), "\n") + "\n```")
+				continue
+			}
+			flush()
+			blocks = append(blocks, c.blocks(n.children)...)
+		case "head", "script", "style", "title":
+		case "h1", "h2", "h3", "h4", "h5", "h6":
+			add(strings.Repeat("#", int(n.tag[1]-'0')) + " " + strings.TrimSpace(c.inline(n.children)))
+		case "ul", "ol":
+			add(c.list(n))
+		case "pre":
+			add("```\n" + strings.Trim(rawText(n), "\n") + "\n```")
+		case "blockquote":
+			var lines []string
+			for _, line := range strings.Split(strings.Join(c.blocks(n.children), "\n\n"), "\n") {
+				lines = append(lines, strings.TrimRight("> "+line, " "))
+			}
+			add(strings.Join(lines, "\n"))
+		case "hr":
+			add("---")
+		case "table":
+			add(c.table(n))
+		case "en-todo":
+			// A checkbox at the start of a line makes it a task
+			if strings.TrimSpace(inline.String()) == "" {
+				inline.Reset()
+				inline.WriteString("- ")
+			}
+			inline.WriteString(checkbox(n))
+		default:
+			inline.WriteString(c.inline([]*htmlNode{n}))
+		}
+	}
+	flush()
+	return blocks
+}
+
+// inline converts nodes to markdown text within a line
+func (c htmlConverter) inline(nodes []*htmlNode) string {
+	var b strings.Builder
+	for _, n := range nodes {
+		inner := func() string { return c.inline(n.children) }
+		switch n.tag {
+		case "":
+			b.WriteString(collapseSpace(n.text))
+		case "br":
+			b.WriteString("\n")
+		case "b", "strong":
+			b.WriteString(emphasis(inner(), "**"))
+		case "i", "em":
+			b.WriteString(emphasis(inner(), "*"))
+		case "s", "strike", "del":
+			b.WriteString(emphasis(inner(), "~~"))
+		case "code", "tt":
+			b.WriteString(emphasis(inner(), "`"))
+		case "a":
+			text, href := inner(), n.attrs["href"]
+			if href == "" || strings.TrimSpace(text) == "" {
+				b.WriteString(text)
+			} else {
+				b.WriteString("[" + strings.TrimSpace(text) + "](" + href + ")")
+			}
+		case "img":
+			if src := n.attrs["src"]; src != "" {
+				b.WriteString("![" + n.attrs["alt"] + "](" + src + ")")
+			}
+		case "en-media":
+			b.WriteString(c.mediaLink(n))
+		case "en-todo":
+			b.WriteString(checkbox(n))
+		case "en-crypt":
+			b.WriteString("*(encrypted text not imported)*")
+		case "head", "script", "style", "title":
+		default:
+			b.WriteString(inner())
+		}
+	}
+	return b.String()
+}
+
+// list converts a ul or ol element to a markdown list. Nested lists are
+// indented under the item before them.
+func (c htmlConverter) list(n *htmlNode) string {
+	var items []string
+	number := 1
+	for _, child := range n.children {
+		switch child.tag {
+		case "li":
+			marker := "- "
+			if n.tag == "ol" {
+				marker = fmt.Sprintf("%d. ", number)
+				number++
+			}
+			text := strings.Join(c.blocks(child.children), "\n")
+			// Tasks already start with their own "- "
+			if marker == "- " && (strings.HasPrefix(text, "- [ ] ") || strings.HasPrefix(text, "- [x] ")) {
+				text = strings.TrimPrefix(text, "- ")
+			}
+			items = append(items, marker+indent(text, len(marker)))
+		case "ul", "ol":
+			if nested := c.list(child); nested != "" {
+				items = append(items, indent("  "+nested, 2))
+			}
+		}
+	}
+	return strings.Join(items, "\n")
+}
+
+// table converts a table element to a markdown table, with its first row
+// as the header
+func (c htmlConverter) table(n *htmlNode) string {
+	var rows [][]string
+	var visit func(nodes []*htmlNode)
+	visit = func(nodes []*htmlNode) {
+		for _, child := range nodes {
+			switch child.tag {
+			case "tr":
+				var row []string
+				for _, cell := range child.children {
+					if cell.tag == "td" || cell.tag == "th" {
+						text := strings.Join(strings.Fields(c.inline(cell.children)), " ")
+						row = append(row, strings.ReplaceAll(text, "|", `\|`))
+					}
+				}
+				rows = append(rows, row)
+			case "thead", "tbody", "tfoot":
+				visit(child.children)
+			}
+		}
+	}
+	visit(n.children)
+	if len(rows) == 0 {
+		return ""
+	}
+
+	width := 0
+	for _, row := range rows {
+		width = max(width, len(row))
+	}
+	lines := make([]string, 0, len(rows)+1)
+	for i, row := range rows {
+		for len(row) < width {
+			row = append(row, "")
+		}
+		lines = append(lines, "| "+strings.Join(row, " | ")+" |")
+		if i == 0 {
+			lines = append(lines, "|"+strings.Repeat(" --- |", width))
+		}
+	}
+	return strings.Join(lines, "\n")
+}
+
+// mediaLink links to the attachment an en-media element shows
+func (c htmlConverter) mediaLink(n *htmlNode) string {
+	media, ok := c.media[strings.ToLower(n.attrs["hash"])]
+	if !ok {
+		return ""
+	}
+	link := "[" + path.Base(media.name) + "](" + linkTarget(media.name) + ")"
+	if media.image {
+		return "!" + link
+	}
+	return link
+}
+
+// checkbox returns the markdown of an en-todo checkbox
+func checkbox(n *htmlNode) string {
+	if n.attrs["checked"] == "true" {
+		return "[x] "
+	}
+	return "[ ] "
+}
+
+// emphasis wraps text in a markdown marker, keeping the surrounding spaces
+// outside of it
+func emphasis(text, marker string) string {
+	trimmed := strings.TrimSpace(text)
+	if trimmed == "" {
+		return text
+	}
+	start := text[:strings.Index(text, trimmed)]
+	end := text[len(start)+len(trimmed):]
+	return start + marker + trimmed + marker + end
+}
+
+// rawText returns the text of a node as it is, for code blocks. Lines
+// split into divs or by br elements are kept.
+func rawText(n *htmlNode) string {
+	var b strings.Builder
+	var visit func(n *htmlNode)
+	visit = func(n *htmlNode) {
+		switch n.tag {
+		case "":
+			b.WriteString(n.text)
+			return
+		case "br":
+			b.WriteString("\n")
+			return
+		}
+		for _, child := range n.children {
+			visit(child)
+		}
+		if n.tag == "div" || n.tag == "p" {
+			if !strings.HasSuffix(b.String(), "\n") {
+				b.WriteString("\n")
+			}
+		}
+	}
+	for _, child := range n.children {
+		visit(child)
+	}
+	return b.String()
+}
+
+// collapseSpace collapses runs of white space in markup text to one space,
+// as a browser shows them
+func collapseSpace(text string) string {
+	fields := strings.Fields(text)
+	if len(fields) == 0 {
+		if text == "" {
+			return ""
+		}
+		return " "
+	}
+	collapsed := strings.Join(fields, " ")
+	if strings.TrimLeftFunc(text, unicode.IsSpace) != text {
+		collapsed = " " + collapsed
+	}
+	if strings.TrimRightFunc(text, unicode.IsSpace) != text {
+		collapsed += " "
+	}
+	return collapsed
+}
+
+// indent indents every line but the first by n spaces
+func indent(text string, n int) string {
+	return strings.ReplaceAll(text, "\n", "\n"+strings.Repeat(" ", n))
+}
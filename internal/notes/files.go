@@ -1,27 +1,51 @@
 package notes
 
 import (
+	"bufio"
+	"bytes"
+	"crypto/sha256"
+	"errors"
 	"fmt"
-	"log"
+	"io/fs"
 	"os"
+	"path/filepath"
+	"sort"
+	"strings"
 	"time"
 
 	"github.com/charmbracelet/bubbles/list"
 )
 
+var (
+	// ErrEmptyName is returned when a note name is blank
+	ErrEmptyName = errors.New("note name is empty")
+	// ErrInvalidName is returned when a note name contains characters
+	// that are not allowed in filenames
+	ErrInvalidName = errors.New("note name contains invalid characters")
+	// ErrExists is returned when creating a note that already exists
+	ErrExists = errors.New("a note with this name already exists")
+	// ErrNotFound is returned when a note does not exist in the vault
+	ErrNotFound = errors.New("note not found")
+)
+
+// invalidChars are characters rejected in note names. "/" separates folders.
+var invalidChars = []string{"\\", ":", "*", "?", "\"", "<", ">", "|"}
+
 // Item represents a file list item
 type Item struct {
 	title, desc string
-	filename    string // Full filename with extension
+	filename    string // Vault-relative path, with extension for notes
+	dir         bool
 }
 
 func (i Item) Title() string       { return i.title }
 func (i Item) Description() string { return i.desc }
 func (i Item) FilterValue() string { return i.title }
 func (i Item) Filename() string    { return i.filename }
+func (i Item) IsDir() bool         { return i.dir }
 
-// formatRelativeTime returns a human-readable relative time string
-func formatRelativeTime(t time.Time) string {
+// FormatRelativeTime returns a human-readable relative time string
+func FormatRelativeTime(t time.Time) string {
 	now := time.Now()
 	diff := now.Sub(t)
 
@@ -88,54 +112,394 @@ func formatRelativeTime(t time.Time) string {
 	return fmt.Sprintf("%d years ago", years)
 }
 
-// fileWithTime holds file info with modification time for sorting
-type fileWithTime struct {
-	name    string
-	modTime time.Time
+// Note holds the metadata of a note file in the vault
+type Note struct {
+	Name     string    `json:"name"`
+	Size     int64     `json:"size"`
+	Modified time.Time `json:"modified"`
 }
 
-// ListFiles returns a list of all note files in the vault directory
-func ListFiles(vaultDir string) []list.Item {
-	items := make([]list.Item, 0)
-	filesWithTime := make([]fileWithTime, 0)
+// ListNotes returns all notes in the vault and its folders, most recently
+// modified first. Names are vault-relative paths.
+func ListNotes(v Vault) ([]Note, error) {
+	found := make([]Note, 0)
+	err := walk(v, func(name string, info fs.FileInfo) {
+		if !info.IsDir() {
+			found = append(found, Note{
+				Name:     name,
+				Size:     info.Size(),
+				Modified: info.ModTime(),
+			})
+		}
+	})
+	if err != nil {
+		return nil, fmt.Errorf("error reading notes list: %w", err)
+	}
+
+	// Sort by modification time (most recent first)
+	sort.SliceStable(found, func(i, j int) bool {
+		return found[i].Modified.After(found[j].Modified)
+	})
+
+	return found, nil
+}
 
-	entries, err := os.ReadDir(vaultDir)
+// ListFolders returns the vault-relative paths of all folders in the vault,
+// sorted by name
+func ListFolders(v Vault) ([]string, error) {
+	var folders []string
+	err := walk(v, func(name string, info fs.FileInfo) {
+		if info.IsDir() {
+			folders = append(folders, name)
+		}
+	})
 	if err != nil {
-		log.Fatal("error reading notes list")
+		return nil, fmt.Errorf("error reading folders: %w", err)
 	}
+	sort.Strings(folders)
+	return folders, nil
+}
 
-	// Collect files with their modification times
-	for _, entry := range entries {
-		if !entry.IsDir() {
-			info, err := entry.Info()
-			if err != nil {
+// walk calls fn for every file and folder in the vault. Hidden files and
+// folders hold metadata, the trash and unfinished saves, not notes, so
+// they are skipped. Only an unreadable vault is an error; folders below it
+// that cannot be read are skipped.
+func walk(v Vault, fn func(name string, info fs.FileInfo)) error {
+	var visit func(folder string) error
+	visit = func(folder string) error {
+		entries, err := v.List(folder)
+		if err != nil {
+			return err
+		}
+		for _, entry := range entries {
+			if strings.HasPrefix(entry.Name(), ".") {
 				continue
 			}
+			name := filepath.Join(folder, entry.Name())
+			fn(name, entry)
+			if entry.IsDir() {
+				visit(name)
+			}
+		}
+		return nil
+	}
+	return visit("")
+}
 
-			filesWithTime = append(filesWithTime, fileWithTime{
-				name:    entry.Name(),
-				modTime: info.ModTime(),
-			})
+// ListFiles returns the list items for a folder of the vault ("" for the
+// vault itself): its subfolders by name, then its notes, most recently
+// modified first. With plain, descriptions are ASCII only.
+func ListFiles(v Vault, folder string, plain bool) ([]list.Item, error) {
+	entries, err := v.List(folder)
+	if err != nil {
+		return nil, fmt.Errorf("error reading notes list: %w", err)
+	}
+	found, err := ListNotes(v)
+	if err != nil {
+		return nil, err
+	}
+
+	// Count the notes below each subfolder
+	counts := make(map[string]int)
+	for _, note := range found {
+		rel, err := filepath.Rel(folder, note.Name)
+		if folder == "" {
+			rel, err = note.Name, nil
+		}
+		if err != nil || strings.HasPrefix(rel, "..") {
+			continue
+		}
+		if sub, _, ok := strings.Cut(filepath.ToSlash(rel), "/"); ok {
+			counts[sub]++
 		}
 	}
 
-	// Sort by modification time (most recent first)
-	for i := 0; i < len(filesWithTime); i++ {
-		for j := i + 1; j < len(filesWithTime); j++ {
-			if filesWithTime[j].modTime.After(filesWithTime[i].modTime) {
-				filesWithTime[i], filesWithTime[j] = filesWithTime[j], filesWithTime[i]
-			}
+	sep, lock := " · ", "🔒 "
+	if plain {
+		sep, lock = " - ", ""
+	}
+
+	items := make([]list.Item, 0, len(entries))
+	for _, entry := range entries {
+		if !entry.IsDir() || strings.HasPrefix(entry.Name(), ".") {
+			continue
+		}
+		desc := fmt.Sprintf("Folder%s%d notes", sep, counts[entry.Name()])
+		if counts[entry.Name()] == 1 {
+			desc = "Folder" + sep + "1 note"
 		}
+		items = append(items, Item{
+			title:    entry.Name() + "/",
+			desc:     desc,
+			filename: filepath.Join(folder, entry.Name()),
+			dir:      true,
+		})
 	}
 
 	// Create list items from sorted files
-	for _, file := range filesWithTime {
+	for _, note := range found {
+		if filepath.Dir(note.Name) != filepath.Clean(filepath.Join(".", folder)) {
+			continue
+		}
+		desc := fmt.Sprintf("Modified: %s", note.Modified.Format("2006-01-02 15:04"))
+		if IsEncrypted(note.Name) {
+			desc = lock + "Encrypted" + sep + desc
+		}
 		items = append(items, Item{
-			title:    file.name,
-			desc:     fmt.Sprintf("Modified: %s", file.modTime.Format("2006-01-02 15:04")),
-			filename: file.name, // Store full filename for opening
+			title:    filepath.Base(note.Name),
+			desc:     desc,
+			filename: note.Name, // Store the vault-relative path for opening
 		})
 	}
 
-	return items
+	return items, nil
+}
+
+// ValidateName checks that a note name can be used as a filename. A name
+// may start with folders separated by "/", e.g. "projects/plan"; each part
+// must be a plain, visible name.
+func ValidateName(name string) error {
+	if strings.TrimSpace(name) == "" {
+		return ErrEmptyName
+	}
+	for _, part := range strings.Split(name, "/") {
+		if strings.TrimSpace(part) == "" || strings.HasPrefix(part, ".") {
+			return ErrInvalidName
+		}
+		for _, char := range invalidChars {
+			if strings.Contains(part, char) {
+				return ErrInvalidName
+			}
+		}
+	}
+	return nil
+}
+
+// FileName returns the filename for a note name, adding the extension
+// unless the name already ends with it
+func FileName(name, ext string) string {
+	if ext == "" || strings.HasSuffix(name, ext) {
+		return name
+	}
+	return name + ext
+}
+
+// Resolve finds the filename of an existing note by name. The name may be
+// given with or without the extension.
+func Resolve(v Vault, name, ext string) (string, error) {
+	if err := ValidateName(name); err != nil {
+		return "", err
+	}
+	for _, candidate := range []string{name, FileName(name, ext)} {
+		info, err := v.Stat(filepath.FromSlash(candidate))
+		if err == nil && !info.IsDir() {
+			return candidate, nil
+		}
+	}
+	return "", fmt.Errorf("%s: %w", name, ErrNotFound)
+}
+
+// Create validates the name and creates a new empty note, returning its
+// vault-relative filename
+func Create(v Vault, name, ext string) (string, error) {
+	name = strings.TrimSpace(name)
+	if err := ValidateName(name); err != nil {
+		return "", err
+	}
+
+	filename := filepath.FromSlash(FileName(name, ext))
+	if err := v.Create(filename); err != nil {
+		return "", err
+	}
+	return filename, nil
+}
+
+// MoveNote moves a note to another vault-relative filename, creating the
+// target folder if needed. It returns ErrExists if the target is taken.
+func MoveNote(v Vault, from, to string) error {
+	if err := ValidateName(filepath.ToSlash(to)); err != nil {
+		return err
+	}
+	err := v.Rename(from, to)
+	if err != nil && !errors.Is(err, ErrExists) {
+		return fmt.Errorf("error moving note: %w", err)
+	}
+	return err
+}
+
+// DailyNoteName returns the name of the daily note for the given day
+func DailyNoteName(t time.Time) string {
+	return t.Format("2006-01-02")
+}
+
+// AddToNote adds text to the start or end of a note, creating the note if
+// it does not exist. Entries are separated from existing content by a blank line.
+func AddToNote(v Vault, name, ext, text string, prepend bool) (string, error) {
+	if err := ValidateName(name); err != nil {
+		return "", err
+	}
+
+	filename, err := Resolve(v, name, ext)
+	if errors.Is(err, ErrNotFound) {
+		filename, err = FileName(name, ext), nil
+	}
+	if err != nil {
+		return "", err
+	}
+	if IsEncrypted(filename) {
+		return "", fmt.Errorf("%s: %w", filename, ErrEncrypted)
+	}
+	filename = filepath.FromSlash(filename)
+
+	existing, err := v.Read(filename)
+	if err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return "", err
+	}
+
+	entry := strings.TrimRight(text, "\n") + "\n"
+	current := string(existing)
+
+	var content string
+	switch {
+	case strings.TrimSpace(current) == "":
+		content = entry
+	case prepend:
+		content = entry + "\n" + current
+	default:
+		content = strings.TrimRight(current, "\n") + "\n\n" + entry
+	}
+
+	if err := v.Write(filename, []byte(content)); err != nil {
+		return "", err
+	}
+	return filename, nil
+}
+
+// WriteFile replaces the file at path with data without ever leaving a
+// partly written note behind. The data goes to a hidden temp file in the
+// same directory, which is synced and then renamed over the original.
+// The original file's permissions are kept; new files get 0644.
+func WriteFile(path string, data []byte) error {
+	perm := os.FileMode(0644)
+	if info, err := os.Stat(path); err == nil {
+		perm = info.Mode().Perm()
+	} else if !errors.Is(err, os.ErrNotExist) {
+		return fmt.Errorf("error reading note: %w", err)
+	}
+
+	dir, base := filepath.Split(path)
+	tmp, err := os.CreateTemp(dir, "."+base+".*.tmp")
+	if err != nil {
+		return fmt.Errorf("error creating temp file: %w", err)
+	}
+	// Remove the temp file unless it was renamed into place
+	defer os.Remove(tmp.Name())
+
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return fmt.Errorf("error writing note: %w", err)
+	}
+	if err := tmp.Chmod(perm); err != nil {
+		tmp.Close()
+		return fmt.Errorf("error setting permissions: %w", err)
+	}
+	if err := tmp.Sync(); err != nil {
+		tmp.Close()
+		return fmt.Errorf("error syncing note: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("error writing note: %w", err)
+	}
+
+	if err := os.Rename(tmp.Name(), path); err != nil {
+		return fmt.Errorf("error replacing note: %w", err)
+	}
+
+	// Sync the directory so the rename itself survives a crash
+	if d, err := os.Open(filepath.Clean(dir)); err == nil {
+		d.Sync()
+		d.Close()
+	}
+	return nil
+}
+
+// Stamp identifies the version of a note on disk
+type Stamp struct {
+	ModTime time.Time
+	Size    int64
+	Hash    [sha256.Size]byte
+}
+
+// NewStamp records the version of a note from its file info and content
+func NewStamp(info os.FileInfo, content []byte) Stamp {
+	return Stamp{
+		ModTime: info.ModTime(),
+		Size:    info.Size(),
+		Hash:    sha256.Sum256(content),
+	}
+}
+
+// Changed reports whether a note of the vault differs from the stamped
+// version. The content is only read and hashed when the modification time
+// or size differ, so touching a file without editing it does not count as a
+// change. It also returns the current content when it was read.
+func (s Stamp) Changed(v Vault, filename string) (bool, []byte, error) {
+	info, err := v.Stat(filename)
+	if err != nil {
+		return false, nil, err
+	}
+	if info.ModTime().Equal(s.ModTime) && info.Size() == s.Size {
+		return false, nil, nil
+	}
+
+	content, err := v.Read(filename)
+	if err != nil {
+		return false, nil, err
+	}
+	return sha256.Sum256(content) != s.Hash, content, nil
+}
+
+// Match is a single line of a note matching a search query
+type Match struct {
+	Note string `json:"note"`
+	Line int    `json:"line"`
+	Text string `json:"text"`
+}
+
+// Search returns every line in the vault containing the query, ignoring case.
+// A note whose name matches is reported with line 0. Encrypted notes only
+// match by name.
+func Search(v Vault, query string) ([]Match, error) {
+	found, err := ListNotes(v)
+	if err != nil {
+		return nil, err
+	}
+
+	needle := strings.ToLower(query)
+	matches := make([]Match, 0)
+	for _, note := range found {
+		if strings.Contains(strings.ToLower(note.Name), needle) {
+			matches = append(matches, Match{Note: note.Name})
+		}
+		// The content of encrypted notes is never searched
+		if IsEncrypted(note.Name) {
+			continue
+		}
+
+		content, err := v.Read(note.Name)
+		if err != nil {
+			continue
+		}
+		scanner := bufio.NewScanner(bytes.NewReader(content))
+		scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+		lineNo := 0
+		for scanner.Scan() {
+			lineNo++
+			if strings.Contains(strings.ToLower(scanner.Text()), needle) {
+				matches = append(matches, Match{Note: note.Name, Line: lineNo, Text: scanner.Text()})
+			}
+		}
+	}
+
+	return matches, nil
 }
@@ -0,0 +1,133 @@
+package notes
+
+import (
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+)
+
+func TestListFilesMarkers(t *testing.T) {
+	v := NewMemVault(map[string]string{
+		"secret.md.enc": "x",
+		"work/plan.md":  "plan",
+	})
+
+	for _, plain := range []bool{false, true} {
+		items, err := ListFiles(v, "", plain)
+		if err != nil {
+			t.Fatal(err)
+		}
+		var descs []string
+		for _, item := range items {
+			descs = append(descs, item.(Item).Description())
+		}
+		all := strings.Join(descs, "\n")
+
+		if !strings.Contains(all, "Encrypted") || !strings.Contains(all, "1 note") {
+			t.Errorf("plain=%v: descriptions miss the folder or encrypted note:\n%s", plain, all)
+		}
+		hasFancy := strings.ContainsAny(all, "🔒·")
+		if hasFancy == plain {
+			t.Errorf("plain=%v: descriptions %q", plain, all)
+		}
+	}
+}
+
+// dirNames returns the names of the entries in dir
+func dirNames(t *testing.T, dir string) []string {
+	t.Helper()
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var names []string
+	for _, e := range entries {
+		names = append(names, e.Name())
+	}
+	return names
+}
+
+func TestWriteFile(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "plan.md")
+
+	if err := WriteFile(path, []byte("one")); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Chmod(path, 0600); err != nil {
+		t.Fatal(err)
+	}
+	if err := WriteFile(path, []byte("two")); err != nil {
+		t.Fatal(err)
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(data) != "two" {
+		t.Errorf("plan.md = %q, want %q", data, "two")
+	}
+	if runtime.GOOS != "windows" {
+		info, err := os.Stat(path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if perm := info.Mode().Perm(); perm != 0600 {
+			t.Errorf("mode = %v, want the original 0600", perm)
+		}
+	}
+	if names := dirNames(t, dir); len(names) != 1 || names[0] != "plan.md" {
+		t.Errorf("directory holds %v, want only plan.md", names)
+	}
+}
+
+func TestWriteFileFailedRename(t *testing.T) {
+	dir := t.TempDir()
+	// A non-empty directory cannot be replaced by a file
+	path := filepath.Join(dir, "plan.md")
+	if err := os.MkdirAll(path, 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(path, "inner"), []byte("keep"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := WriteFile(path, []byte("new")); err == nil {
+		t.Fatal("WriteFile over a directory succeeded")
+	}
+	if names := dirNames(t, dir); len(names) != 1 || names[0] != "plan.md" {
+		t.Errorf("directory holds %v after a failed rename, want only plan.md", names)
+	}
+	if data, err := os.ReadFile(filepath.Join(path, "inner")); err != nil || string(data) != "keep" {
+		t.Errorf("target changed: %q, %v", data, err)
+	}
+}
+
+func TestWriteFileFailedWrite(t *testing.T) {
+	if runtime.GOOS == "windows" || os.Geteuid() == 0 {
+		t.Skip("needs directory permissions that are enforced")
+	}
+	dir := t.TempDir()
+	path := filepath.Join(dir, "plan.md")
+	if err := os.WriteFile(path, []byte("old"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	// No temp file can be created next to the note
+	if err := os.Chmod(dir, 0500); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { os.Chmod(dir, 0700) })
+
+	if err := WriteFile(path, []byte("new")); err == nil {
+		t.Fatal("WriteFile in a read-only directory succeeded")
+	}
+	if data, err := os.ReadFile(path); err != nil || string(data) != "old" {
+		t.Errorf("plan.md = %q, %v, want it unchanged", data, err)
+	}
+	if names := dirNames(t, dir); len(names) != 1 {
+		t.Errorf("directory holds %v, want only plan.md", names)
+	}
+}
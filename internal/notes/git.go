@@ -0,0 +1,223 @@
+package notes
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// ErrConflictMarkers is returned when committing a note that still has
+// unresolved merge conflict markers
+var ErrConflictMarkers = errors.New("note still has merge conflict markers")
+
+// Commit is an entry in the git log of a note
+type Commit struct {
+	Hash    string
+	Author  string
+	Time    time.Time
+	Subject string
+	Path    string // Path of the note in this commit, relative to the repository root
+}
+
+// ConflictError is returned when a pull leaves files with merge conflicts
+type ConflictError struct {
+	Files []string // Vault-relative paths of the conflicted files
+}
+
+func (e *ConflictError) Error() string {
+	return "merge conflicts in " + strings.Join(e.Files, ", ")
+}
+
+// git runs git in the vault directory and returns its standard output. The
+// error carries git's own message.
+func git(vaultDir string, args ...string) (string, error) {
+	cmd := exec.Command("git", args...)
+	cmd.Dir = vaultDir
+	// Never stop to ask for credentials or a merge message: the UI owns the
+	// terminal. Paths are passed as they are, not as patterns.
+	cmd.Env = append(os.Environ(), "GIT_TERMINAL_PROMPT=0", "GIT_MERGE_AUTOEDIT=no", "GIT_LITERAL_PATHSPECS=1")
+	if os.Getenv("GIT_SSH_COMMAND") == "" {
+		cmd.Env = append(cmd.Env, "GIT_SSH_COMMAND=ssh -o BatchMode=yes")
+	}
+
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		msg := strings.TrimSpace(stderr.String())
+		if msg == "" {
+			msg = strings.TrimSpace(stdout.String())
+		}
+		if msg == "" {
+			msg = err.Error()
+		}
+		return stdout.String(), fmt.Errorf("git %s: %s", args[0], msg)
+	}
+	return stdout.String(), nil
+}
+
+// IsGitRepo reports whether the vault is inside a git work tree and the
+// git binary is available
+func IsGitRepo(vaultDir string) bool {
+	out, err := git(vaultDir, "rev-parse", "--is-inside-work-tree")
+	return err == nil && strings.TrimSpace(out) == "true"
+}
+
+// GitMerging reports whether a merge is waiting to be concluded in the vault
+func GitMerging(vaultDir string) bool {
+	_, err := git(vaultDir, "rev-parse", "-q", "--verify", "MERGE_HEAD")
+	return err == nil
+}
+
+// GitConflicts returns the vault-relative paths of files with unresolved
+// merge conflicts
+func GitConflicts(vaultDir string) ([]string, error) {
+	out, err := git(vaultDir, "diff", "--name-only", "--diff-filter=U", "--relative", "-z")
+	if err != nil {
+		return nil, err
+	}
+	var files []string
+	for _, name := range strings.Split(out, "\x00") {
+		if name != "" {
+			files = append(files, filepath.FromSlash(name))
+		}
+	}
+	return files, nil
+}
+
+// HasConflictMarkers reports whether content still has the markers git
+// leaves around conflicting changes
+func HasConflictMarkers(content string) bool {
+	for _, line := range strings.Split(content, "\n") {
+		if strings.HasPrefix(line, "<<<<<<< ") || strings.HasPrefix(line, ">>>>>>> ") {
+			return true
+		}
+	}
+	return false
+}
+
+// GitCommit commits the current state of the given vault-relative paths,
+// whether they were added, changed or deleted. Nothing is committed when
+// they have not changed. While a merge is in progress the paths are marked
+// as resolved instead, and the merge is committed once no conflicts remain.
+func GitCommit(vaultDir, message string, paths ...string) error {
+	// Only paths that exist or that git knows about can be staged
+	out, err := git(vaultDir, append([]string{"ls-files", "-z", "--"}, paths...)...)
+	if err != nil {
+		return err
+	}
+	tracked := make(map[string]bool)
+	for _, name := range strings.Split(out, "\x00") {
+		tracked[filepath.FromSlash(name)] = true
+	}
+	var staged []string
+	for _, path := range paths {
+		if _, err := os.Lstat(filepath.Join(vaultDir, path)); err == nil || tracked[path] {
+			staged = append(staged, path)
+		}
+	}
+	if len(staged) == 0 {
+		return nil
+	}
+
+	if GitMerging(vaultDir) {
+		return gitResolve(vaultDir, staged)
+	}
+
+	if _, err := git(vaultDir, append([]string{"add", "-A", "--"}, staged...)...); err != nil {
+		return err
+	}
+	status, err := git(vaultDir, append([]string{"status", "--porcelain", "--"}, staged...)...)
+	if err != nil {
+		return err
+	}
+	if strings.TrimSpace(status) == "" {
+		return nil
+	}
+	_, err = git(vaultDir, append([]string{"commit", "-q", "-m", message, "--"}, staged...)...)
+	return err
+}
+
+// gitResolve marks files as resolved during a merge and concludes the merge
+// when it was the last conflict
+func gitResolve(vaultDir string, paths []string) error {
+	for _, path := range paths {
+		content, err := os.ReadFile(filepath.Join(vaultDir, path))
+		if err == nil && HasConflictMarkers(string(content)) {
+			return fmt.Errorf("%s: %w", path, ErrConflictMarkers)
+		}
+	}
+	if _, err := git(vaultDir, append([]string{"add", "-A", "--"}, paths...)...); err != nil {
+		return err
+	}
+
+	conflicts, err := GitConflicts(vaultDir)
+	if err != nil || len(conflicts) > 0 {
+		return err
+	}
+	_, err = git(vaultDir, "commit", "-q", "--no-edit")
+	return err
+}
+
+// GitLog returns the commits that changed a note, newest first, following
+// it across renames
+func GitLog(vaultDir, filename string) ([]Commit, error) {
+	out, err := git(vaultDir, "log", "--follow", "--name-only",
+		"--format=%x1e%H%x1f%an%x1f%aI%x1f%s", "--", filename)
+	if err != nil {
+		return nil, err
+	}
+
+	var commits []Commit
+	for _, record := range strings.Split(out, "\x1e") {
+		header, names, _ := strings.Cut(record, "\n")
+		fields := strings.Split(header, "\x1f")
+		if len(fields) != 4 {
+			continue
+		}
+		when, _ := time.Parse(time.RFC3339, fields[2])
+		commits = append(commits, Commit{
+			Hash:    fields[0],
+			Author:  fields[1],
+			Time:    when,
+			Subject: fields[3],
+			Path:    strings.TrimSpace(names),
+		})
+	}
+	return commits, nil
+}
+
+// GitPatch returns the changes a commit made to a note as a unified diff
+func GitPatch(vaultDir string, c Commit) (string, error) {
+	top, err := git(vaultDir, "rev-parse", "--show-toplevel")
+	if err != nil {
+		return "", err
+	}
+	return git(strings.TrimSpace(top), "show", "--format=", "--no-color", "--no-ext-diff", c.Hash, "--", c.Path)
+}
+
+// GitSync pulls the vault's remote changes, merging them with local
+// commits, then pushes. A pull that leaves conflicts returns a
+// *ConflictError listing the conflicted files.
+func GitSync(vaultDir string) error {
+	if _, err := git(vaultDir, "pull", "--no-rebase", "--no-edit"); err != nil {
+		if conflicts, _ := GitConflicts(vaultDir); len(conflicts) > 0 {
+			return &ConflictError{Files: conflicts}
+		}
+		return err
+	}
+	_, err := git(vaultDir, "push")
+	return err
+}
+
+// GitAbortMerge abandons a merge in progress, restoring the notes as they
+// were before the pull
+func GitAbortMerge(vaultDir string) error {
+	_, err := git(vaultDir, "merge", "--abort")
+	return err
+}
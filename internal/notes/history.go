@@ -0,0 +1,149 @@
+package notes
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+// snapshotTimeFormat names snapshot files so they sort by time
+const snapshotTimeFormat = "20060102T150405.000000000Z"
+
+// Snapshot is a saved version of a note in its history
+type Snapshot struct {
+	Path string    // Snapshot file
+	Time time.Time // When the version was saved
+}
+
+// SnapshotDir returns the directory holding the history of a note, named
+// after the note without its extension: work/plan.md keeps its versions
+// in work/plan/<time>.md
+func SnapshotDir(historyDir, filename string) string {
+	return filepath.Join(historyDir, strings.TrimSuffix(filename, filepath.Ext(filename)))
+}
+
+// snapshotExt returns the extension of a note's snapshot files. It keeps
+// apart the histories of notes that differ only in their extension.
+func snapshotExt(filename string) string {
+	if ext := filepath.Ext(filename); ext != "" {
+		return ext
+	}
+	return ".md"
+}
+
+// SaveSnapshot adds a version of a note to its history. Nothing is written
+// if the content is the same as the newest snapshot.
+func SaveSnapshot(historyDir, filename, content string, now time.Time) error {
+	snapshots, err := ListSnapshots(historyDir, filename)
+	if err != nil {
+		return err
+	}
+	if len(snapshots) > 0 {
+		if latest, err := os.ReadFile(snapshots[0].Path); err == nil && string(latest) == content {
+			return nil
+		}
+	}
+
+	dir := SnapshotDir(historyDir, filename)
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return fmt.Errorf("error creating history directory: %w", err)
+	}
+	path := filepath.Join(dir, now.UTC().Format(snapshotTimeFormat)+snapshotExt(filename))
+	if err := WriteFile(path, []byte(content)); err != nil {
+		return fmt.Errorf("error writing snapshot: %w", err)
+	}
+	return nil
+}
+
+// ListSnapshots returns the history of a note, newest first
+func ListSnapshots(historyDir, filename string) ([]Snapshot, error) {
+	entries, err := os.ReadDir(SnapshotDir(historyDir, filename))
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("error reading history: %w", err)
+	}
+
+	var snapshots []Snapshot
+	for _, entry := range entries {
+		name := entry.Name()
+		if entry.IsDir() || strings.HasPrefix(name, ".") || filepath.Ext(name) != snapshotExt(filename) {
+			continue
+		}
+		t, err := time.Parse(snapshotTimeFormat, strings.TrimSuffix(name, filepath.Ext(name)))
+		if err != nil {
+			continue
+		}
+		snapshots = append(snapshots, Snapshot{
+			Path: filepath.Join(SnapshotDir(historyDir, filename), name),
+			Time: t,
+		})
+	}
+
+	sort.Slice(snapshots, func(i, j int) bool {
+		return snapshots[i].Time.After(snapshots[j].Time)
+	})
+	return snapshots, nil
+}
+
+// MoveSnapshots moves the history of a note along with the note
+func MoveSnapshots(historyDir, from, to string) error {
+	snapshots, err := ListSnapshots(historyDir, from)
+	if err != nil || len(snapshots) == 0 {
+		return err
+	}
+	dst := SnapshotDir(historyDir, to)
+	if err := os.MkdirAll(dst, 0755); err != nil {
+		return fmt.Errorf("error moving history: %w", err)
+	}
+	for _, s := range snapshots {
+		name := s.Time.UTC().Format(snapshotTimeFormat) + snapshotExt(to)
+		if err := os.Rename(s.Path, filepath.Join(dst, name)); err != nil {
+			return fmt.Errorf("error moving history: %w", err)
+		}
+	}
+	// The directory may still hold the history of a note with another extension
+	os.Remove(SnapshotDir(historyDir, from))
+	return nil
+}
+
+// DeleteSnapshots deletes the whole history of a note
+func DeleteSnapshots(historyDir, filename string) error {
+	snapshots, err := ListSnapshots(historyDir, filename)
+	if err != nil {
+		return err
+	}
+	for _, s := range snapshots {
+		if err := os.Remove(s.Path); err != nil && !errors.Is(err, fs.ErrNotExist) {
+			return fmt.Errorf("error deleting history: %w", err)
+		}
+	}
+	os.Remove(SnapshotDir(historyDir, filename))
+	return nil
+}
+
+// PruneSnapshots deletes the versions of a note beyond the newest keep, and
+// those older than maxAge. A zero keep or maxAge does not limit history.
+func PruneSnapshots(historyDir, filename string, keep int, maxAge time.Duration, now time.Time) error {
+	snapshots, err := ListSnapshots(historyDir, filename)
+	if err != nil {
+		return err
+	}
+	for i, s := range snapshots {
+		tooMany := keep > 0 && i >= keep
+		tooOld := maxAge > 0 && now.Sub(s.Time) > maxAge
+		if !tooMany && !tooOld {
+			continue
+		}
+		if err := os.Remove(s.Path); err != nil && !errors.Is(err, fs.ErrNotExist) {
+			return fmt.Errorf("error removing snapshot: %w", err)
+		}
+	}
+	return nil
+}
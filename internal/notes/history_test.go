@@ -0,0 +1,59 @@
+package notes
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestSnapshotLayout(t *testing.T) {
+	dir := t.TempDir()
+	now := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+
+	if err := SaveSnapshot(dir, "work/plan.md", "v1", now); err != nil {
+		t.Fatal(err)
+	}
+	if err := SaveSnapshot(dir, "work/plan.txt", "other", now); err != nil {
+		t.Fatal(err)
+	}
+	want := filepath.Join(dir, "work", "plan", "20260301T120000.000000000Z.md")
+	if _, err := os.Stat(want); err != nil {
+		t.Fatalf("snapshot not at %s: %v", want, err)
+	}
+
+	// The same content is not saved twice
+	if err := SaveSnapshot(dir, "work/plan.md", "v1", now.Add(time.Minute)); err != nil {
+		t.Fatal(err)
+	}
+	if err := SaveSnapshot(dir, "work/plan.md", "v2", now.Add(2*time.Minute)); err != nil {
+		t.Fatal(err)
+	}
+	snapshots, err := ListSnapshots(dir, "work/plan.md")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(snapshots) != 2 || !snapshots[0].Time.Equal(now.Add(2*time.Minute)) {
+		t.Fatalf("ListSnapshots = %v, want 2 newest first", snapshots)
+	}
+
+	// Moving and deleting leave the note with another extension alone
+	if err := MoveSnapshots(dir, "work/plan.md", "archive/plan.md"); err != nil {
+		t.Fatal(err)
+	}
+	if moved, _ := ListSnapshots(dir, "archive/plan.md"); len(moved) != 2 {
+		t.Errorf("moved history has %d versions, want 2", len(moved))
+	}
+	if left, _ := ListSnapshots(dir, "work/plan.md"); len(left) != 0 {
+		t.Errorf("%d versions stayed behind", len(left))
+	}
+	if err := DeleteSnapshots(dir, "archive/plan.md"); err != nil {
+		t.Fatal(err)
+	}
+	if left, _ := ListSnapshots(dir, "archive/plan.md"); len(left) != 0 {
+		t.Errorf("%d versions left after deleting", len(left))
+	}
+	if other, _ := ListSnapshots(dir, "work/plan.txt"); len(other) != 1 {
+		t.Errorf("plan.txt has %d versions, want 1", len(other))
+	}
+}
@@ -0,0 +1,344 @@
+package notes
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+	"unicode/utf8"
+)
+
+// ImportFormat names a kind of source notes can be imported from
+type ImportFormat string
+
+const (
+	FormatENEX     ImportFormat = "enex"     // Evernote export file
+	FormatJoplin   ImportFormat = "joplin"   // Joplin "RAW - Joplin Export Directory"
+	FormatObsidian ImportFormat = "obsidian" // Obsidian vault
+	FormatText     ImportFormat = "text"     // Folder of .txt and .md files, or a single one
+)
+
+// ImportFormats lists the formats Import understands
+var ImportFormats = []ImportFormat{FormatENEX, FormatJoplin, FormatObsidian, FormatText}
+
+// attachmentsFolder is where converted notes keep their attachments, below
+// the folder they are imported into
+const attachmentsFolder = "attachments"
+
+// maxTitleLength caps the length of note names made from titles
+const maxTitleLength = 100
+
+// ImportFile is a note or attachment to be written into the vault
+type ImportFile struct {
+	Name   string // Path below the import folder, with "/" between folders
+	Source string // Where it came from, for reports
+	Data   []byte
+}
+
+// ImportPlan holds the files read and converted from a source
+type ImportPlan struct {
+	Source  string
+	Format  ImportFormat
+	Files   []ImportFile
+	Skipped []string // Source entries that were left out, with the reason
+	names   map[string]bool
+}
+
+// add adds a file to the plan under a name no other file of the plan uses,
+// numbering it "name-2.md", "name-3.md"... if needed. It returns the name.
+func (p *ImportPlan) add(name, source string, data []byte) string {
+	if p.names == nil {
+		p.names = make(map[string]bool)
+	}
+	ext := path.Ext(name)
+	base := strings.TrimSuffix(name, ext)
+	for i := 2; p.names[strings.ToLower(name)]; i++ {
+		name = fmt.Sprintf("%s-%d%s", base, i, ext)
+	}
+	p.names[strings.ToLower(name)] = true
+	p.Files = append(p.Files, ImportFile{Name: name, Source: source, Data: data})
+	return name
+}
+
+// skip records a source entry left out of the plan
+func (p *ImportPlan) skip(source, reason string) {
+	p.Skipped = append(p.Skipped, source+": "+reason)
+}
+
+// ReadImport reads the notes of a source and converts them to notes of the
+// vault, with ext as the extension of converted notes. The format is
+// detected from the source when empty. Nothing is written.
+func ReadImport(source string, format ImportFormat, ext string) (*ImportPlan, error) {
+	if format == "" {
+		detected, err := DetectFormat(source)
+		if err != nil {
+			return nil, err
+		}
+		format = detected
+	}
+
+	plan := &ImportPlan{Source: source, Format: format}
+	var err error
+	switch format {
+	case FormatENEX:
+		err = readENEX(plan, source, ext)
+	case FormatJoplin:
+		err = readJoplin(plan, source, ext)
+	case FormatObsidian:
+		err = readObsidian(plan, source)
+	case FormatText:
+		err = readText(plan, source, ext)
+	default:
+		return nil, fmt.Errorf("unknown import format %q", format)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return plan, nil
+}
+
+// DetectFormat tells the format of a source from its name and content
+func DetectFormat(source string) (ImportFormat, error) {
+	info, err := os.Stat(source)
+	if err != nil {
+		return "", fmt.Errorf("error reading import source: %w", err)
+	}
+
+	if !info.IsDir() {
+		switch strings.ToLower(filepath.Ext(source)) {
+		case ".enex":
+			return FormatENEX, nil
+		case ".txt", ".md", ".markdown":
+			return FormatText, nil
+		}
+		return "", fmt.Errorf("cannot tell the format of %s", source)
+	}
+
+	if info, err := os.Stat(filepath.Join(source, ".obsidian")); err == nil && info.IsDir() {
+		return FormatObsidian, nil
+	}
+	if isJoplinExport(source) {
+		return FormatJoplin, nil
+	}
+	return FormatText, nil
+}
+
+// ImportReport tells what an import did, or would do for a dry run
+type ImportReport struct {
+	DryRun   bool
+	Created  []string // Vault-relative filenames written
+	Existing []string // Vault-relative filenames that already existed and were left alone
+	Skipped  []string // Source entries that were not converted, with the reason
+}
+
+// Import writes the files of a plan below a folder of the vault. As when
+// creating a note, a file is never overwritten: files whose name is taken
+// are left alone and reported as existing. A dry run only reports.
+func Import(v Vault, plan *ImportPlan, folder string, dryRun bool) (ImportReport, error) {
+	report := ImportReport{DryRun: dryRun, Skipped: plan.Skipped}
+	for _, file := range plan.Files {
+		filename := filepath.Join(folder, filepath.FromSlash(file.Name))
+
+		if dryRun {
+			_, err := v.Stat(filename)
+			switch {
+			case err == nil:
+				report.Existing = append(report.Existing, filename)
+			case errors.Is(err, fs.ErrNotExist):
+				report.Created = append(report.Created, filename)
+			default:
+				return report, fmt.Errorf("error importing %s: %w", file.Source, err)
+			}
+			continue
+		}
+
+		err := v.Create(filename)
+		if errors.Is(err, ErrExists) {
+			report.Existing = append(report.Existing, filename)
+			continue
+		}
+		if err != nil {
+			return report, fmt.Errorf("error importing %s: %w", file.Source, err)
+		}
+		if err := v.Write(filename, file.Data); err != nil {
+			return report, fmt.Errorf("error importing %s: %w", file.Source, err)
+		}
+		report.Created = append(report.Created, filename)
+	}
+	return report, nil
+}
+
+// Summary describes the report in one line, e.g. "Imported 3 files, 1
+// already exists"
+func (r ImportReport) Summary() string {
+	verb := "Imported"
+	if r.DryRun {
+		verb = "Would import"
+	}
+	summary := verb + " " + countFiles(len(r.Created))
+	if n := len(r.Existing); n == 1 {
+		summary += ", 1 already exists"
+	} else if n > 1 {
+		summary += fmt.Sprintf(", %d already exist", n)
+	}
+	if n := len(r.Skipped); n > 0 {
+		summary += fmt.Sprintf(", %d skipped", n)
+	}
+	return summary
+}
+
+// countFiles returns "1 file" or "n files"
+func countFiles(n int) string {
+	if n == 1 {
+		return "1 file"
+	}
+	return fmt.Sprintf("%d files", n)
+}
+
+// readObsidian copies every file of an Obsidian vault as it is, so wiki
+// links and attachments keep working. The hidden .obsidian and .trash
+// folders are left out.
+func readObsidian(plan *ImportPlan, dir string) error {
+	return walkSource(dir, func(rel string, data []byte) {
+		if err := ValidateName(rel); err != nil {
+			plan.skip(rel, err.Error())
+			return
+		}
+		plan.add(rel, rel, data)
+	})
+}
+
+// readText turns the .txt files of a folder into notes and copies its
+// markdown files. A single file may be given instead of a folder.
+func readText(plan *ImportPlan, source, ext string) error {
+	info, err := os.Stat(source)
+	if err != nil {
+		return fmt.Errorf("error reading import source: %w", err)
+	}
+	if !info.IsDir() {
+		data, err := os.ReadFile(source)
+		if err != nil {
+			return fmt.Errorf("error reading import source: %w", err)
+		}
+		addText(plan, filepath.Base(source), data, ext)
+		return nil
+	}
+
+	return walkSource(source, func(rel string, data []byte) {
+		addText(plan, rel, data, ext)
+	})
+}
+
+// addText adds a text or markdown file to the plan, giving .txt files the
+// extension of notes
+func addText(plan *ImportPlan, rel string, data []byte, ext string) {
+	switch strings.ToLower(path.Ext(rel)) {
+	case ".txt":
+		name := strings.TrimSuffix(rel, path.Ext(rel)) + ext
+		if err := ValidateName(name); err != nil {
+			plan.skip(rel, err.Error())
+			return
+		}
+		plan.add(name, rel, data)
+	case ".md", ".markdown":
+		if err := ValidateName(rel); err != nil {
+			plan.skip(rel, err.Error())
+			return
+		}
+		plan.add(rel, rel, data)
+	default:
+		plan.skip(rel, "not a text file")
+	}
+}
+
+// walkSource calls fn with the "/" separated path and content of every file
+// below dir, skipping hidden files and folders
+func walkSource(dir string, fn func(rel string, data []byte)) error {
+	err := filepath.WalkDir(dir, func(p string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if p != dir && strings.HasPrefix(d.Name(), ".") {
+			if d.IsDir() {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if !d.Type().IsRegular() {
+			return nil
+		}
+		rel, err := filepath.Rel(dir, p)
+		if err != nil {
+			return err
+		}
+		data, err := os.ReadFile(p)
+		if err != nil {
+			return err
+		}
+		fn(filepath.ToSlash(rel), data)
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("error reading import source: %w", err)
+	}
+	return nil
+}
+
+// noteTitle turns a title into a name a note can have, replacing
+// characters that are not allowed in names
+func noteTitle(title string) string {
+	title = strings.Map(func(r rune) rune {
+		if r == '/' || r < ' ' {
+			return '-'
+		}
+		for _, char := range invalidChars {
+			if string(r) == char {
+				return '-'
+			}
+		}
+		return r
+	}, title)
+	title = strings.Trim(strings.TrimSpace(title), ".")
+	for utf8.RuneCountInString(title) > maxTitleLength {
+		_, size := utf8.DecodeLastRuneInString(title)
+		title = title[:len(title)-size]
+	}
+	title = strings.TrimSpace(title)
+	if title == "" {
+		return "Untitled"
+	}
+	return title
+}
+
+// linkTarget returns a path for use as a markdown link target
+func linkTarget(p string) string {
+	return strings.NewReplacer(" ", "%20", "(", "%28", ")", "%29").Replace(p)
+}
+
+// tagLine returns the line listing a note's tags as #tags, or "" for none
+func tagLine(tags []string) string {
+	var line []string
+	for _, tag := range tags {
+		tag = strings.Join(strings.Fields(tag), "-")
+		if tag != "" {
+			line = append(line, "#"+tag)
+		}
+	}
+	return strings.Join(line, " ")
+}
+
+// convertedNote assembles a converted note from its title, tags and body
+func convertedNote(title string, tags []string, body string) []byte {
+	var b strings.Builder
+	b.WriteString("# " + strings.TrimSpace(title) + "\n")
+	if line := tagLine(tags); line != "" {
+		b.WriteString("\n" + line + "\n")
+	}
+	if body = strings.TrimSpace(body); body != "" {
+		b.WriteString("\n" + body + "\n")
+	}
+	return []byte(b.String())
+}
@@ -0,0 +1,170 @@
+package notes
+
+import (
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+// readFixture reads an import source from testdata and returns its files
+// by name
+func readFixture(t *testing.T, source string, want ImportFormat) (*ImportPlan, map[string]string) {
+	t.Helper()
+	plan, err := ReadImport(filepath.Join("testdata", source), "", ".md")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if plan.Format != want {
+		t.Fatalf("%s detected as %q, want %q", source, plan.Format, want)
+	}
+	files := make(map[string]string)
+	for _, file := range plan.Files {
+		files[file.Name] = string(file.Data)
+	}
+	return plan, files
+}
+
+func TestReadENEX(t *testing.T) {
+	plan, files := readFixture(t, "notes.enex", FormatENEX)
+
+	want := map[string]string{
+		"attachments/map.png":          "PNGDATA",
+		"attachments/attachment-2.pdf": "PDFDATA",
+		"Trip- Lisbon.md": "# Trip: Lisbon\n\n" +
+			"#travel #summer-plans\n\n" +
+			"## Packing\n\n" +
+			"- [x] Passport\n\n" +
+			"- [ ] Charger\n\n" +
+			"- Light & **warm** clothes\n- Books\n\n" +
+			"Map: ![map.png](attachments/map.png)\n\n" +
+			"Tickets: [attachment-2.pdf](attachments/attachment-2.pdf)\n",
+		// The same title is numbered, the same attachment saved once
+		"Trip- Lisbon-2.md": "# Trip: Lisbon\n\n" +
+			"Second note with the same title, showing the same map.\n\n" +
+			"![map.png](attachments/map.png)\n",
+	}
+	if !reflect.DeepEqual(files, want) {
+		t.Errorf("converted files:\n%q\nwant:\n%q", files, want)
+	}
+	if len(plan.Skipped) != 0 {
+		t.Errorf("skipped %q", plan.Skipped)
+	}
+}
+
+func TestReadJoplin(t *testing.T) {
+	plan, files := readFixture(t, "joplin", FormatJoplin)
+
+	// Notebooks become folders, links become relative paths, tags are
+	// kept and notes in the trash are left out
+	want := map[string]string{
+		"Work/Plan.md": "# Plan\n\n#urgent\n\n" +
+			"See [ideas](../Ideas.md#later) and ![chart](../attachments/chart.png).\n",
+		"Ideas.md":              "# Ideas\n\nSome **bold** ideas\n",
+		"attachments/chart.png": "PNGDATA",
+	}
+	if !reflect.DeepEqual(files, want) {
+		t.Errorf("converted files:\n%q\nwant:\n%q", files, want)
+	}
+	if len(plan.Skipped) != 0 {
+		t.Errorf("skipped %q", plan.Skipped)
+	}
+}
+
+func TestParseJoplinItem(t *testing.T) {
+	tests := []struct {
+		name  string
+		data  string
+		title string
+		body  string
+		ok    bool
+	}{
+		{"note", "Title\n\nline one\nline two\n\nid: 1\ntype_: 1\n", "Title", "line one\nline two", true},
+		{"windows line endings", "Title\r\n\r\nbody\r\n\r\ntype_: 1\r\n", "Title", "body", true},
+		{"no body", "Title\n\ntype_: 2", "Title", "", true},
+		{"properties only", "note_id: 1\ntag_id: 2\ntype_: 6\n", "", "", true},
+		{"no type", "Title\n\nid: 1\n", "", "", false},
+		{"plain markdown", "# Title\n\nSome text\n", "", "", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			item, ok := parseJoplinItem(tt.data)
+			if ok != tt.ok || (ok && (item.title != tt.title || item.body != tt.body)) {
+				t.Errorf("parseJoplinItem = %q, %q, %v, want %q, %q, %v", item.title, item.body, ok, tt.title, tt.body, tt.ok)
+			}
+		})
+	}
+}
+
+func TestImportPlanAdd(t *testing.T) {
+	var plan ImportPlan
+	var got []string
+	for _, name := range []string{"Plan.md", "plan.md", "PLAN.md", "plan-2.md", "work/plan.md", "README", "README"} {
+		got = append(got, plan.add(name, name, nil))
+	}
+
+	want := []string{"Plan.md", "plan-2.md", "PLAN-3.md", "plan-2-2.md", "work/plan.md", "README", "README-2"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("names = %q, want %q", got, want)
+	}
+}
+
+func TestImportCollisions(t *testing.T) {
+	plan, _ := readFixture(t, "notes.enex", FormatENEX)
+	existing := filepath.Join("inbox", "Trip- Lisbon.md")
+	v := NewMemVault(map[string]string{existing: "my own note"})
+
+	wantReport := func(dryRun bool) ImportReport {
+		return ImportReport{
+			DryRun: dryRun,
+			Created: []string{
+				filepath.Join("inbox", "attachments", "map.png"),
+				filepath.Join("inbox", "attachments", "attachment-2.pdf"),
+				filepath.Join("inbox", "Trip- Lisbon-2.md"),
+			},
+			Existing: []string{existing},
+		}
+	}
+
+	// A dry run reports without writing
+	report, err := Import(v, plan, "inbox", true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(report, wantReport(true)) {
+		t.Errorf("dry run report = %+v", report)
+	}
+	if got := report.Summary(); got != "Would import 3 files, 1 already exists" {
+		t.Errorf("dry run summary = %q", got)
+	}
+	if _, err := v.Stat(filepath.Join("inbox", "Trip- Lisbon-2.md")); err == nil {
+		t.Error("dry run wrote a note")
+	}
+
+	// Importing writes the same files and leaves the existing one alone
+	report, err = Import(v, plan, "inbox", false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(report, wantReport(false)) {
+		t.Errorf("import report = %+v", report)
+	}
+	if got := report.Summary(); got != "Imported 3 files, 1 already exists" {
+		t.Errorf("import summary = %q", got)
+	}
+	if data, _ := v.Read(existing); string(data) != "my own note" {
+		t.Errorf("existing note overwritten with %q", data)
+	}
+	if data, _ := v.Read(filepath.Join("inbox", "attachments", "map.png")); string(data) != "PNGDATA" {
+		t.Errorf("attachment written as %q", data)
+	}
+
+	// Importing again creates nothing
+	report, err = Import(v, plan, "inbox", false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := report.Summary(); got != "Imported 0 files, 4 already exist" {
+		t.Errorf("second import summary = %q", got)
+	}
+}
@@ -0,0 +1,214 @@
+package notes
+
+import (
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// Item types of a Joplin export
+const (
+	joplinNote     = "1"
+	joplinFolder   = "2"
+	joplinResource = "4"
+	joplinTag      = "5"
+	joplinNoteTag  = "6"
+)
+
+var (
+	// joplinFile matches the name of an item file of a Joplin export
+	joplinFile = regexp.MustCompile(`^[0-9a-f]{32}\.md$`)
+	// joplinLink matches the target of a link to another item, :/id
+	joplinLink = regexp.MustCompile(`\]\(:/([0-9a-f]{32})((?:#[^)\s]*)?)\)`)
+)
+
+// joplinItem is an item of a Joplin export: a note, notebook, attachment,
+// tag or the tagging of a note
+type joplinItem struct {
+	title string
+	body  string
+	meta  map[string]string
+}
+
+// parseJoplinItem parses an item file: its title, a blank line, its body
+// and, after another blank line, a "key: value" line per property. Items
+// without a title hold the properties alone.
+func parseJoplinItem(data string) (joplinItem, bool) {
+	lines := strings.Split(strings.ReplaceAll(data, "\r\n", "\n"), "\n")
+	for len(lines) > 0 && lines[len(lines)-1] == "" {
+		lines = lines[:len(lines)-1]
+	}
+
+	item := joplinItem{meta: make(map[string]string)}
+	end := len(lines)
+	for end > 0 && lines[end-1] != "" {
+		key, value, ok := strings.Cut(lines[end-1], ": ")
+		if !ok {
+			key, ok = strings.CutSuffix(lines[end-1], ":")
+		}
+		if !ok {
+			return item, false
+		}
+		item.meta[key] = value
+		end--
+	}
+	if item.meta["type_"] == "" {
+		return item, false
+	}
+
+	// The tagging of a note has properties only
+	if end > 0 {
+		item.title = lines[0]
+	}
+	if end > 2 {
+		item.body = strings.Join(lines[2:end-1], "\n")
+	}
+	return item, true
+}
+
+// isJoplinExport reports whether a folder holds a Joplin raw export
+func isJoplinExport(dir string) bool {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return false
+	}
+	for _, entry := range entries {
+		if entry.IsDir() || !joplinFile.MatchString(entry.Name()) {
+			continue
+		}
+		data, err := os.ReadFile(filepath.Join(dir, entry.Name()))
+		if err != nil {
+			return false
+		}
+		_, ok := parseJoplinItem(string(data))
+		return ok
+	}
+	return false
+}
+
+// readJoplin converts a Joplin raw export. Notebooks become folders,
+// attachments go in the attachments folder and links between items are
+// rewritten to relative paths.
+func readJoplin(plan *ImportPlan, dir, ext string) error {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return fmt.Errorf("error reading import source: %w", err)
+	}
+
+	items := make(map[string]joplinItem)
+	for _, entry := range entries {
+		if entry.IsDir() || !joplinFile.MatchString(entry.Name()) {
+			continue
+		}
+		data, err := os.ReadFile(filepath.Join(dir, entry.Name()))
+		if err != nil {
+			return fmt.Errorf("error reading import source: %w", err)
+		}
+		item, ok := parseJoplinItem(string(data))
+		if !ok {
+			plan.skip(entry.Name(), "not a Joplin item")
+			continue
+		}
+		items[strings.TrimSuffix(entry.Name(), ".md")] = item
+	}
+
+	// Folder of each notebook, following its parents
+	folders := make(map[string]string)
+	var folder func(id string, depth int) string
+	folder = func(id string, depth int) string {
+		item, ok := items[id]
+		if !ok || item.meta["type_"] != joplinFolder || depth > len(items) {
+			return ""
+		}
+		if name, ok := folders[id]; ok {
+			return name
+		}
+		name := path.Join(folder(item.meta["parent_id"], depth+1), noteTitle(item.title))
+		folders[id] = name
+		return name
+	}
+
+	tags := make(map[string][]string)
+	for _, id := range sortedKeys(items) {
+		if item := items[id]; item.meta["type_"] == joplinNoteTag {
+			if tag, ok := items[item.meta["tag_id"]]; ok && tag.meta["type_"] == joplinTag {
+				tags[item.meta["note_id"]] = append(tags[item.meta["note_id"]], tag.title)
+			}
+		}
+	}
+
+	// Name every note and attachment first so that links can be rewritten
+	names := make(map[string]string)
+	noteIDs := make(map[string]string) // Note ids by name
+	for _, id := range sortedKeys(items) {
+		item := items[id]
+		switch item.meta["type_"] {
+		case joplinNote:
+			// Notes in Joplin's trash are left out
+			if deleted := item.meta["deleted_time"]; deleted != "" && deleted != "0" {
+				continue
+			}
+			name := path.Join(folder(item.meta["parent_id"], 0), noteTitle(item.title)) + ext
+			names[id] = plan.add(name, item.title, nil)
+			noteIDs[names[id]] = id
+		case joplinResource:
+			resExt := item.meta["file_extension"]
+			data, err := os.ReadFile(filepath.Join(dir, "resources", id+"."+resExt))
+			if resExt == "" {
+				data, err = os.ReadFile(filepath.Join(dir, "resources", id))
+			}
+			if err != nil {
+				plan.skip(item.title, "attachment file not found")
+				continue
+			}
+			name := noteTitle(item.title)
+			if resExt != "" && !strings.EqualFold(path.Ext(name), "."+resExt) {
+				name += "." + resExt
+			}
+			names[id] = plan.add(path.Join(attachmentsFolder, name), item.title, data)
+		}
+	}
+
+	for i, file := range plan.Files {
+		id, ok := noteIDs[file.Name]
+		if !ok {
+			continue
+		}
+		item := items[id]
+		body := item.body
+		if item.meta["markup_language"] == "2" {
+			if converted, err := convertENML(body, nil); err == nil {
+				body = converted
+			}
+		}
+		body = joplinLink.ReplaceAllStringFunc(body, func(match string) string {
+			parts := joplinLink.FindStringSubmatch(match)
+			target, ok := names[parts[1]]
+			if !ok {
+				return match
+			}
+			rel, err := filepath.Rel(filepath.FromSlash(path.Dir(file.Name)), filepath.FromSlash(target))
+			if err != nil {
+				return match
+			}
+			return "](" + linkTarget(filepath.ToSlash(rel)) + parts[2] + ")"
+		})
+		plan.Files[i].Data = convertedNote(item.title, tags[id], body)
+	}
+	return nil
+}
+
+// sortedKeys returns the ids of items in order, so that an import names
+// notes the same way every time
+func sortedKeys(items map[string]joplinItem) []string {
+	ids := make([]string, 0, len(items))
+	for id := range items {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+	return ids
+}
@@ -0,0 +1,188 @@
+package notes
+
+import (
+	"fmt"
+	"net/url"
+	"path"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"unicode/utf8"
+)
+
+var (
+	// markdownLink matches the target of [text](target "title") and
+	// ![alt](target), with the target optionally in angle brackets
+	markdownLink = regexp.MustCompile(`(\]\()(<[^>\n]*>|[^)\s]+)([^)\n]*\))`)
+	// wikiLink matches [[target]], [[target#heading]] and [[target|alias]]
+	wikiLink = regexp.MustCompile(`(\[\[)([^\]|#\n]+)([^\]\n]*\]\])`)
+)
+
+// RewriteLinks updates the markdown and wiki links in every note of the
+// vault after the note at from was renamed or moved to to, and the
+// relative links inside the moved note itself. Encrypted notes are left
+// alone. It returns the notes that were changed.
+func RewriteLinks(v Vault, from, to string) ([]string, error) {
+	found, err := ListNotes(v)
+	if err != nil {
+		return nil, err
+	}
+
+	// Wiki links by base name only follow the note if no other note shares it
+	byBase := true
+	for _, note := range found {
+		if note.Name != to && stem(filepath.Base(note.Name)) == stem(filepath.Base(from)) {
+			byBase = false
+		}
+	}
+
+	var changed []string
+	for _, note := range found {
+		if IsEncrypted(note.Name) {
+			continue
+		}
+		content, err := v.Read(note.Name)
+		if err != nil {
+			return changed, fmt.Errorf("error reading note: %w", err)
+		}
+		// Leave images and other binary files alone
+		if !utf8.Valid(content) {
+			continue
+		}
+
+		old := note.Name
+		if note.Name == to {
+			old = from
+		}
+		updated := rewriteLinks(string(content), old, note.Name, from, to, byBase)
+		if updated == string(content) {
+			continue
+		}
+		if err := v.Write(note.Name, []byte(updated)); err != nil {
+			return changed, err
+		}
+		changed = append(changed, note.Name)
+	}
+	return changed, nil
+}
+
+// stem returns a filename without its extension, or both extensions of an
+// encrypted note, so that wiki links by name survive encrypting it
+func stem(name string) string {
+	name = strings.TrimSuffix(name, EncryptedExt)
+	return strings.TrimSuffix(name, path.Ext(name))
+}
+
+// rewriteLinks rewrites the links in the content of a note that was at old
+// and is now at current, for a note moved from from to to. Fenced code
+// blocks are left untouched.
+func rewriteLinks(content, old, current, from, to string, byBase bool) string {
+	old, current = filepath.ToSlash(old), filepath.ToSlash(current)
+	from, to = filepath.ToSlash(from), filepath.ToSlash(to)
+
+	lines := strings.SplitAfter(content, "\n")
+	fenced := false
+	for i, line := range lines {
+		trimmed := strings.TrimSpace(line)
+		if strings.HasPrefix(trimmed, "```") || strings.HasPrefix(trimmed, "~~~") {
+			fenced = !fenced
+			continue
+		}
+		if fenced {
+			continue
+		}
+
+		line = markdownLink.ReplaceAllStringFunc(line, func(match string) string {
+			parts := markdownLink.FindStringSubmatch(match)
+			return parts[1] + rewriteMarkdownTarget(parts[2], path.Dir(old), path.Dir(current), from, to) + parts[3]
+		})
+		line = wikiLink.ReplaceAllStringFunc(line, func(match string) string {
+			parts := wikiLink.FindStringSubmatch(match)
+			return parts[1] + rewriteWikiTarget(parts[2], from, to, byBase) + parts[3]
+		})
+		lines[i] = line
+	}
+	return strings.Join(lines, "")
+}
+
+// rewriteMarkdownTarget returns the target of a markdown link written in
+// oldDir as it should read in newDir once from has moved to to. Links
+// starting with "/" are relative to the vault; URLs are left as they are.
+func rewriteMarkdownTarget(target, oldDir, newDir, from, to string) string {
+	raw := target
+	angled := strings.HasPrefix(raw, "<") && strings.HasSuffix(raw, ">")
+	if angled {
+		raw = raw[1 : len(raw)-1]
+	}
+	if raw == "" || strings.HasPrefix(raw, "#") || strings.Contains(raw, ":") {
+		return target
+	}
+
+	linkPath, fragment, hasFragment := strings.Cut(raw, "#")
+	unescaped, err := url.PathUnescape(linkPath)
+	if err != nil {
+		return target
+	}
+	escaped := unescaped != linkPath
+
+	rooted := strings.HasPrefix(unescaped, "/")
+	resolved := path.Clean(strings.TrimPrefix(unescaped, "/"))
+	if !rooted {
+		resolved = path.Join(oldDir, unescaped)
+	}
+	if resolved == ".." || strings.HasPrefix(resolved, "../") {
+		return target
+	}
+
+	switch {
+	case resolved == from:
+		resolved = to
+	case path.Ext(resolved) == "" && resolved+path.Ext(from) == from:
+		resolved = strings.TrimSuffix(to, path.Ext(to))
+	case rooted || oldDir == newDir:
+		return target // Not a link to the moved note and still valid
+	}
+
+	var rewritten string
+	if rooted {
+		rewritten = "/" + resolved
+	} else {
+		rel, err := filepath.Rel(filepath.FromSlash(newDir), filepath.FromSlash(resolved))
+		if err != nil {
+			return target
+		}
+		rewritten = filepath.ToSlash(rel)
+	}
+	if escaped {
+		rewritten = strings.ReplaceAll(rewritten, " ", "%20")
+	}
+	if hasFragment {
+		rewritten += "#" + fragment
+	}
+	if angled {
+		rewritten = "<" + rewritten + ">"
+	}
+	return rewritten
+}
+
+// rewriteWikiTarget returns the target of a wiki link once from has moved
+// to to. A wiki link names a note by its path or, when byBase is set, by
+// its base name, with or without the extension.
+func rewriteWikiTarget(target, from, to string, byBase bool) string {
+	name := strings.TrimSpace(target)
+
+	var rewritten string
+	switch {
+	case name == from:
+		rewritten = to
+	case name == stem(from):
+		rewritten = stem(to)
+	case byBase && name == path.Base(from):
+		rewritten = path.Base(to)
+	case byBase && name == path.Base(stem(from)):
+		rewritten = path.Base(stem(to))
+	default:
+		return target
+	}
+	return strings.Replace(target, name, rewritten, 1)
+}
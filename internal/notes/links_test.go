@@ -0,0 +1,186 @@
+package notes
+
+import (
+	"path/filepath"
+	"reflect"
+	"sort"
+	"testing"
+)
+
+func TestRewriteMarkdownTarget(t *testing.T) {
+	tests := []struct {
+		name           string
+		target         string
+		oldDir, newDir string
+		from, to       string
+		want           string
+	}{
+		{"relative", "plan.md", "work", "work", "work/plan.md", "archive/plan.md", "../archive/plan.md"},
+		{"relative from the root", "work/plan.md", ".", ".", "work/plan.md", "archive/plan.md", "archive/plan.md"},
+		{"rooted", "/work/plan.md", "notes", "notes", "work/plan.md", "archive/plan.md", "/archive/plan.md"},
+		{"angled", "<work/my plan.md>", ".", ".", "work/my plan.md", "archive/my plan.md", "<archive/my plan.md>"},
+		{"escaped", "work/my%20plan.md", ".", ".", "work/my plan.md", "archive/my plan.md", "archive/my%20plan.md"},
+		{"fragment", "plan.md#next-steps", "work", "work", "work/plan.md", "archive/plan.md", "../archive/plan.md#next-steps"},
+		{"without extension", "plan", "work", "work", "work/plan.md", "archive/roadmap.md", "../archive/roadmap"},
+		{"renamed in place", "plan.md", "work", "work", "work/plan.md", "work/roadmap.md", "roadmap.md"},
+		{"other note", "todo.md", "work", "work", "work/plan.md", "archive/plan.md", "todo.md"},
+		{"url", "https://example.com/plan.md", "work", "work", "work/plan.md", "archive/plan.md", "https://example.com/plan.md"},
+		{"anchor", "#plan", "work", "work", "work/plan.md", "archive/plan.md", "#plan"},
+		{"outside the vault", "../../plan.md", "work", "archive", "work/plan.md", "archive/plan.md", "../../plan.md"},
+		{"bad escape", "plan%zz.md", "work", "archive", "work/plan.md", "archive/plan.md", "plan%zz.md"},
+
+		// Links inside the moved note itself
+		{"sibling of a moved note", "todo.md", "work", "archive", "work/plan.md", "archive/plan.md", "../work/todo.md"},
+		{"self-link after a move", "plan.md#goals", "work", "archive", "work/plan.md", "archive/plan.md", "plan.md#goals"},
+		{"rooted in a moved note", "/work/todo.md", "work", "archive", "work/plan.md", "archive/plan.md", "/work/todo.md"},
+		{"escaped in a moved note", "<my todo.md>", "work", "archive/2026", "work/plan.md", "archive/2026/plan.md", "<../../work/my todo.md>"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := rewriteMarkdownTarget(tt.target, tt.oldDir, tt.newDir, tt.from, tt.to)
+			if got != tt.want {
+				t.Errorf("rewriteMarkdownTarget(%q, %q, %q) = %q, want %q", tt.target, tt.oldDir, tt.newDir, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRewriteWikiTarget(t *testing.T) {
+	const from, to = "work/plan.md", "archive/roadmap.md"
+	tests := []struct {
+		name   string
+		target string
+		byBase bool
+		want   string
+	}{
+		{"path", "work/plan.md", false, "archive/roadmap.md"},
+		{"path without extension", "work/plan", false, "archive/roadmap"},
+		{"base name", "plan", true, "roadmap"},
+		{"base name with extension", "plan.md", true, "roadmap.md"},
+		{"base name shared by another note", "plan", false, "plan"},
+		{"spaces kept", " plan ", true, " roadmap "},
+		{"other note", "todo", true, "todo"},
+		{"same base in another folder", "other/plan", true, "other/plan"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := rewriteWikiTarget(tt.target, from, to, tt.byBase); got != tt.want {
+				t.Errorf("rewriteWikiTarget(%q, byBase %v) = %q, want %q", tt.target, tt.byBase, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRewriteLinksInContent(t *testing.T) {
+	const from, to = "work/plan.md", "archive/roadmap.md"
+	tests := []struct {
+		name    string
+		content string
+		want    string
+	}{
+		{
+			"title kept",
+			`See [the plan](plan.md "Plan for Q4").`,
+			`See [the plan](../archive/roadmap.md "Plan for Q4").`,
+		},
+		{
+			"several links on a line",
+			"[a](plan.md) and ![chart](plan.md#chart) and [b](todo.md)",
+			"[a](../archive/roadmap.md) and ![chart](../archive/roadmap.md#chart) and [b](todo.md)",
+		},
+		{
+			"wiki heading and alias",
+			"[[plan#Goals|the plan]] and [[work/plan|plan]]",
+			"[[roadmap#Goals|the plan]] and [[archive/roadmap|plan]]",
+		},
+		{
+			"fenced code skipped",
+			"```md\n[plan](plan.md) [[plan]]\n```\n[plan](plan.md)\n~~~\n[[plan]]\n~~~\n[[plan]]\n",
+			"```md\n[plan](plan.md) [[plan]]\n```\n[plan](../archive/roadmap.md)\n~~~\n[[plan]]\n~~~\n[[roadmap]]\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := rewriteLinks(tt.content, filepath.FromSlash("work/todo.md"), filepath.FromSlash("work/todo.md"), filepath.FromSlash(from), filepath.FromSlash(to), true)
+			if got != tt.want {
+				t.Errorf("rewriteLinks() =\n%s\nwant\n%s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRewriteLinks(t *testing.T) {
+	tests := []struct {
+		name    string
+		files   map[string]string
+		want    map[string]string
+		changed []string
+	}{
+		{
+			name: "links follow the note",
+			files: map[string]string{
+				"work/plan.md":  "[todo](todo.md) and [me](plan.md#top)\n```\n[todo](todo.md)\n```\n",
+				"work/todo.md":  "[[plan]] [plan](plan.md) [[plan#Goals|goals]]",
+				"index.md":      "[plan](/work/plan.md) [[work/plan]]",
+				"secret.md.enc": "[[plan]]",
+			},
+			want: map[string]string{
+				"archive/plan.md": "[todo](../work/todo.md) and [me](plan.md#top)\n```\n[todo](todo.md)\n```\n",
+				"work/todo.md":    "[[plan]] [plan](../archive/plan.md) [[plan#Goals|goals]]",
+				"index.md":        "[plan](/archive/plan.md) [[archive/plan]]",
+				"secret.md.enc":   "[[plan]]",
+			},
+			changed: []string{"archive/plan.md", "index.md", "work/todo.md"},
+		},
+		{
+			name: "base name shared by another note",
+			files: map[string]string{
+				"work/plan.md":  "",
+				"other/plan.md": "",
+				"work/todo.md":  "[[plan]] [[work/plan]]",
+			},
+			want: map[string]string{
+				"work/todo.md": "[[plan]] [[archive/plan]]",
+			},
+			changed: []string{"work/todo.md"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			files := make(map[string]string, len(tt.files))
+			for name, content := range tt.files {
+				files[filepath.FromSlash(name)] = content
+			}
+			v := NewMemVault(files)
+			from, to := filepath.FromSlash("work/plan.md"), filepath.FromSlash("archive/plan.md")
+			if err := MoveNote(v, from, to); err != nil {
+				t.Fatal(err)
+			}
+
+			changed, err := RewriteLinks(v, from, to)
+			if err != nil {
+				t.Fatal(err)
+			}
+			for i := range changed {
+				changed[i] = filepath.ToSlash(changed[i])
+			}
+			sort.Strings(changed)
+			if !reflect.DeepEqual(changed, tt.changed) {
+				t.Errorf("changed = %v, want %v", changed, tt.changed)
+			}
+			for name, want := range tt.want {
+				data, err := v.Read(filepath.FromSlash(name))
+				if err != nil {
+					t.Fatal(err)
+				}
+				if string(data) != want {
+					t.Errorf("%s =\n%s\nwant\n%s", name, data, want)
+				}
+			}
+		})
+	}
+}
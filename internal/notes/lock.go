@@ -0,0 +1,213 @@
+package notes
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Lock records which TermNote process has a note open in its editor
+type Lock struct {
+	PID   int
+	Host  string
+	Since time.Time
+}
+
+// LockedError is returned when a note is locked by another process
+type LockedError struct {
+	Holder Lock
+}
+
+func (e *LockedError) Error() string {
+	return fmt.Sprintf("note is open in PID %d on host %s", e.Holder.PID, e.Holder.Host)
+}
+
+// LockPath returns the lock file of a note
+func LockPath(lockDir, filename string) string {
+	return filepath.Join(lockDir, filename+".lock")
+}
+
+// currentLock describes this process as a lock holder
+func currentLock() Lock {
+	host, err := os.Hostname()
+	if err != nil {
+		host = "unknown"
+	}
+	return Lock{PID: os.Getpid(), Host: host, Since: time.Now()}
+}
+
+// Mine reports whether the lock is held by this process
+func (l Lock) Mine() bool {
+	me := currentLock()
+	return l.PID == me.PID && l.Host == me.Host
+}
+
+// Stale reports whether the lock was left behind by a process on this host
+// that is no longer running. Locks from other hosts are never stale since
+// their processes cannot be checked.
+func (l Lock) Stale() bool {
+	if l.Host != currentLock().Host {
+		return false
+	}
+	if l.PID <= 0 {
+		return true
+	}
+	return !processRunning(l.PID)
+}
+
+// AcquireLock locks a note for this process. A stale lock is replaced; a
+// lock held by another running process is reported as a *LockedError.
+func AcquireLock(lockDir, filename string) error {
+	path := LockPath(lockDir, filename)
+	if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		return fmt.Errorf("error creating lock directory: %w", err)
+	}
+
+	for attempt := 0; attempt < 2; attempt++ {
+		f, err := os.OpenFile(path, os.O_CREATE|os.O_EXCL|os.O_WRONLY, 0600)
+		if err == nil {
+			_, err = f.WriteString(formatLock(currentLock()))
+			if closeErr := f.Close(); err == nil {
+				err = closeErr
+			}
+			if err != nil {
+				os.Remove(path)
+				return fmt.Errorf("error writing lock file: %w", err)
+			}
+			return nil
+		}
+		if !errors.Is(err, fs.ErrExist) {
+			return fmt.Errorf("error creating lock file: %w", err)
+		}
+
+		holder, err := ReadLock(lockDir, filename)
+		if errors.Is(err, fs.ErrNotExist) {
+			continue // Released in the meantime
+		}
+		if err != nil {
+			return err
+		}
+		if holder.Mine() {
+			return nil
+		}
+		if !holder.Stale() {
+			return &LockedError{Holder: holder}
+		}
+		if err := os.Remove(path); err != nil && !errors.Is(err, fs.ErrNotExist) {
+			return fmt.Errorf("error removing stale lock: %w", err)
+		}
+	}
+	return fmt.Errorf("error creating lock file: %s keeps changing", path)
+}
+
+// StealLock takes over the lock of a note, whoever holds it
+func StealLock(lockDir, filename string) error {
+	path := LockPath(lockDir, filename)
+	if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		return fmt.Errorf("error creating lock directory: %w", err)
+	}
+	if err := WriteFile(path, []byte(formatLock(currentLock()))); err != nil {
+		return fmt.Errorf("error writing lock file: %w", err)
+	}
+	return nil
+}
+
+// ReadLock returns the holder of a note's lock. The error wraps
+// fs.ErrNotExist when the note is not locked.
+func ReadLock(lockDir, filename string) (Lock, error) {
+	data, err := os.ReadFile(LockPath(lockDir, filename))
+	if err != nil {
+		return Lock{}, err
+	}
+	return parseLock(string(data)), nil
+}
+
+// CheckLock reports a *LockedError when another running process has a
+// note open. Notes that are unlocked, locked by this process or locked
+// by a process that has gone away are fine to change.
+func CheckLock(lockDir, filename string) error {
+	holder, err := ReadLock(lockDir, filename)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("error reading lock file: %w", err)
+	}
+	if holder.Mine() || holder.Stale() {
+		return nil
+	}
+	return &LockedError{Holder: holder}
+}
+
+// ReleaseLock removes a note's lock if this process holds it. A lock taken
+// over by another process is left alone.
+func ReleaseLock(lockDir, filename string) error {
+	holder, err := ReadLock(lockDir, filename)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("error reading lock file: %w", err)
+	}
+	if !holder.Mine() {
+		return nil
+	}
+	err = os.Remove(LockPath(lockDir, filename))
+	if err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return fmt.Errorf("error removing lock file: %w", err)
+	}
+	return nil
+}
+
+// CleanLocks removes the stale locks in a lock directory
+func CleanLocks(lockDir string) error {
+	return filepath.WalkDir(lockDir, func(path string, d fs.DirEntry, err error) error {
+		if errors.Is(err, fs.ErrNotExist) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		if d.IsDir() || !strings.HasSuffix(path, ".lock") {
+			return nil
+		}
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return nil
+		}
+		if parseLock(string(data)).Stale() {
+			os.Remove(path)
+		}
+		return nil
+	})
+}
+
+// formatLock encodes a lock as "pid\nhost\ntime\n"
+func formatLock(l Lock) string {
+	return fmt.Sprintf("%d\n%s\n%s\n", l.PID, l.Host, l.Since.Format(time.RFC3339))
+}
+
+// parseLock decodes a lock file. Missing or malformed fields are left zero,
+// which makes a lock from this host stale.
+func parseLock(data string) Lock {
+	var l Lock
+	lines := strings.Split(data, "\n")
+	if len(lines) > 0 {
+		l.PID, _ = strconv.Atoi(strings.TrimSpace(lines[0]))
+	}
+	if len(lines) > 1 {
+		l.Host = strings.TrimSpace(lines[1])
+	}
+	if len(lines) > 2 {
+		l.Since, _ = time.Parse(time.RFC3339, strings.TrimSpace(lines[2]))
+	}
+	if l.Host == "" {
+		l.Host = currentLock().Host
+	}
+	return l
+}
@@ -0,0 +1,17 @@
+//go:build !unix
+
+package notes
+
+import "os"
+
+// processRunning reports whether a process with the given PID exists. On
+// Windows finding a process fails once it has exited; on other platforms
+// it always succeeds, so their locks are never taken for stale.
+func processRunning(pid int) bool {
+	p, err := os.FindProcess(pid)
+	if err != nil {
+		return false
+	}
+	p.Release()
+	return true
+}
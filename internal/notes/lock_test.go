@@ -0,0 +1,62 @@
+package notes
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestLockStale(t *testing.T) {
+	host := currentLock().Host
+	if (Lock{PID: os.Getpid(), Host: host}).Stale() {
+		t.Error("lock of this process is stale")
+	}
+	if !(Lock{PID: 0, Host: host}).Stale() {
+		t.Error("lock without a PID is not stale")
+	}
+	if (Lock{PID: 0, Host: host + ".elsewhere"}).Stale() {
+		t.Error("lock from another host is stale")
+	}
+}
+
+func TestCheckLock(t *testing.T) {
+	lockDir := t.TempDir()
+	host := currentLock().Host
+	tests := []struct {
+		name   string
+		holder *Lock
+		locked bool
+	}{
+		{"unlocked", nil, false},
+		{"this process", &Lock{PID: os.Getpid(), Host: host}, false},
+		{"stale", &Lock{PID: 0, Host: host}, false},
+		{"other host", &Lock{PID: 1, Host: host + ".elsewhere"}, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			filename := filepath.Join("dir", tt.name+".md")
+			if tt.holder != nil {
+				path := LockPath(lockDir, filename)
+				if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+					t.Fatal(err)
+				}
+				tt.holder.Since = time.Now()
+				if err := os.WriteFile(path, []byte(formatLock(*tt.holder)), 0600); err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			err := CheckLock(lockDir, filename)
+			var locked *LockedError
+			if got := errors.As(err, &locked); got != tt.locked {
+				t.Fatalf("CheckLock() = %v, want locked %v", err, tt.locked)
+			}
+			if !tt.locked && err != nil {
+				t.Fatalf("CheckLock() = %v", err)
+			}
+		})
+	}
+}
@@ -0,0 +1,15 @@
+//go:build unix
+
+package notes
+
+import (
+	"errors"
+	"syscall"
+)
+
+// processRunning reports whether a process with the given PID exists
+func processRunning(pid int) bool {
+	// Signal 0 only checks that the process exists
+	err := syscall.Kill(pid, 0)
+	return !errors.Is(err, syscall.ESRCH)
+}
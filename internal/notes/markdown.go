@@ -2,6 +2,7 @@ package notes
 
 import (
 	"strings"
+	"time"
 )
 
 // InsertBulletPoint inserts a bullet point at the current line
@@ -155,6 +156,19 @@ func InsertCodeBlock(language string) string {
 	return "```" + language + "\n\n```"
 }
 
+// CodeBlock wraps content in a fenced code block
+func CodeBlock(content, language string) string {
+	block := InsertCodeBlock(language)
+	// Split after the opening fence and put the content on its own lines
+	fence := strings.Index(block, "\n")
+	return block[:fence+1] + strings.TrimRight(content, "\n") + block[fence+1:]
+}
+
+// TimestampHeading returns a heading line for an entry written at t
+func TimestampHeading(t time.Time) string {
+	return "## " + t.Format("2006-01-02 15:04")
+}
+
 // InsertHorizontalRule inserts a horizontal rule
 func InsertHorizontalRule() string {
 	return "---\n"
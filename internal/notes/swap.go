@@ -0,0 +1,74 @@
+package notes
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// SwapPath returns the swap file holding unsaved edits of a note
+func SwapPath(swapDir, filename string) string {
+	return filepath.Join(swapDir, filename+".swp")
+}
+
+// WriteSwap stores the unsaved content of a note in its swap file. Swap
+// files are only readable by their owner.
+func WriteSwap(swapDir, filename, content string) error {
+	path := SwapPath(swapDir, filename)
+	if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		return fmt.Errorf("error creating swap directory: %w", err)
+	}
+
+	// Create the file first so WriteFile keeps its private permissions
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY, 0600)
+	if err != nil {
+		return fmt.Errorf("error creating swap file: %w", err)
+	}
+	f.Close()
+
+	return WriteFile(path, []byte(content))
+}
+
+// ReadSwap returns the content of a note's swap file and when it was written.
+// The error wraps os.ErrNotExist when the note has no swap file.
+func ReadSwap(swapDir, filename string) (string, time.Time, error) {
+	path := SwapPath(swapDir, filename)
+	info, err := os.Stat(path)
+	if err != nil {
+		return "", time.Time{}, err
+	}
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return "", time.Time{}, err
+	}
+	return string(content), info.ModTime(), nil
+}
+
+// RemoveSwap deletes a note's swap file, if there is one
+func RemoveSwap(swapDir, filename string) error {
+	err := os.Remove(SwapPath(swapDir, filename))
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return fmt.Errorf("error removing swap file: %w", err)
+	}
+	return nil
+}
+
+// MoveSwap moves a note's swap file along with the note, so edits left
+// behind by a crash are still offered when the note is opened under its
+// new name. A note without a swap file is left alone.
+func MoveSwap(swapDir, from, to string) error {
+	src := SwapPath(swapDir, from)
+	if _, err := os.Stat(src); errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+	dst := SwapPath(swapDir, to)
+	if err := os.MkdirAll(filepath.Dir(dst), 0700); err != nil {
+		return fmt.Errorf("error creating swap directory: %w", err)
+	}
+	if err := os.Rename(src, dst); err != nil {
+		return fmt.Errorf("error moving swap file: %w", err)
+	}
+	return nil
+}
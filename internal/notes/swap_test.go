@@ -0,0 +1,63 @@
+package notes
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+)
+
+func TestSwapFiles(t *testing.T) {
+	swapDir := t.TempDir()
+	filename := filepath.Join("work", "plan.md")
+
+	if _, _, err := ReadSwap(swapDir, filename); !errors.Is(err, os.ErrNotExist) {
+		t.Fatalf("ReadSwap without a swap file = %v, want os.ErrNotExist", err)
+	}
+
+	if err := WriteSwap(swapDir, filename, "draft"); err != nil {
+		t.Fatal(err)
+	}
+	if err := WriteSwap(swapDir, filename, "draft 2"); err != nil {
+		t.Fatal(err)
+	}
+	content, modified, err := ReadSwap(swapDir, filename)
+	if err != nil || content != "draft 2" || modified.IsZero() {
+		t.Fatalf("ReadSwap = %q, %v, %v", content, modified, err)
+	}
+	if runtime.GOOS != "windows" {
+		info, err := os.Stat(SwapPath(swapDir, filename))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if perm := info.Mode().Perm(); perm != 0600 {
+			t.Errorf("swap file mode = %v, want 0600", perm)
+		}
+	}
+
+	// The swap file follows the note to its new name
+	moved := filepath.Join("archive", "2026", "plan.md")
+	if err := MoveSwap(swapDir, filename, moved); err != nil {
+		t.Fatal(err)
+	}
+	if _, _, err := ReadSwap(swapDir, filename); !errors.Is(err, os.ErrNotExist) {
+		t.Errorf("swap file of the old name is still there: %v", err)
+	}
+	if content, _, err := ReadSwap(swapDir, moved); err != nil || content != "draft 2" {
+		t.Errorf("swap file of the new name = %q, %v", content, err)
+	}
+	if err := MoveSwap(swapDir, "none.md", "other.md"); err != nil {
+		t.Errorf("MoveSwap without a swap file = %v", err)
+	}
+
+	if err := RemoveSwap(swapDir, moved); err != nil {
+		t.Fatal(err)
+	}
+	if err := RemoveSwap(swapDir, moved); err != nil {
+		t.Errorf("removing a missing swap file = %v", err)
+	}
+	if _, err := os.Stat(SwapPath(swapDir, moved)); !errors.Is(err, os.ErrNotExist) {
+		t.Errorf("swap file still there after RemoveSwap: %v", err)
+	}
+}
@@ -0,0 +1,201 @@
+package notes
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"fmt"
+	"io/fs"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+// TrashDirName is the vault directory deleted notes are moved to. Like the
+// freedesktop.org trash, it holds the notes in files/ and where each one
+// came from in info/<name>.trashinfo.
+const TrashDirName = ".trash"
+
+// TrashItem is a deleted note waiting in the trash
+type TrashItem struct {
+	Name    string    // Name of the note inside the trash
+	Path    string    // Vault-relative filename the note was deleted from
+	Deleted time.Time // When the note was deleted
+}
+
+// trashPaths returns the vault-relative names of a trashed file and its info file
+func trashPaths(name string) (file, info string) {
+	return filepath.Join(TrashDirName, "files", name), filepath.Join(TrashDirName, "info", name+".trashinfo")
+}
+
+// Trash moves a note into the vault's trash and records where it came from
+func Trash(v Vault, filename string, now time.Time) (TrashItem, error) {
+	// Reserve a free name by creating its info file
+	base := filepath.Base(filename)
+	ext := filepath.Ext(base)
+	stem := strings.TrimSuffix(base, ext)
+	item := TrashItem{Path: filename, Deleted: now}
+	for n := 1; item.Name == ""; n++ {
+		name := base
+		if n > 1 {
+			name = fmt.Sprintf("%s.%d%s", stem, n, ext)
+		}
+		filePath, infoPath := trashPaths(name)
+		if _, err := v.Stat(filePath); err == nil {
+			continue
+		}
+		err := v.Create(infoPath)
+		if errors.Is(err, ErrExists) {
+			continue
+		}
+		if err != nil {
+			return TrashItem{}, fmt.Errorf("error writing trash info: %w", err)
+		}
+		item.Name = name
+	}
+
+	filePath, infoPath := trashPaths(item.Name)
+	info := fmt.Sprintf("[Trash Info]\nPath=%s\nDeletionDate=%s\n", filepath.ToSlash(filename), now.Format(time.RFC3339))
+	err := v.Write(infoPath, []byte(info))
+	if err == nil {
+		err = v.Rename(filename, filePath)
+	}
+	if err != nil {
+		v.Delete(infoPath)
+		return TrashItem{}, fmt.Errorf("error moving note to the trash: %w", err)
+	}
+	return item, nil
+}
+
+// ListTrash returns the notes in the vault's trash, most recently deleted first
+func ListTrash(v Vault) ([]TrashItem, error) {
+	infoDir := filepath.Join(TrashDirName, "info")
+	entries, err := v.List(infoDir)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("error reading trash: %w", err)
+	}
+
+	var items []TrashItem
+	for _, entry := range entries {
+		name, ok := strings.CutSuffix(entry.Name(), ".trashinfo")
+		if entry.IsDir() || !ok {
+			continue
+		}
+		data, err := v.Read(filepath.Join(infoDir, entry.Name()))
+		if err != nil {
+			continue
+		}
+		item, err := parseTrashInfo(data)
+		if err != nil {
+			continue
+		}
+		item.Name = name
+		items = append(items, item)
+	}
+
+	sort.Slice(items, func(i, j int) bool {
+		return items[i].Deleted.After(items[j].Deleted)
+	})
+	return items, nil
+}
+
+// parseTrashInfo parses the Path and DeletionDate of a .trashinfo file
+func parseTrashInfo(data []byte) (TrashItem, error) {
+	var item TrashItem
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	for scanner.Scan() {
+		key, value, ok := strings.Cut(scanner.Text(), "=")
+		if !ok {
+			continue
+		}
+		switch key {
+		case "Path":
+			item.Path = filepath.FromSlash(value)
+		case "DeletionDate":
+			item.Deleted, _ = time.Parse(time.RFC3339, value)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return TrashItem{}, err
+	}
+	if item.Path == "" {
+		return TrashItem{}, errors.New("trash info has no Path")
+	}
+	if err := checkTrashPath(item.Path); err != nil {
+		return TrashItem{}, err
+	}
+	return item, nil
+}
+
+// checkTrashPath makes sure a note restored from the trash lands inside the
+// vault and outside its hidden directories, such as the trash and the
+// settings, whatever its trash info says
+func checkTrashPath(path string) error {
+	if !filepath.IsLocal(path) {
+		return fmt.Errorf("trash info path %q is outside the vault", filepath.ToSlash(path))
+	}
+	for _, part := range strings.Split(filepath.ToSlash(path), "/") {
+		if strings.HasPrefix(part, ".") {
+			return fmt.Errorf("trash info path %q is in a hidden directory", filepath.ToSlash(path))
+		}
+	}
+	return nil
+}
+
+// RestoreTrash moves a note from the trash back to where it was deleted
+// from. It returns ErrExists if another note has taken its place.
+func RestoreTrash(v Vault, item TrashItem) error {
+	if err := checkTrashPath(item.Path); err != nil {
+		return err
+	}
+	filePath, infoPath := trashPaths(item.Name)
+	err := v.Rename(filePath, item.Path)
+	if errors.Is(err, ErrExists) {
+		return ErrExists
+	}
+	if err != nil {
+		return fmt.Errorf("error restoring note: %w", err)
+	}
+	v.Delete(infoPath)
+	return nil
+}
+
+// PurgeTrash deletes a note from the trash for good
+func PurgeTrash(v Vault, item TrashItem) error {
+	filePath, infoPath := trashPaths(item.Name)
+	if err := v.Delete(filePath); err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return fmt.Errorf("error purging note: %w", err)
+	}
+	if err := v.Delete(infoPath); err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return fmt.Errorf("error purging note: %w", err)
+	}
+	return nil
+}
+
+// PurgeOldTrash deletes the notes that have been in the trash longer than
+// maxAge and returns how many were deleted. A zero maxAge keeps them all.
+func PurgeOldTrash(v Vault, maxAge time.Duration, now time.Time) (int, error) {
+	if maxAge <= 0 {
+		return 0, nil
+	}
+	items, err := ListTrash(v)
+	if err != nil {
+		return 0, err
+	}
+
+	purged := 0
+	for _, item := range items {
+		if now.Sub(item.Deleted) <= maxAge {
+			continue
+		}
+		if err := PurgeTrash(v, item); err != nil {
+			return purged, err
+		}
+		purged++
+	}
+	return purged, nil
+}
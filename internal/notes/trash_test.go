@@ -0,0 +1,91 @@
+package notes
+
+import (
+	"errors"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestTrashRestore(t *testing.T) {
+	v := NewMemVault(map[string]string{"work/plan.md": "plan"})
+	now := time.Date(2026, 10, 17, 9, 0, 0, 0, time.UTC)
+
+	item, err := Trash(v, filepath.Join("work", "plan.md"), now)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := v.Stat(filepath.Join("work", "plan.md")); !errors.Is(err, fs.ErrNotExist) {
+		t.Fatalf("trashed note still in place: %v", err)
+	}
+
+	items, err := ListTrash(v)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(items) != 1 || items[0] != item || item.Path != filepath.Join("work", "plan.md") || !item.Deleted.Equal(now) {
+		t.Fatalf("ListTrash = %+v, want %+v", items, item)
+	}
+
+	if err := RestoreTrash(v, items[0]); err != nil {
+		t.Fatal(err)
+	}
+	if data, err := v.Read(filepath.Join("work", "plan.md")); err != nil || string(data) != "plan" {
+		t.Errorf("restored note = %q, %v", data, err)
+	}
+	if items, _ := ListTrash(v); len(items) != 0 {
+		t.Errorf("trash still holds %+v", items)
+	}
+}
+
+func TestTrashInfoOutsideVault(t *testing.T) {
+	tests := []struct {
+		name string
+		path string
+	}{
+		{"parent directory", "../outside.md"},
+		{"climbing out of a folder", "work/../../outside.md"},
+		{"absolute", "/tmp/outside.md"},
+		{"settings", ".termnote/config.toml"},
+		{"trash", ".trash/files/other.md"},
+		{"hidden folder", "work/.git/config"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			v := DirVault(filepath.Join(dir, "vault"))
+			if err := os.MkdirAll(string(v), 0755); err != nil {
+				t.Fatal(err)
+			}
+			filePath, infoPath := trashPaths("evil.md")
+			if err := v.Write(filePath, []byte("payload")); err != nil {
+				t.Fatal(err)
+			}
+			info := "[Trash Info]\nPath=" + tt.path + "\nDeletionDate=2026-10-17T09:00:00Z\n"
+			if err := v.Write(infoPath, []byte(info)); err != nil {
+				t.Fatal(err)
+			}
+
+			if _, err := parseTrashInfo([]byte(info)); err == nil {
+				t.Errorf("parseTrashInfo accepted Path=%s", tt.path)
+			}
+			if items, err := ListTrash(v); err != nil || len(items) != 0 {
+				t.Errorf("ListTrash = %+v, %v, want the entry skipped", items, err)
+			}
+
+			item := TrashItem{Name: "evil.md", Path: filepath.FromSlash(tt.path)}
+			if err := RestoreTrash(v, item); err == nil {
+				t.Errorf("RestoreTrash to %s succeeded", tt.path)
+			}
+			if data, err := v.Read(filePath); err != nil || string(data) != "payload" {
+				t.Errorf("trashed file = %q, %v, want it left in the trash", data, err)
+			}
+			if _, err := os.Stat(filepath.Join(dir, "outside.md")); !errors.Is(err, fs.ErrNotExist) {
+				t.Errorf("a file was written outside the vault: %v", err)
+			}
+		})
+	}
+}
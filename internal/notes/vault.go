@@ -0,0 +1,303 @@
+package notes
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+	"time"
+)
+
+// Vault stores the files of a vault. Names are vault-relative paths using
+// the OS separator, with "" for the vault itself. Missing files are
+// reported with errors wrapping fs.ErrNotExist.
+type Vault interface {
+	// List returns the files and folders in a folder of the vault, sorted by name
+	List(folder string) ([]fs.FileInfo, error)
+	// Read returns the content of a file
+	Read(name string) ([]byte, error)
+	// Write replaces the content of a file, creating it and its folder if
+	// needed, without ever leaving it partly written
+	Write(name string, data []byte) error
+	// Create creates a new empty file and its folder. It returns ErrExists
+	// if the name is taken.
+	Create(name string) error
+	// Delete removes a file or an empty folder
+	Delete(name string) error
+	// Rename moves a file, creating the target folder if needed. It returns
+	// ErrExists if the target is taken.
+	Rename(from, to string) error
+	// Stat returns the file info of a file or folder
+	Stat(name string) (fs.FileInfo, error)
+}
+
+// DirVault is a vault stored in a directory on disk
+type DirVault string
+
+// path returns the location of a vault file on disk
+func (d DirVault) path(name string) string {
+	return filepath.Join(string(d), name)
+}
+
+func (d DirVault) List(folder string) ([]fs.FileInfo, error) {
+	entries, err := os.ReadDir(d.path(folder))
+	if err != nil {
+		return nil, err
+	}
+	infos := make([]fs.FileInfo, 0, len(entries))
+	for _, entry := range entries {
+		// Skip files removed while listing
+		if info, err := entry.Info(); err == nil {
+			infos = append(infos, info)
+		}
+	}
+	return infos, nil
+}
+
+func (d DirVault) Read(name string) ([]byte, error) {
+	return os.ReadFile(d.path(name))
+}
+
+func (d DirVault) Write(name string, data []byte) error {
+	path := d.path(name)
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return fmt.Errorf("error creating folder: %w", err)
+	}
+	return WriteFile(path, data)
+}
+
+func (d DirVault) Create(name string) error {
+	path := d.path(name)
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+	f, err := os.OpenFile(path, os.O_RDWR|os.O_CREATE|os.O_EXCL, 0644)
+	if errors.Is(err, os.ErrExist) {
+		return ErrExists
+	}
+	if err != nil {
+		return err
+	}
+	return f.Close()
+}
+
+func (d DirVault) Delete(name string) error {
+	return os.Remove(d.path(name))
+}
+
+func (d DirVault) Rename(from, to string) error {
+	target := d.path(to)
+	if _, err := os.Lstat(target); err == nil {
+		return ErrExists
+	}
+	if err := os.MkdirAll(filepath.Dir(target), 0755); err != nil {
+		return fmt.Errorf("error creating folder: %w", err)
+	}
+	return os.Rename(d.path(from), target)
+}
+
+func (d DirVault) Stat(name string) (fs.FileInfo, error) {
+	return os.Stat(d.path(name))
+}
+
+// MemVault is a vault kept in memory, for demos and tests. It is safe for
+// concurrent use.
+type MemVault struct {
+	mu    sync.Mutex
+	files map[string]memFile
+	dirs  map[string]time.Time // Folders with their modification time
+}
+
+// memFile is a file of a MemVault
+type memFile struct {
+	data    []byte
+	modTime time.Time
+}
+
+// NewMemVault creates an in-memory vault holding the given files, keyed by
+// vault-relative name with "/" between folders
+func NewMemVault(files map[string]string) *MemVault {
+	v := &MemVault{
+		files: make(map[string]memFile),
+		dirs:  make(map[string]time.Time),
+	}
+	now := time.Now()
+	for name, content := range files {
+		name = memName(filepath.FromSlash(name))
+		v.mkdirs(filepath.Dir(name), now)
+		v.files[name] = memFile{data: []byte(content), modTime: now}
+	}
+	return v
+}
+
+// memName cleans a vault-relative name, with "" for the vault itself
+func memName(name string) string {
+	name = filepath.Clean(name)
+	if name == "." {
+		return ""
+	}
+	return name
+}
+
+// mkdirs creates a folder and the folders above it
+func (v *MemVault) mkdirs(folder string, now time.Time) {
+	for folder = memName(folder); folder != ""; folder = memName(filepath.Dir(folder)) {
+		if _, ok := v.dirs[folder]; ok {
+			return
+		}
+		v.dirs[folder] = now
+	}
+}
+
+// exists reports whether a file or folder has the name
+func (v *MemVault) exists(name string) bool {
+	_, file := v.files[name]
+	_, dir := v.dirs[name]
+	return file || dir || name == ""
+}
+
+func (v *MemVault) List(folder string) ([]fs.FileInfo, error) {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+
+	folder = memName(folder)
+	if _, ok := v.dirs[folder]; !ok && folder != "" {
+		return nil, &fs.PathError{Op: "readdir", Path: folder, Err: fs.ErrNotExist}
+	}
+	var infos []fs.FileInfo
+	for name, f := range v.files {
+		if memName(filepath.Dir(name)) == folder {
+			infos = append(infos, memInfo{name: filepath.Base(name), size: int64(len(f.data)), modTime: f.modTime})
+		}
+	}
+	for name, modTime := range v.dirs {
+		if memName(filepath.Dir(name)) == folder {
+			infos = append(infos, memInfo{name: filepath.Base(name), modTime: modTime, dir: true})
+		}
+	}
+	sort.Slice(infos, func(i, j int) bool {
+		return infos[i].Name() < infos[j].Name()
+	})
+	return infos, nil
+}
+
+func (v *MemVault) Read(name string) ([]byte, error) {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+
+	f, ok := v.files[memName(name)]
+	if !ok {
+		return nil, &fs.PathError{Op: "open", Path: name, Err: fs.ErrNotExist}
+	}
+	return append([]byte(nil), f.data...), nil
+}
+
+func (v *MemVault) Write(name string, data []byte) error {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+
+	name = memName(name)
+	if _, ok := v.dirs[name]; ok || name == "" {
+		return &fs.PathError{Op: "write", Path: name, Err: errors.New("is a folder")}
+	}
+	now := time.Now()
+	v.mkdirs(filepath.Dir(name), now)
+	v.files[name] = memFile{data: append([]byte(nil), data...), modTime: now}
+	return nil
+}
+
+func (v *MemVault) Create(name string) error {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+
+	name = memName(name)
+	if v.exists(name) {
+		return ErrExists
+	}
+	now := time.Now()
+	v.mkdirs(filepath.Dir(name), now)
+	v.files[name] = memFile{modTime: now}
+	return nil
+}
+
+func (v *MemVault) Delete(name string) error {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+
+	name = memName(name)
+	if _, ok := v.files[name]; ok {
+		delete(v.files, name)
+		return nil
+	}
+	if _, ok := v.dirs[name]; !ok {
+		return &fs.PathError{Op: "remove", Path: name, Err: fs.ErrNotExist}
+	}
+	for other := range v.files {
+		if memName(filepath.Dir(other)) == name {
+			return &fs.PathError{Op: "remove", Path: name, Err: errors.New("folder not empty")}
+		}
+	}
+	for other := range v.dirs {
+		if memName(filepath.Dir(other)) == name {
+			return &fs.PathError{Op: "remove", Path: name, Err: errors.New("folder not empty")}
+		}
+	}
+	delete(v.dirs, name)
+	return nil
+}
+
+func (v *MemVault) Rename(from, to string) error {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+
+	from, to = memName(from), memName(to)
+	f, ok := v.files[from]
+	if !ok {
+		return &fs.PathError{Op: "rename", Path: from, Err: fs.ErrNotExist}
+	}
+	if v.exists(to) {
+		return ErrExists
+	}
+	v.mkdirs(filepath.Dir(to), time.Now())
+	delete(v.files, from)
+	v.files[to] = f
+	return nil
+}
+
+func (v *MemVault) Stat(name string) (fs.FileInfo, error) {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+
+	name = memName(name)
+	if f, ok := v.files[name]; ok {
+		return memInfo{name: filepath.Base(name), size: int64(len(f.data)), modTime: f.modTime}, nil
+	}
+	if modTime, ok := v.dirs[name]; ok || name == "" {
+		return memInfo{name: filepath.Base(name), modTime: modTime, dir: true}, nil
+	}
+	return nil, &fs.PathError{Op: "stat", Path: name, Err: fs.ErrNotExist}
+}
+
+// memInfo describes a file or folder of a MemVault
+type memInfo struct {
+	name    string
+	size    int64
+	modTime time.Time
+	dir     bool
+}
+
+func (i memInfo) Name() string       { return i.name }
+func (i memInfo) Size() int64        { return i.size }
+func (i memInfo) ModTime() time.Time { return i.modTime }
+func (i memInfo) IsDir() bool        { return i.dir }
+func (i memInfo) Sys() any           { return nil }
+
+func (i memInfo) Mode() fs.FileMode {
+	if i.dir {
+		return fs.ModeDir | 0755
+	}
+	return 0644
+}
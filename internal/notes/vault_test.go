@@ -0,0 +1,160 @@
+package notes
+
+import (
+	"errors"
+	"io/fs"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+// vaultNames returns the names listed in a folder of a vault
+func vaultNames(t *testing.T, v Vault, folder string) []string {
+	t.Helper()
+	infos, err := v.List(folder)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var names []string
+	for _, info := range infos {
+		names = append(names, info.Name())
+	}
+	return names
+}
+
+// readString returns the content of a vault file, failing the test if it is missing
+func readString(t *testing.T, v Vault, name string) string {
+	t.Helper()
+	data, err := v.Read(name)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return string(data)
+}
+
+// TestVaultContract runs the same checks against every Vault implementation
+func TestVaultContract(t *testing.T) {
+	vaults := map[string]func(t *testing.T) Vault{
+		"mem": func(t *testing.T) Vault { return NewMemVault(nil) },
+		"dir": func(t *testing.T) Vault { return DirVault(t.TempDir()) },
+	}
+	join := filepath.Join
+
+	tests := []struct {
+		name string
+		run  func(t *testing.T, v Vault)
+	}{
+		{"create makes missing folders", func(t *testing.T, v Vault) {
+			if err := v.Create(join("new", "deep", "c.md")); err != nil {
+				t.Fatal(err)
+			}
+			info, err := v.Stat(join("new", "deep", "c.md"))
+			if err != nil || info.IsDir() || info.Size() != 0 {
+				t.Fatalf("Stat = %v, %v, want an empty file", info, err)
+			}
+			if names := vaultNames(t, v, "new"); !reflect.DeepEqual(names, []string{"deep"}) {
+				t.Errorf("List(new) = %v", names)
+			}
+		}},
+		{"create refuses a taken name", func(t *testing.T, v Vault) {
+			for _, name := range []string{"a.md", "work"} {
+				if err := v.Create(name); !errors.Is(err, ErrExists) {
+					t.Errorf("Create(%s) = %v, want ErrExists", name, err)
+				}
+			}
+			if got := readString(t, v, "a.md"); got != "A" {
+				t.Errorf("a.md = %q after a refused create", got)
+			}
+		}},
+		{"rename makes missing folders", func(t *testing.T, v Vault) {
+			to := join("archive", "2026", "a.md")
+			if err := v.Rename("a.md", to); err != nil {
+				t.Fatal(err)
+			}
+			if got := readString(t, v, to); got != "A" {
+				t.Errorf("%s = %q", to, got)
+			}
+			if _, err := v.Stat("a.md"); !errors.Is(err, fs.ErrNotExist) {
+				t.Errorf("Stat(a.md) after the rename = %v", err)
+			}
+		}},
+		{"rename refuses a taken name", func(t *testing.T, v Vault) {
+			for _, to := range []string{join("work", "b.md"), "work"} {
+				if err := v.Rename("a.md", to); !errors.Is(err, ErrExists) {
+					t.Errorf("Rename(a.md, %s) = %v, want ErrExists", to, err)
+				}
+			}
+			if readString(t, v, "a.md") != "A" || readString(t, v, join("work", "b.md")) != "B" {
+				t.Error("a refused rename changed the files")
+			}
+		}},
+		{"rename of a missing file", func(t *testing.T, v Vault) {
+			if err := v.Rename("missing.md", "other.md"); !errors.Is(err, fs.ErrNotExist) {
+				t.Errorf("Rename = %v, want fs.ErrNotExist", err)
+			}
+		}},
+		{"delete", func(t *testing.T, v Vault) {
+			if err := v.Delete("work"); err == nil || errors.Is(err, fs.ErrNotExist) {
+				t.Errorf("Delete of a folder with files = %v, want an error", err)
+			}
+			if err := v.Delete(join("work", "b.md")); err != nil {
+				t.Fatal(err)
+			}
+			if _, err := v.Stat(join("work", "b.md")); !errors.Is(err, fs.ErrNotExist) {
+				t.Errorf("Stat after Delete = %v", err)
+			}
+			if err := v.Delete("work"); err != nil {
+				t.Errorf("Delete of an empty folder = %v", err)
+			}
+			if err := v.Delete("missing.md"); !errors.Is(err, fs.ErrNotExist) {
+				t.Errorf("Delete of a missing file = %v, want fs.ErrNotExist", err)
+			}
+		}},
+		{"stat", func(t *testing.T, v Vault) {
+			if info, err := v.Stat(""); err != nil || !info.IsDir() {
+				t.Errorf("Stat of the vault = %v, %v", info, err)
+			}
+			if info, err := v.Stat("work"); err != nil || !info.IsDir() || info.Name() != "work" {
+				t.Errorf("Stat(work) = %v, %v", info, err)
+			}
+			if info, err := v.Stat("a.md"); err != nil || info.IsDir() || info.Size() != 1 || info.Name() != "a.md" {
+				t.Errorf("Stat(a.md) = %v, %v", info, err)
+			}
+			if _, err := v.Stat(join("missing", "a.md")); !errors.Is(err, fs.ErrNotExist) {
+				t.Errorf("Stat in a missing folder = %v, want fs.ErrNotExist", err)
+			}
+		}},
+		{"read, write and list", func(t *testing.T, v Vault) {
+			if err := v.Write(join("notes", "c.md"), []byte("C")); err != nil {
+				t.Fatal(err)
+			}
+			if got := readString(t, v, join("notes", "c.md")); got != "C" {
+				t.Errorf("c.md = %q", got)
+			}
+			if names := vaultNames(t, v, ""); !reflect.DeepEqual(names, []string{"a.md", "notes", "work"}) {
+				t.Errorf("List of the vault = %v", names)
+			}
+			if _, err := v.Read("missing.md"); !errors.Is(err, fs.ErrNotExist) {
+				t.Errorf("Read of a missing file = %v, want fs.ErrNotExist", err)
+			}
+			if _, err := v.List("missing"); !errors.Is(err, fs.ErrNotExist) {
+				t.Errorf("List of a missing folder = %v, want fs.ErrNotExist", err)
+			}
+		}},
+	}
+
+	for kind, newVault := range vaults {
+		for _, tt := range tests {
+			t.Run(kind+"/"+tt.name, func(t *testing.T) {
+				v := newVault(t)
+				if err := v.Write("a.md", []byte("A")); err != nil {
+					t.Fatal(err)
+				}
+				if err := v.Write(join("work", "b.md"), []byte("B")); err != nil {
+					t.Fatal(err)
+				}
+				tt.run(t, v)
+			})
+		}
+	}
+}
@@ -0,0 +1,148 @@
+package notes
+
+import (
+	"io/fs"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+)
+
+// watchSettle is how long a watcher waits for more changes before reporting
+// a batch, so that a save made of several file operations is one change
+const watchSettle = 100 * time.Millisecond
+
+// Watcher reports changes made to the notes and folders of a vault, whether
+// by this program or another one
+type Watcher struct {
+	changes chan []string
+	done    chan struct{}
+	once    sync.Once
+}
+
+// Watch starts watching a vault. Vaults on disk are watched with inotify
+// where it is available; other vaults, and those inotify cannot watch, are
+// polled every interval.
+func Watch(v Vault, interval time.Duration) *Watcher {
+	w := &Watcher{
+		changes: make(chan []string),
+		done:    make(chan struct{}),
+	}
+	if dir, ok := v.(DirVault); ok {
+		if err := w.watchNative(string(dir)); err == nil {
+			return w
+		}
+	}
+	// Stamp the vault before returning so that no change is missed
+	go w.poll(v, interval, pollVault(v))
+	return w
+}
+
+// Changes returns the channel batches of changed vault-relative paths are
+// sent on. A batch holding "" means anything in the vault may have changed.
+// The channel is closed when the watcher is closed.
+func (w *Watcher) Changes() <-chan []string {
+	return w.changes
+}
+
+// Close stops watching the vault
+func (w *Watcher) Close() {
+	w.once.Do(func() { close(w.done) })
+}
+
+// send reports a batch of changes, giving up when the watcher is closed
+func (w *Watcher) send(paths []string) bool {
+	select {
+	case w.changes <- paths:
+		return true
+	case <-w.done:
+		return false
+	}
+}
+
+// watched reports whether a change to a vault-relative path concerns notes.
+// Hidden files and folders hold metadata, the trash and unfinished saves.
+func watched(name string) bool {
+	for _, part := range strings.Split(filepath.ToSlash(name), "/") {
+		if strings.HasPrefix(part, ".") {
+			return false
+		}
+	}
+	return true
+}
+
+// pollStamp is what polling compares to notice a changed file or folder
+type pollStamp struct {
+	modTime time.Time
+	size    int64
+	dir     bool
+}
+
+// poll lists the vault every interval and reports what changed since the
+// previous listing. Once something changed, the vault is listed again until
+// it settles, so that a save caught halfway is still one change.
+func (w *Watcher) poll(v Vault, interval time.Duration, previous map[string]pollStamp) {
+	defer close(w.changes)
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-w.done:
+			return
+		case <-ticker.C:
+		}
+
+		current := pollVault(v)
+		changed := pollChanges(previous, current)
+		for len(changed) > 0 {
+			select {
+			case <-w.done:
+				return
+			case <-time.After(watchSettle):
+			}
+			settled := pollVault(v)
+			more := pollChanges(current, settled)
+			if len(more) == 0 {
+				break
+			}
+			for _, name := range more {
+				if !slices.Contains(changed, name) {
+					changed = append(changed, name)
+				}
+			}
+			current = settled
+		}
+		previous = current
+		if len(changed) > 0 && !w.send(changed) {
+			return
+		}
+	}
+}
+
+// pollChanges returns the names stamped differently in two listings
+func pollChanges(previous, current map[string]pollStamp) []string {
+	var changed []string
+	for name, stamp := range current {
+		if old, ok := previous[name]; !ok || !old.modTime.Equal(stamp.modTime) || old.size != stamp.size || old.dir != stamp.dir {
+			changed = append(changed, name)
+		}
+	}
+	for name := range previous {
+		if _, ok := current[name]; !ok {
+			changed = append(changed, name)
+		}
+	}
+	return changed
+}
+
+// pollVault stamps every note and folder of the vault
+func pollVault(v Vault) map[string]pollStamp {
+	stamps := make(map[string]pollStamp)
+	walk(v, func(name string, info fs.FileInfo) {
+		stamps[name] = pollStamp{modTime: info.ModTime(), size: info.Size(), dir: info.IsDir()}
+	})
+	return stamps
+}
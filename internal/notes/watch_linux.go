@@ -0,0 +1,162 @@
+package notes
+
+import (
+	"encoding/binary"
+	"errors"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+
+	"golang.org/x/sys/unix"
+)
+
+// inotifyMask is the set of events watched in every vault folder
+const inotifyMask = unix.IN_CREATE | unix.IN_DELETE | unix.IN_CLOSE_WRITE | unix.IN_MOVED_FROM |
+	unix.IN_MOVED_TO | unix.IN_ATTRIB | unix.IN_DELETE_SELF | unix.IN_ONLYDIR
+
+// inotify watches the folders of a vault directory
+type inotify struct {
+	fd      int
+	root    string
+	folders map[int]string // Watch descriptor -> vault-relative folder
+}
+
+// watchNative watches a vault directory and its folders with inotify
+func (w *Watcher) watchNative(dir string) error {
+	fd, err := unix.InotifyInit1(unix.IN_NONBLOCK | unix.IN_CLOEXEC)
+	if err != nil {
+		return err
+	}
+	in := &inotify{fd: fd, root: dir, folders: make(map[int]string)}
+	if err := in.add(""); err != nil {
+		unix.Close(fd)
+		return err
+	}
+	go w.readInotify(in)
+	return nil
+}
+
+// add watches a vault folder and the folders below it, leaving hidden ones
+// out. Folders removed in the meantime are skipped.
+func (in *inotify) add(folder string) error {
+	wd, err := unix.InotifyAddWatch(in.fd, filepath.Join(in.root, folder), inotifyMask)
+	if errors.Is(err, unix.ENOENT) && folder != "" {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	in.folders[wd] = folder
+
+	entries, err := os.ReadDir(filepath.Join(in.root, folder))
+	if err != nil {
+		return nil
+	}
+	for _, entry := range entries {
+		if entry.IsDir() && !strings.HasPrefix(entry.Name(), ".") {
+			if err := in.add(filepath.Join(folder, entry.Name())); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// event handles one inotify event and returns the vault-relative paths it
+// changed
+func (in *inotify) event(wd int, mask uint32, name string) []string {
+	if mask&unix.IN_Q_OVERFLOW != 0 {
+		// Events were lost, so anything may have changed
+		return []string{""}
+	}
+	folder, ok := in.folders[wd]
+	if !ok {
+		return nil
+	}
+	if mask&unix.IN_IGNORED != 0 {
+		delete(in.folders, wd)
+		return nil
+	}
+	if name == "" {
+		// The folder itself went away, which its parent reports too
+		if folder == "" && mask&unix.IN_DELETE_SELF != 0 {
+			return []string{""}
+		}
+		return nil
+	}
+
+	path := filepath.Join(folder, name)
+	if !watched(path) {
+		return nil
+	}
+	if mask&unix.IN_ISDIR != 0 && mask&(unix.IN_CREATE|unix.IN_MOVED_TO) != 0 {
+		// Watch new folders too; one that cannot be watched is still listed
+		in.add(path)
+	}
+	return []string{path}
+}
+
+// readInotify reports the changes inotify sees in batches until the watcher
+// is closed
+func (w *Watcher) readInotify(in *inotify) {
+	defer close(w.changes)
+	defer unix.Close(in.fd)
+
+	buf := make([]byte, 64*1024)
+	var batch []string
+	var due time.Time
+	for {
+		select {
+		case <-w.done:
+			return
+		default:
+		}
+
+		// Wake up regularly to notice Close, and when the batch is due
+		timeout := 250 * time.Millisecond
+		if len(batch) > 0 {
+			timeout = max(0, time.Until(due))
+		}
+		fds := []unix.PollFd{{Fd: int32(in.fd), Events: unix.POLLIN}}
+		ready, err := unix.Poll(fds, int(timeout.Milliseconds()))
+		if err != nil && !errors.Is(err, unix.EINTR) {
+			return
+		}
+
+		if ready > 0 {
+			n, err := unix.Read(in.fd, buf)
+			if err != nil && !errors.Is(err, unix.EAGAIN) && !errors.Is(err, unix.EINTR) {
+				return
+			}
+			for off := 0; off+unix.SizeofInotifyEvent <= n; {
+				wd := int(int32(binary.NativeEndian.Uint32(buf[off:])))
+				mask := binary.NativeEndian.Uint32(buf[off+4:])
+				length := int(binary.NativeEndian.Uint32(buf[off+12:]))
+				start := off + unix.SizeofInotifyEvent
+				if start+length > n {
+					break
+				}
+				name := strings.TrimRight(string(buf[start:start+length]), "\x00")
+				off = start + length
+
+				for _, path := range in.event(wd, mask, name) {
+					if len(batch) == 0 {
+						due = time.Now().Add(watchSettle)
+					}
+					if !slices.Contains(batch, path) {
+						batch = append(batch, path)
+					}
+				}
+			}
+		}
+
+		if len(batch) > 0 && !time.Now().Before(due) {
+			if !w.send(batch) {
+				return
+			}
+			batch = nil
+		}
+	}
+}
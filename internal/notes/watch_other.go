@@ -0,0 +1,10 @@
+//go:build !linux
+
+package notes
+
+import "errors"
+
+// watchNative is only available on Linux; vaults elsewhere are polled
+func (w *Watcher) watchNative(dir string) error {
+	return errors.New("inotify is not available on this platform")
+}
@@ -0,0 +1,104 @@
+package notes
+
+import (
+	"path/filepath"
+	"runtime"
+	"slices"
+	"testing"
+	"time"
+)
+
+// nextBatch waits for the next batch of changes a watcher reports
+func nextBatch(t *testing.T, w *Watcher, timeout time.Duration) ([]string, bool) {
+	t.Helper()
+	select {
+	case paths, ok := <-w.Changes():
+		if !ok {
+			t.Fatal("watcher closed its changes")
+		}
+		slices.Sort(paths)
+		return paths, true
+	case <-time.After(timeout):
+		return nil, false
+	}
+}
+
+func TestWatchCoalesces(t *testing.T) {
+	if runtime.GOOS != "linux" {
+		t.Skip("inotify is only used on Linux")
+	}
+	v := DirVault(t.TempDir())
+	w := Watch(v, time.Hour) // Polling would never report in time
+	defer w.Close()
+
+	// A save is several file operations, and notes are often saved together
+	for _, name := range []string{"a.md", "a.md", "b.md"} {
+		if err := v.Write(name, []byte(name)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	paths, ok := nextBatch(t, w, 2*time.Second)
+	if !ok {
+		t.Fatal("no changes reported")
+	}
+	if want := []string{"a.md", "b.md"}; !slices.Equal(paths, want) {
+		t.Errorf("changes = %q, want %q", paths, want)
+	}
+	if paths, ok := nextBatch(t, w, 3*watchSettle); ok {
+		t.Errorf("a second batch %q was reported", paths)
+	}
+
+	// Folders made after watching started are watched too
+	if err := v.Write(filepath.Join("work", "c.md"), nil); err != nil {
+		t.Fatal(err)
+	}
+	if paths, _ := nextBatch(t, w, 2*time.Second); !slices.Contains(paths, "work") {
+		t.Errorf("changes = %q, want the new folder", paths)
+	}
+	if err := v.Write(filepath.Join("work", "c.md"), []byte("c")); err != nil {
+		t.Fatal(err)
+	}
+	if paths, _ := nextBatch(t, w, 2*time.Second); !slices.Equal(paths, []string{filepath.Join("work", "c.md")}) {
+		t.Errorf("changes = %q, want the note in the new folder", paths)
+	}
+}
+
+func TestWatchPolling(t *testing.T) {
+	// Hiding the DirVault from Watch leaves it only polling to fall back on
+	v := struct{ Vault }{DirVault(t.TempDir())}
+	if err := v.Write("a.md", []byte("a")); err != nil {
+		t.Fatal(err)
+	}
+	w := Watch(v, 20*time.Millisecond)
+	defer w.Close()
+
+	if paths, ok := nextBatch(t, w, 5*watchSettle); ok {
+		t.Errorf("changes %q reported before any change", paths)
+	}
+	if err := v.Write(filepath.Join("work", "b.md"), []byte("b")); err != nil {
+		t.Fatal(err)
+	}
+	want := []string{"work", filepath.Join("work", "b.md")}
+	if paths, _ := nextBatch(t, w, 2*time.Second); !slices.Equal(paths, want) {
+		t.Errorf("changes = %q, want %q", paths, want)
+	}
+	if err := v.Write(filepath.Join(".termnote", "state"), nil); err != nil {
+		t.Fatal(err)
+	}
+	if err := v.Delete("a.md"); err != nil {
+		t.Fatal(err)
+	}
+	if paths, _ := nextBatch(t, w, 2*time.Second); !slices.Equal(paths, []string{"a.md"}) {
+		t.Errorf("changes = %q, want the deleted note only", paths)
+	}
+
+	w.Close()
+	select {
+	case _, ok := <-w.Changes():
+		if ok {
+			t.Error("a change was reported after Close")
+		}
+	case <-time.After(2 * time.Second):
+		t.Error("changes were not closed after Close")
+	}
+}
@@ -192,6 +192,38 @@ var (
 			Padding(1, 2)
 )
 
+// buildStyles points every style at the current colors
+func buildStyles() {
+	HeaderStyle = HeaderStyle.Foreground(ColorPrimary)
+	SubtitleStyle = SubtitleStyle.Foreground(ColorMuted)
+	HelpTitleStyle = HelpTitleStyle.Foreground(ColorPrimary)
+	KeyStyle = KeyStyle.Foreground(ColorPrimary)
+	DescStyle = DescStyle.Foreground(ColorText)
+	BoxStyle = BoxStyle.BorderForeground(ColorPrimary)
+	ViewTitleStyle = ViewTitleStyle.Foreground(ColorPrimary)
+	ViewHelpStyle = ViewHelpStyle.Foreground(ColorMuted)
+	SuccessStyle = SuccessStyle.Foreground(ColorSuccess)
+	ErrorStyle = ErrorStyle.Foreground(ColorError)
+	WarningStyle = WarningStyle.Foreground(ColorWarning)
+
+	DialogBoxStyle = DialogBoxStyle.BorderForeground(ColorPrimary)
+	DialogTitleStyle = DialogTitleStyle.Foreground(ColorPrimary)
+	InputLabelStyle = InputLabelStyle.Foreground(ColorAccent)
+	InputBoxStyle = InputBoxStyle.BorderForeground(ColorBorder)
+	InputHelpStyle = InputHelpStyle.Foreground(ColorMuted)
+	InputTipStyle = InputTipStyle.Foreground(ColorSecondary)
+
+	FileIconStyle = FileIconStyle.Foreground(ColorPrimary)
+	FileExtensionStyle = FileExtensionStyle.Foreground(ColorMuted)
+
+	ListTitleStyle = ListTitleStyle.Foreground(ColorPrimary)
+	ListItemTitleStyle = ListItemTitleStyle.Foreground(ColorText)
+	ListItemDescStyle = ListItemDescStyle.Foreground(ColorMuted)
+	ListItemSelectedTitleStyle = ListItemSelectedTitleStyle.Foreground(ColorPrimary)
+	ListItemSelectedDescStyle = ListItemSelectedDescStyle.Foreground(ColorAccent)
+	ListHelpStyle = ListHelpStyle.Foreground(ColorMuted)
+}
+
 // GetASCIIArt returns the selected ASCII art style
 // Options: "box", "simple", "slant", "double", "minimal"
 func GetASCIIArt(style string) string {
@@ -0,0 +1,185 @@
+package styles
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/charmbracelet/lipgloss"
+)
+
+// ThemeAuto picks the dark or light theme from the terminal background
+const ThemeAuto = "auto"
+
+// Theme is a named color scheme
+type Theme struct {
+	Name      string
+	Primary   lipgloss.Color
+	Secondary lipgloss.Color
+	Accent    lipgloss.Color
+	Muted     lipgloss.Color
+	Text      lipgloss.Color
+	Success   lipgloss.Color
+	Warning   lipgloss.Color
+	Error     lipgloss.Color
+	Border    lipgloss.Color
+	Bg        lipgloss.Color
+}
+
+// themes holds the built-in and registered user themes by name
+var themes = map[string]Theme{
+	"dark": {
+		Name:      "dark",
+		Primary:   lipgloss.Color("205"),
+		Secondary: lipgloss.Color("141"),
+		Accent:    lipgloss.Color("212"),
+		Muted:     lipgloss.Color("241"),
+		Text:      lipgloss.Color("252"),
+		Success:   lipgloss.Color("42"),
+		Warning:   lipgloss.Color("214"),
+		Error:     lipgloss.Color("196"),
+		Border:    lipgloss.Color("99"),
+		Bg:        lipgloss.Color("235"),
+	},
+	"light": {
+		Name:      "light",
+		Primary:   lipgloss.Color("162"),
+		Secondary: lipgloss.Color("91"),
+		Accent:    lipgloss.Color("125"),
+		Muted:     lipgloss.Color("244"),
+		Text:      lipgloss.Color("236"),
+		Success:   lipgloss.Color("28"),
+		Warning:   lipgloss.Color("166"),
+		Error:     lipgloss.Color("160"),
+		Border:    lipgloss.Color("97"),
+		Bg:        lipgloss.Color("255"),
+	},
+	"solarized": {
+		Name:      "solarized",
+		Primary:   lipgloss.Color("#d33682"),
+		Secondary: lipgloss.Color("#6c71c4"),
+		Accent:    lipgloss.Color("#268bd2"),
+		Muted:     lipgloss.Color("#657b83"),
+		Text:      lipgloss.Color("#93a1a1"),
+		Success:   lipgloss.Color("#859900"),
+		Warning:   lipgloss.Color("#b58900"),
+		Error:     lipgloss.Color("#dc322f"),
+		Border:    lipgloss.Color("#2aa198"),
+		Bg:        lipgloss.Color("#002b36"),
+	},
+	"high-contrast": {
+		Name:      "high-contrast",
+		Primary:   lipgloss.Color("11"),
+		Secondary: lipgloss.Color("14"),
+		Accent:    lipgloss.Color("13"),
+		Muted:     lipgloss.Color("250"),
+		Text:      lipgloss.Color("15"),
+		Success:   lipgloss.Color("10"),
+		Warning:   lipgloss.Color("11"),
+		Error:     lipgloss.Color("9"),
+		Border:    lipgloss.Color("15"),
+		Bg:        lipgloss.Color("0"),
+	},
+}
+
+// current is the theme the styles were last built from
+var current = themes["dark"]
+
+func init() {
+	Apply(current)
+}
+
+// Apply makes t the active theme and rebuilds every style from it
+func Apply(t Theme) {
+	current = t
+
+	ColorPrimary = t.Primary
+	ColorSecondary = t.Secondary
+	ColorAccent = t.Accent
+	ColorMuted = t.Muted
+	ColorText = t.Text
+	ColorSuccess = t.Success
+	ColorWarning = t.Warning
+	ColorError = t.Error
+	ColorBorder = t.Border
+	ColorBg = t.Bg
+
+	buildStyles()
+}
+
+// Use applies the theme with the given name. "auto" picks dark or light
+// depending on the terminal background.
+func Use(name string) error {
+	if name == ThemeAuto {
+		name = "light"
+		if lipgloss.HasDarkBackground() {
+			name = "dark"
+		}
+	}
+
+	t, ok := themes[name]
+	if !ok {
+		return fmt.Errorf("unknown theme %q", name)
+	}
+	Apply(t)
+	return nil
+}
+
+// Current returns the active theme
+func Current() Theme {
+	return current
+}
+
+// Register adds a theme, replacing any existing theme with the same name
+func Register(t Theme) {
+	themes[t.Name] = t
+}
+
+// ThemeNames returns the names of all available themes in sorted order
+func ThemeNames() []string {
+	names := make([]string, 0, len(themes))
+	for name := range themes {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// NewTheme builds a theme from color settings, e.g. from a user theme file.
+// Colors not given are taken from the theme named by the "base" key, or
+// from the dark theme.
+func NewTheme(name string, colors map[string]string) (Theme, error) {
+	baseName := colors["base"]
+	if baseName == "" {
+		baseName = "dark"
+	}
+	t, ok := themes[baseName]
+	if !ok {
+		return Theme{}, fmt.Errorf("theme %s: unknown base theme %q", name, baseName)
+	}
+	t.Name = name
+
+	fields := map[string]*lipgloss.Color{
+		"primary":   &t.Primary,
+		"secondary": &t.Secondary,
+		"accent":    &t.Accent,
+		"muted":     &t.Muted,
+		"text":      &t.Text,
+		"success":   &t.Success,
+		"warning":   &t.Warning,
+		"error":     &t.Error,
+		"border":    &t.Border,
+		"bg":        &t.Bg,
+	}
+	for key, value := range colors {
+		if key == "base" {
+			continue
+		}
+		field, ok := fields[key]
+		if !ok {
+			return Theme{}, fmt.Errorf("theme %s: unknown color %q", name, key)
+		}
+		*field = lipgloss.Color(value)
+	}
+
+	return t, nil
+}
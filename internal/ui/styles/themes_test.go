@@ -0,0 +1,73 @@
+package styles
+
+import (
+	"testing"
+
+	"github.com/charmbracelet/lipgloss"
+)
+
+func TestUse(t *testing.T) {
+	t.Cleanup(func() { Apply(themes["dark"]) })
+
+	if err := Use("light"); err != nil {
+		t.Fatal(err)
+	}
+	light := themes["light"]
+	if Current().Name != "light" || ColorPrimary != light.Primary {
+		t.Fatalf("Current() = %s, ColorPrimary = %v", Current().Name, ColorPrimary)
+	}
+	if got := HeaderStyle.GetForeground(); got != light.Primary {
+		t.Errorf("HeaderStyle foreground = %v, want %v", got, light.Primary)
+	}
+	if got := InputBoxStyle.GetBorderTopForeground(); got != light.Border {
+		t.Errorf("InputBoxStyle border = %v, want %v", got, light.Border)
+	}
+	// Only the colors change
+	if !KeyStyle.GetBold() || KeyStyle.GetWidth() != 12 {
+		t.Errorf("KeyStyle lost its layout: bold %v, width %d", KeyStyle.GetBold(), KeyStyle.GetWidth())
+	}
+
+	if err := Use("nope"); err == nil {
+		t.Error("Use of an unknown theme succeeded")
+	}
+	if Current().Name != "light" {
+		t.Errorf("failed Use changed the theme to %s", Current().Name)
+	}
+
+	if err := Use(ThemeAuto); err != nil {
+		t.Fatal(err)
+	}
+	if name := Current().Name; name != "dark" && name != "light" {
+		t.Errorf("auto picked %s", name)
+	}
+}
+
+func TestNewTheme(t *testing.T) {
+	tests := []struct {
+		name    string
+		colors  map[string]string
+		primary lipgloss.Color
+		text    lipgloss.Color
+		wantErr bool
+	}{
+		{"defaults to dark", map[string]string{"primary": "#ff0000"}, "#ff0000", themes["dark"].Text, false},
+		{"base", map[string]string{"base": "light", "text": "0"}, themes["light"].Primary, "0", false},
+		{"unknown base", map[string]string{"base": "neon"}, "", "", true},
+		{"unknown color", map[string]string{"shadow": "1"}, "", "", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			theme, err := NewTheme("mine", tt.colors)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("NewTheme() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+			if theme.Name != "mine" || theme.Primary != tt.primary || theme.Text != tt.text {
+				t.Errorf("NewTheme() = %+v", theme)
+			}
+		})
+	}
+}
@@ -1,17 +1,55 @@
 package main
 
 import (
+	"errors"
+	"flag"
 	"fmt"
 	"os"
 
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/shalshcode08/Term-Note/internal/app"
+	"github.com/shalshcode08/Term-Note/internal/cli"
+	"github.com/shalshcode08/Term-Note/internal/config"
+	"github.com/shalshcode08/Term-Note/internal/notes"
 )
 
 func main() {
-	p := tea.NewProgram(app.New())
+	cfg, args, err := config.Load(os.Args[1:])
+	if errors.Is(err, flag.ErrHelp) {
+		os.Exit(0)
+	}
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "termnote: %v\n", err)
+		os.Exit(2)
+	}
+
+	os.Exit(run(cfg, args))
+}
+
+// run starts a subcommand or the interactive app and returns the exit code
+func run(cfg *config.Config, args []string) int {
+	var vault notes.Vault = notes.DirVault(cfg.VaultDir)
+	if cfg.Demo {
+		vault = notes.NewDemoVault()
+		defer os.RemoveAll(cfg.VaultDir)
+	}
+
+	// Subcommands run without the interactive app
+	if len(args) > 0 {
+		return cli.Run(cfg, vault, args, os.Stdin, os.Stdout, os.Stderr)
+	}
+
+	m, err := app.New(cfg, vault)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "termnote: %v\n", err)
+		return 2
+	}
+
+	p := tea.NewProgram(m, tea.WithReportFocus(), tea.WithoutSignalHandler())
+	app.WatchSignals(p)
 	if _, err := p.Run(); err != nil {
 		fmt.Printf("Alas, there's been an error: %v", err)
-		os.Exit(1)
+		return 1
 	}
+	return 0
 }